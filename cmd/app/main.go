@@ -3,13 +3,64 @@ package main
 import (
 	"banner/config"
 	"banner/internal/app"
+	"flag"
+	"fmt"
 	"log"
+	"os"
 )
 
+type command struct {
+	usage string
+	run   func(cfg *config.Config, args []string) error
+}
+
+var commands = map[string]command{
+	"serve": {"запустить сервер (команда по умолчанию)", func(cfg *config.Config, _ []string) error {
+		app.Run(cfg)
+		return nil
+	}},
+	"migrate":        {"up [N] | down N|-all | status | force V - управление миграциями", app.Migrate},
+	"seed":           {"[-count N] [-features N] [-dry-run] - создать тестовые баннеры", app.Seed},
+	"export":         {"[-format jsonl|csv] [-feature-id ID] [-tag-id ID] [-out FILE] - выгрузить баннеры", app.Export},
+	"import":         {"[-file FILE] [-format jsonl|csv] [-conflict skip|overwrite|fail] [-preserve-ids] [-dry-run] - загрузить баннеры", app.Import},
+	"cache-snapshot": {"inspect [-file FILE] - показать записи снимка кэша", app.CacheSnapshot},
+	"token":          {"issue [-role admin|user] [-ttl 24h] [-subject NAME] - выпустить токен доступа", app.Token},
+	"config":         {"print | validate - показать или проверить конфигурацию", app.Config},
+}
+
+var order = []string{"serve", "migrate", "seed", "export", "import", "cache-snapshot", "token", "config"}
+
 func main() {
-	cfg, err := config.NewConfig()
+	configPath := flag.String("config", config.DefaultPath, "файл конфигурации")
+	flag.Usage = usage
+	flag.Parse()
+
+	name, args := "serve", flag.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.NewConfig(*configPath)
 	if err != nil {
 		log.Fatalf("Config error: %s", err)
 	}
-	app.Run(cfg)
+	if err := cmd.run(cfg, args); err != nil {
+		log.Fatalf("%s error: %s", name, err)
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [--config FILE] <command> [args]\n\nCommands:\n", os.Args[0])
+	for _, name := range order {
+		fmt.Fprintf(out, "  %-15s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"banner/internal/bannerctl"
+	"banner/pkg/client"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	output := flag.String("o", string(bannerctl.FormatTable), "формат вывода: table, json или yaml")
+	profileName := flag.String("profile", "", "профиль из файла профилей, по умолчанию current")
+	profilesPath := flag.String("config", bannerctl.DefaultProfilesPath(), "файл профилей")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	name, args := flag.Arg(0), flag.Args()[1:]
+	cmd, ok := bannerctl.Commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+	format, err := bannerctl.ParseFormat(*output)
+	if err != nil {
+		fatal(err)
+	}
+	profile, err := bannerctl.LoadProfile(*profilesPath, *profileName)
+	if err != nil {
+		fatal(err)
+	}
+	c, err := client.New(profile.URL, client.WithToken(profile.Token))
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := cmd.Run(ctx, &bannerctl.Env{Client: c, Out: os.Stdout, Format: format}, args); err != nil {
+		stop()
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "bannerctl: %s\n", err)
+	os.Exit(1)
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [-o table|json|yaml] [--profile NAME] [--config FILE] <command> [args]\n\nCommands:\n", os.Args[0])
+	for _, name := range bannerctl.Order {
+		fmt.Fprintf(out, "  %-12s %s\n", name, bannerctl.Commands[name].Usage)
+	}
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(out, "\nBANNER_URL and BANNER_TOKEN override the profile.")
+}
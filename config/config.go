@@ -8,15 +8,26 @@ import (
 
 type (
 	Config struct {
-		App        `yaml:"app"`
-		HTTPServer `yaml:"HTTPServer"`
-		Log        `yaml:"logger"`
-		PG         `yaml:"postgres"`
+		App         `yaml:"app"`
+		HTTPServer  `yaml:"HTTPServer"`
+		Log         `yaml:"logger"`
+		PG          `yaml:"postgres"`
+		I18n        `yaml:"i18n"`
+		Idempotency `yaml:"idempotency"`
+		Trash       `yaml:"trash"`
+		Jobs        `yaml:"jobs"`
+		Stream      `yaml:"stream"`
+		Webhooks    `yaml:"webhooks"`
+		Auth        `yaml:"auth"`
+		Cache       `yaml:"cache"`
+		Drafts      `yaml:"drafts"`
 	}
 
 	App struct {
 		Name    string `env-required:"true" yaml:"name"    env:"APP_NAME"`
 		Version string `env-required:"true" yaml:"version" env:"APP_VERSION"`
+		// DevTeardown - откатить все миграции при остановке сервера, только для локальной разработки
+		DevTeardown bool `yaml:"dev_teardown" env:"DEV_TEARDOWN" env-default:"false"`
 	}
 
 	HTTPServer struct {
@@ -26,6 +37,9 @@ type (
 		WriteTimeout    time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT" env-default:"5s"`
 		MaxHeaderBytes  int           `yaml:"max_header_bytes" env:"MAX_HEADER_BYTES" env-default:"1048576"`
 		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"3s"`
+		// TrustedProxies - адреса и подсети прокси, которым можно верить в X-Forwarded-For. По умолчанию
+		// никому: адрес клиента в аудите - адрес соединения
+		TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" env-separator:","`
 	}
 
 	Log struct {
@@ -37,12 +51,72 @@ type (
 		ConnAttempts int           `yaml:"conn_attempts" env-default:"5"`
 		ConnTimeout  time.Duration `yaml:"conn_timeout" env-default:"10s"`
 	}
+
+	I18n struct {
+		DefaultLang string `yaml:"default_lang" env:"DEFAULT_LANG" env-default:"ru"`
+	}
+
+	Idempotency struct {
+		TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
+		// SweepInterval - как часто удалять ключи, окно хранения которых истекло
+		SweepInterval time.Duration `yaml:"sweep_interval" env:"IDEMPOTENCY_SWEEP_INTERVAL" env-default:"10m"`
+	}
+
+	Trash struct {
+		Retention     time.Duration `yaml:"retention" env:"TRASH_RETENTION" env-default:"720h"`
+		PurgeInterval time.Duration `yaml:"purge_interval" env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
+	}
+
+	Jobs struct {
+		Workers      int           `yaml:"workers" env:"JOB_WORKERS" env-default:"2"`
+		PollInterval time.Duration `yaml:"poll_interval" env:"JOB_POLL_INTERVAL" env-default:"1s"`
+		MaxAttempts  int32         `yaml:"max_attempts" env:"JOB_MAX_ATTEMPTS" env-default:"5"`
+		RetryBackoff time.Duration `yaml:"retry_backoff" env:"JOB_RETRY_BACKOFF" env-default:"5s"`
+		MaxBackoff   time.Duration `yaml:"max_backoff" env:"JOB_MAX_BACKOFF" env-default:"10m"`
+		LockTimeout  time.Duration `yaml:"lock_timeout" env:"JOB_LOCK_TIMEOUT" env-default:"5m"`
+		DrainTimeout time.Duration `yaml:"drain_timeout" env:"JOB_DRAIN_TIMEOUT" env-default:"30s"`
+	}
+
+	Stream struct {
+		PollInterval time.Duration `yaml:"poll_interval" env:"STREAM_POLL_INTERVAL" env-default:"1s"`
+		Heartbeat    time.Duration `yaml:"heartbeat" env:"STREAM_HEARTBEAT" env-default:"15s"`
+	}
+
+	Webhooks struct {
+		Workers      int           `yaml:"workers" env:"WEBHOOK_WORKERS" env-default:"2"`
+		PollInterval time.Duration `yaml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL" env-default:"1s"`
+		MaxAttempts  int32         `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"`
+		RetryBackoff time.Duration `yaml:"retry_backoff" env:"WEBHOOK_RETRY_BACKOFF" env-default:"10s"`
+		MaxBackoff   time.Duration `yaml:"max_backoff" env:"WEBHOOK_MAX_BACKOFF" env-default:"1h"`
+		Timeout      time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+		LockTimeout  time.Duration `yaml:"lock_timeout" env:"WEBHOOK_LOCK_TIMEOUT" env-default:"1m"`
+		DrainTimeout time.Duration `yaml:"drain_timeout" env:"WEBHOOK_DRAIN_TIMEOUT" env-default:"15s"`
+	}
+
+	Auth struct {
+		// TokenSecret - ключ подписи токенов из token issue, пустой ключ - только статические токены
+		TokenSecret string `yaml:"token_secret" env:"AUTH_TOKEN_SECRET"`
+	}
+
+	Cache struct {
+		// SnapshotPath - файл, куда кэш сохраняется при остановке и откуда загружается при запуске
+		SnapshotPath string `yaml:"snapshot_path" env:"CACHE_SNAPSHOT_PATH"`
+	}
+
+	Drafts struct {
+		// RequireReview - опубликованные баннеры меняются только через черновик и ревью, прямые изменения
+		// отклоняются с 409
+		RequireReview bool `yaml:"require_review" env:"DRAFTS_REQUIRE_REVIEW" env-default:"false"`
+	}
 )
 
-func NewConfig() (*Config, error) {
+// DefaultPath - файл конфигурации, если он не задан флагом --config
+const DefaultPath = "./config/config.yml"
+
+func NewConfig(path string) (*Config, error) {
 	cfg := &Config{}
 
-	err := cleanenv.ReadConfig("./config/config.yml", cfg)
+	err := cleanenv.ReadConfig(path, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("config error: %w", err)
 	}
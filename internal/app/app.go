@@ -19,26 +19,63 @@ import (
 
 func Run(cfg *config.Config) {
 	l := logger.New(cfg.Log.Level)
-	pgURL, _ := os.LookupEnv("PG_URL")
-	pg, err := postgres.New(pgURL, cfg.PG.PoolMax, cfg.PG.ConnAttempts, cfg.PG.ConnTimeout)
+	// те же проверки, что и в config validate: нулевые интервалы и число воркеров иначе обнаружатся
+	// только паникой фоновых циклов
+	if err := validateConfig(cfg); err != nil {
+		l.Fatal(fmt.Errorf("app - Run - validateConfig: %v", err))
+	}
+	if err := checkSchema(); err != nil {
+		l.Fatal(fmt.Errorf("app - Run - checkSchema: %v", err))
+	}
+	pg, err := connect(cfg)
 	if err != nil {
 		l.Fatal(fmt.Errorf("app - Run - postgres.New: %v", err))
 	}
 	defer pg.Close()
 
 	memCache := cache.NewMemoryCache(1000, 20)
+	if cfg.Cache.SnapshotPath != "" {
+		restoreCache(memCache, cfg.Cache.SnapshotPath, l)
+	}
+
+	messages, err := v1.NewCatalog(cfg.I18n.DefaultLang)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - v1.NewCatalog: %v", err))
+	}
 
+	bannerService, schemaService := newServices(pg, memCache)
+	idempotencyService := service.NewIdempotencyService(repository.NewIdempotencyRepository(pg), cfg.Idempotency.TTL)
 	bannerController := v1.NewBannerController(
-		service.NewBannerService(
-			repository.NewBannerRepository(pg),
-			memCache,
-			5*time.Minute,
-		),
+		bannerService,
 		l,
-	)
+		messages,
+	).WithIdempotency(idempotencyService).
+		WithTokenSecret(cfg.Auth.TokenSecret).
+		WithRequireReview(cfg.Drafts.RequireReview)
+	schemaController := v1.NewSchemaController(schemaService, l, messages)
+	jobService := service.NewJobService(repository.NewJobRepository(pg), bannerService, l, jobOptions(cfg.Jobs))
+	jobService.Start()
+	jobController := v1.NewJobController(jobService, l, messages)
+
+	streamController := v1.NewStreamController(service.NewEventService(repository.NewEventRepository(pg)), l, messages, v1.StreamOptions{
+		PollInterval: cfg.Stream.PollInterval,
+		Heartbeat:    cfg.Stream.Heartbeat,
+	})
+
+	webhookService := service.NewWebhookService(repository.NewWebhookRepository(pg), l, webhookOptions(cfg.Webhooks))
+	webhookService.Start()
+	webhookController := v1.NewWebhookController(webhookService, l, messages)
+	draftController := v1.NewDraftController(bannerService, l, messages)
+	auditController := v1.NewAuditController(service.NewAuditService(repository.NewAuditRepository(pg)), l, messages)
+
+	stopPurge := startPurge(bannerService, cfg.Trash, l)
+	stopSweep := startIdempotencySweep(idempotencyService, cfg.Idempotency.SweepInterval, l)
 
 	handler := gin.New()
-	v1.RegisterRoutes(handler, bannerController)
+	if err := handler.SetTrustedProxies(cfg.HTTPServer.TrustedProxies); err != nil {
+		l.Fatal(fmt.Errorf("app - Run - SetTrustedProxies: %v", err))
+	}
+	v1.RegisterRoutes(handler, bannerController, schemaController, jobController, streamController, webhookController, auditController, draftController)
 	httpServer := httpserver.New(handler, cfg.HTTPServer.ReadTimeout, cfg.HTTPServer.WriteTimeout, cfg.HTTPServer.Host, cfg.HTTPServer.Port, cfg.HTTPServer.MaxHeaderBytes, cfg.HTTPServer.ShutdownTimeout)
 	l.Info("Server is starting on " + cfg.HTTPServer.Host + ":" + cfg.HTTPServer.Port)
 	interrupt := make(chan os.Signal, 1)
@@ -50,10 +87,55 @@ func Run(cfg *config.Config) {
 		l.Error("app - Run - httpServer.Notify: %v", err)
 	}
 	l.Info("Server shutting down...")
-	dropTables(pgURL, l)
+	stopPurge()
+	stopSweep()
+	streamController.Close()
 	err = httpServer.Shutdown()
 	if err != nil {
 		l.Error("app - Run - httpServer.Shutdown: %v", err)
 	}
+	jobService.Stop()
+	webhookService.Stop()
+	if cfg.Cache.SnapshotPath != "" {
+		saveCache(memCache, cfg.Cache.SnapshotPath, l)
+	}
+	if cfg.App.DevTeardown {
+		l.Warn("app.dev_teardown is set, dropping all tables")
+		if err := dropTables(); err != nil {
+			l.Error("app - Run - dropTables: %v", err)
+		}
+	}
+
+}
+
+// newServices собирает сервисы баннеров и схем поверх подключения к бд
+func newServices(pg *postgres.DB, memCache *cache.MemoryCache) (*service.BannerService, *service.SchemaService) {
+	bannerRepository := repository.NewBannerRepository(pg)
+	schemaService := service.NewSchemaService(repository.NewSchemaRepository(pg), bannerRepository)
+	return service.NewBannerService(bannerRepository, schemaService, memCache, 5*time.Minute), schemaService
+}
 
+func jobOptions(cfg config.Jobs) service.JobOptions {
+	return service.JobOptions{
+		Workers:      cfg.Workers,
+		PollInterval: cfg.PollInterval,
+		MaxAttempts:  cfg.MaxAttempts,
+		RetryBackoff: cfg.RetryBackoff,
+		MaxBackoff:   cfg.MaxBackoff,
+		LockTimeout:  cfg.LockTimeout,
+		DrainTimeout: cfg.DrainTimeout,
+	}
+}
+
+func webhookOptions(cfg config.Webhooks) service.WebhookOptions {
+	return service.WebhookOptions{
+		Workers:      cfg.Workers,
+		PollInterval: cfg.PollInterval,
+		MaxAttempts:  cfg.MaxAttempts,
+		RetryBackoff: cfg.RetryBackoff,
+		MaxBackoff:   cfg.MaxBackoff,
+		Timeout:      cfg.Timeout,
+		LockTimeout:  cfg.LockTimeout,
+		DrainTimeout: cfg.DrainTimeout,
+	}
 }
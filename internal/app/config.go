@@ -0,0 +1,82 @@
+package app
+
+import (
+	"banner/config"
+	v1 "banner/internal/controller/http/v1"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config - команда config: print печатает итоговую конфигурацию с учетом переменных окружения,
+// validate проверяет значения, которые нельзя описать тегами cleanenv
+func Config(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("config: expected print or validate")
+	}
+	switch args[0] {
+	case "print":
+		printed := *cfg
+		if printed.Auth.TokenSecret != "" {
+			printed.Auth.TokenSecret = "***"
+		}
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&printed); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "validate":
+		if err := validateConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Println("config is valid")
+		return nil
+	default:
+		return fmt.Errorf("config: unknown command %q, expected print or validate", args[0])
+	}
+}
+
+func validateConfig(cfg *config.Config) error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	port, err := strconv.Atoi(cfg.HTTPServer.Port)
+	check(err == nil && port > 0 && port < 65536, "HTTPServer.port: invalid port %q", cfg.HTTPServer.Port)
+	check(cfg.HTTPServer.ReadTimeout > 0, "HTTPServer.read_timeout: must be positive")
+	check(cfg.HTTPServer.WriteTimeout > 0, "HTTPServer.write_timeout: must be positive")
+	check(cfg.HTTPServer.ShutdownTimeout > 0, "HTTPServer.shutdown_timeout: must be positive")
+	for _, proxy := range cfg.HTTPServer.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "HTTPServer.trusted_proxies: invalid address %q", proxy)
+	}
+	check(cfg.PG.PoolMax > 0, "postgres.pool_max: must be positive")
+	check(cfg.Idempotency.TTL > 0, "idempotency.ttl: must be positive")
+	check(cfg.Idempotency.SweepInterval > 0, "idempotency.sweep_interval: must be positive")
+	check(cfg.Trash.Retention > 0, "trash.retention: must be positive")
+	check(cfg.Trash.PurgeInterval > 0, "trash.purge_interval: must be positive")
+	check(cfg.Jobs.Workers > 0, "jobs.workers: must be positive")
+	check(cfg.Jobs.PollInterval > 0, "jobs.poll_interval: must be positive")
+	check(cfg.Jobs.MaxAttempts > 0, "jobs.max_attempts: must be positive")
+	check(cfg.Jobs.RetryBackoff > 0, "jobs.retry_backoff: must be positive")
+	check(cfg.Jobs.MaxBackoff >= cfg.Jobs.RetryBackoff, "jobs.max_backoff: must not be less than retry_backoff")
+	check(cfg.Jobs.LockTimeout > 0, "jobs.lock_timeout: must be positive")
+	check(cfg.Webhooks.Workers > 0, "webhooks.workers: must be positive")
+	check(cfg.Webhooks.PollInterval > 0, "webhooks.poll_interval: must be positive")
+	check(cfg.Webhooks.MaxAttempts > 0, "webhooks.max_attempts: must be positive")
+	check(cfg.Webhooks.RetryBackoff > 0, "webhooks.retry_backoff: must be positive")
+	check(cfg.Webhooks.MaxBackoff >= cfg.Webhooks.RetryBackoff, "webhooks.max_backoff: must not be less than retry_backoff")
+	check(cfg.Webhooks.Timeout > 0, "webhooks.timeout: must be positive")
+	check(cfg.Webhooks.LockTimeout > cfg.Webhooks.Timeout, "webhooks.lock_timeout: must be greater than timeout")
+	if _, err := v1.NewCatalog(cfg.I18n.DefaultLang); err != nil {
+		errs = append(errs, fmt.Errorf("i18n.default_lang: %w", err))
+	}
+	return errors.Join(errs...)
+}
@@ -1,82 +1,213 @@
 package app
 
 import (
-	"banner/pkg/logger"
+	"banner/config"
 	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
 const (
 	defaultAttempts = 10
 	defaultTimeout  = time.Second
+	migrationsURL   = "file://migrations"
 )
 
-func init() {
-	databaseURL, ok := os.LookupEnv("PG_URL")
-	if !ok || len(databaseURL) == 0 {
-		log.Fatalf("migrate: environment variable not declared: PG_URL")
-	}
-	var (
-		attempts = defaultAttempts
-		err      error
-		m        *migrate.Migrate
-	)
-	for attempts > 0 {
-		m, err = migrate.New("file://migrations", databaseURL)
-		if err == nil {
-			break
-		}
-		log.Printf("Migrate: postgres is trying to connect, attempts left: %d", attempts)
-		time.Sleep(defaultTimeout)
-		attempts--
+// ErrSchemaVersion - схема бд не совпадает с миграциями, с которыми собран сервис
+var ErrSchemaVersion = errors.New("unexpected schema version")
+
+// Migrate - команда migrate: up [N], down N|-all, status, force V
+func Migrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("migrate: expected up, down, status or force")
 	}
+	m, err := newMigrate()
 	if err != nil {
-		log.Fatalf("Migrate: postgres connect error: %s", err)
+		return err
 	}
-	err = m.Up()
 	defer m.Close()
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		log.Fatalf("Migrate: up error: %s", err)
+
+	switch args[0] {
+	case "up":
+		err = migrateUp(m, args[1:])
+	case "down":
+		err = migrateDown(m, args[1:])
+	case "status":
+		err = migrateStatus(m)
+	case "force":
+		err = migrateForce(m, args[1:])
+	default:
+		return fmt.Errorf("migrate: unknown command %q, expected up, down, status or force", args[0])
 	}
 	if errors.Is(err, migrate.ErrNoChange) {
 		log.Printf("Migrate: no change")
-		return
+		return nil
+	}
+	if err != nil {
+		return err
 	}
-	log.Printf("Migrate: up success")
+	return migrateStatus(m)
 }
 
-func dropTables(databaseURL string, l logger.Logger) {
+// migrateUp применяет все новые миграции или только N следующих
+func migrateUp(m *migrate.Migrate, args []string) error {
+	if len(args) == 0 {
+		return m.Up()
+	}
+	steps, err := migrationSteps(args[0])
+	if err != nil {
+		return err
+	}
+	return m.Steps(steps)
+}
+
+// migrateDown откатывает N последних миграций. Откат всех миграций удаляет данные, поэтому
+// требует явного -all
+func migrateDown(m *migrate.Migrate, args []string) error {
+	flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	all := flags.Bool("all", false, "откатить все миграции, все данные будут удалены")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *all {
+		return m.Down()
+	}
+	if flags.NArg() != 1 {
+		return errors.New("migrate down: expected number of migrations or -all")
+	}
+	steps, err := migrationSteps(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return m.Steps(-steps)
+}
+
+// migrateForce записывает версию схемы без выполнения миграций, чтобы снять флаг dirty после
+// ручного исправления бд
+func migrateForce(m *migrate.Migrate, args []string) error {
+	if len(args) != 1 {
+		return errors.New("migrate force: expected version")
+	}
+	version, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("migrate force: invalid version %q", args[0])
+	}
+	return m.Force(version)
+}
+
+func migrateStatus(m *migrate.Migrate) error {
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	latest, err := latestMigration()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d\ndirty: %t\nlatest: %d\n", version, dirty, latest)
+	return nil
+}
+
+func migrationSteps(arg string) (int, error) {
+	steps, err := strconv.Atoi(arg)
+	if err != nil || steps <= 0 {
+		return 0, fmt.Errorf("migrate: invalid number of migrations %q", arg)
+	}
+	return steps, nil
+}
+
+// checkSchema проверяет, что к бд применены ровно те миграции, с которыми собран сервис
+func checkSchema() error {
+	m, err := newMigrate()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("%w: no migrations applied, run migrate up", ErrSchemaVersion)
+	}
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: version %d is dirty, fix the schema and run migrate force", ErrSchemaVersion, version)
+	}
+	latest, err := latestMigration()
+	if err != nil {
+		return err
+	}
+	if version != latest {
+		return fmt.Errorf("%w: schema is at %d, expected %d", ErrSchemaVersion, version, latest)
+	}
+	return nil
+}
+
+// latestMigration - версия последнего файла миграции
+func latestMigration() (uint, error) {
+	driver, err := source.Open(migrationsURL)
+	if err != nil {
+		return 0, err
+	}
+	defer driver.Close()
+	version, err := driver.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := driver.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+func newMigrate() (*migrate.Migrate, error) {
+	databaseURL, ok := os.LookupEnv("PG_URL")
+	if !ok || len(databaseURL) == 0 {
+		return nil, errors.New("migrate: environment variable not declared: PG_URL")
+	}
 	var (
 		attempts = defaultAttempts
 		err      error
 		m        *migrate.Migrate
 	)
 	for attempts > 0 {
-		m, err = migrate.New("file://migrations", databaseURL)
+		m, err = migrate.New(migrationsURL, databaseURL)
 		if err == nil {
-			break
+			return m, nil
 		}
-		l.Info("Migrate: postgres is trying to connect, attempts left: %d", attempts)
+		log.Printf("Migrate: postgres is trying to connect, attempts left: %d", attempts)
 		time.Sleep(defaultTimeout)
 		attempts--
 	}
+	return nil, fmt.Errorf("migrate: postgres connect error: %w", err)
+}
+
+// dropTables откатывает все миграции. Используется только для локальной разработки с app.dev_teardown
+func dropTables() error {
+	m, err := newMigrate()
 	if err != nil {
-		l.Fatal("Migrate: postgres connect error: %s", err)
+		return err
 	}
-	err = m.Down()
 	defer m.Close()
+	err = m.Down()
 	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		l.Fatal("Migrate: down error: %s", err)
-	}
-	if errors.Is(err, migrate.ErrNoChange) {
-		l.Info("Migrate: no change")
-		return
+		return fmt.Errorf("migrate: down error: %w", err)
 	}
-	l.Info("Migrate: down success")
+	return nil
 }
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// startPeriodic выполняет run сразу и затем раз в interval. Возвращаемая функция останавливает
+// цикл и ждет завершения текущего прохода. Цикл с неположительным интервалом не запускается
+func startPeriodic(interval time.Duration, run func(ctx context.Context)) func() {
+	if interval <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			run(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
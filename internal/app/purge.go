@@ -0,0 +1,36 @@
+package app
+
+import (
+	"banner/config"
+	"banner/internal/service"
+	"banner/pkg/logger"
+	"context"
+	"time"
+)
+
+// startPurge раз в cfg.PurgeInterval удаляет из корзины баннеры старше cfg.Retention.
+// Возвращаемая функция останавливает очистку и ждет завершения текущего прохода
+func startPurge(bannerService *service.BannerService, cfg config.Trash, l logger.Logger) func() {
+	return startPeriodic(cfg.PurgeInterval, func(ctx context.Context) {
+		purged, err := bannerService.PurgeDeleted(ctx, cfg.Retention)
+		if err != nil && ctx.Err() == nil {
+			l.Error("app - purge - PurgeDeleted: %v", err)
+		}
+		if purged > 0 {
+			l.Info("Purged %d deleted banners", purged)
+		}
+	})
+}
+
+// startIdempotencySweep раз в interval удаляет ключи идемпотентности, окно хранения которых истекло
+func startIdempotencySweep(idempotencyService *service.IdempotencyService, interval time.Duration, l logger.Logger) func() {
+	return startPeriodic(interval, func(ctx context.Context) {
+		swept, err := idempotencyService.Sweep(ctx)
+		if err != nil && ctx.Err() == nil {
+			l.Error("app - sweep - Sweep: %v", err)
+		}
+		if swept > 0 {
+			l.Info("Removed %d expired idempotency keys", swept)
+		}
+	})
+}
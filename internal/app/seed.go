@@ -0,0 +1,65 @@
+package app
+
+import (
+	"banner/config"
+	"banner/internal/entity"
+	"banner/pkg/cache"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Seed - команда seed: создает тестовые баннеры. У i-го баннера фича i%features+1 и тег i/features+1,
+// поэтому пары тег-фича не пересекаются, а повторный запуск пропускает уже созданные баннеры
+func Seed(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	count := flags.Int("count", 100, "сколько баннеров создать")
+	features := flags.Int("features", 10, "на сколько фич распределить баннеры")
+	dryRun := flags.Bool("dry-run", false, "проверить загрузку, ничего не сохраняя")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *count <= 0 || *features <= 0 {
+		return errors.New("seed: count and features must be positive")
+	}
+
+	var rows bytes.Buffer
+	encoder := json.NewEncoder(&rows)
+	for i := 0; i < *count; i++ {
+		err := encoder.Encode(entity.Banner{
+			TagIDs:    []int32{int32(i / *features + 1)},
+			FeatureID: int32(i%*features + 1),
+			Content: map[string]interface{}{
+				"title": fmt.Sprintf("Баннер %d", i+1),
+				"text":  fmt.Sprintf("Тестовый баннер %d", i+1),
+				"url":   fmt.Sprintf("https://example.com/banners/%d", i+1),
+			},
+			IsActive: i%5 != 4,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	pg, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+	bannerService, _ := newServices(pg, cache.NewMemoryCache(1, 1))
+	report, err := bannerService.Import(context.Background(), &rows, &entity.ImportOptions{
+		Format:   entity.FormatJSONL,
+		Conflict: entity.ConflictSkip,
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+	encoded := json.NewEncoder(os.Stdout)
+	encoded.SetIndent("", "  ")
+	return encoded.Encode(report)
+}
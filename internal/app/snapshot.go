@@ -0,0 +1,103 @@
+package app
+
+import (
+	"banner/config"
+	"banner/pkg/cache"
+	"banner/pkg/logger"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// CacheSnapshot - команда cache-snapshot inspect: печатает записи снимка кэша
+func CacheSnapshot(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "inspect" {
+		return errors.New("cache-snapshot: expected inspect")
+	}
+	flags := flag.NewFlagSet("cache-snapshot inspect", flag.ContinueOnError)
+	file := flags.String("file", cfg.Cache.SnapshotPath, "файл снимка, по умолчанию cache.snapshot_path")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("cache-snapshot inspect: snapshot file is not set")
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	now := time.Now()
+	var total, expired int
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TAG\tFEATURE\tFREQ\tEXPIRES IN\tETAG\tSIZE")
+	err = cache.ReadSnapshot(f, func(entry cache.SnapshotEntry) error {
+		total++
+		expiresIn := entry.Expiry.Sub(now).Truncate(time.Second)
+		if expiresIn <= 0 {
+			expired++
+		}
+		size, err := json.Marshal(entry.Value)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%s\t%d\n", entry.Part1, entry.Part2, entry.Freq, expiresIn, entry.Tag, len(size))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("cache-snapshot inspect: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("entries: %d, expired: %d\n", total, expired)
+	return nil
+}
+
+// restoreCache загружает кэш из снимка, если он есть
+func restoreCache(memCache *cache.MemoryCache, path string, l logger.Logger) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		l.Error("app - restoreCache: %v", err)
+		return
+	}
+	defer f.Close()
+	restored, err := memCache.Restore(f)
+	if err != nil {
+		l.Error("app - restoreCache: %v", err)
+		return
+	}
+	l.Info("Restored %d cache entries from %s", restored, path)
+}
+
+// saveCache записывает снимок кэша во временный файл и переименовывает его, чтобы не оставить
+// недописанный снимок
+func saveCache(memCache *cache.MemoryCache, path string, l logger.Logger) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		l.Error("app - saveCache: %v", err)
+		return
+	}
+	saved, err := memCache.Snapshot(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		l.Error("app - saveCache: %v", err)
+		return
+	}
+	l.Info("Saved %d cache entries to %s", saved, path)
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"banner/config"
+	"banner/pkg/token"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// Token - команда token issue: выпускает токен доступа, подписанный auth.token_secret
+func Token(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "issue" {
+		return errors.New("token: expected issue")
+	}
+	flags := flag.NewFlagSet("token issue", flag.ContinueOnError)
+	role := flags.String("role", string(token.RoleUser), "роль: admin или user")
+	ttl := flags.Duration("ttl", 24*time.Hour, "срок действия токена")
+	subject := flags.String("subject", "", "кому выдан токен")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if cfg.Auth.TokenSecret == "" {
+		return errors.New("token issue: auth.token_secret is not set")
+	}
+	if *ttl <= 0 {
+		return errors.New("token issue: ttl must be positive")
+	}
+	issued, err := token.Issue([]byte(cfg.Auth.TokenSecret), *subject, token.Role(*role), *ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(issued)
+	return nil
+}
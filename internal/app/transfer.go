@@ -0,0 +1,118 @@
+package app
+
+import (
+	"banner/config"
+	"banner/internal/entity"
+	"banner/pkg/cache"
+	"banner/pkg/db/postgres"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Export - команда export: выгружает баннеры в файл или stdout
+func Export(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", string(entity.FormatJSONL), "формат: jsonl или csv")
+	featureID := flags.Int("feature-id", 0, "выгрузить только баннеры фичи")
+	tagID := flags.Int("tag-id", 0, "выгрузить только баннеры с тегом")
+	out := flags.String("out", "", "файл выгрузки, по умолчанию stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	pg, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+	bannerService, _ := newServices(pg, cache.NewMemoryCache(1, 1))
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	return bannerService.Export(context.Background(), w, entity.TransferFormat(*format), &entity.BannerFilter{
+		FeatureID: optionalID(*featureID),
+		TagID:     optionalID(*tagID),
+	})
+}
+
+// Import - команда import: загружает баннеры из файла или stdin и печатает отчет
+func Import(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "формат: jsonl или csv, по умолчанию по расширению файла")
+	file := flags.String("file", "", "файл загрузки, по умолчанию stdin")
+	conflict := flags.String("conflict", string(entity.ConflictSkip), "при конфликте: skip, overwrite или fail")
+	dryRun := flags.Bool("dry-run", false, "проверить загрузку, ничего не сохраняя")
+	preserveIDs := flags.Bool("preserve-ids", false, "сохранить id баннеров из файла")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	switch entity.ConflictStrategy(*conflict) {
+	case entity.ConflictSkip, entity.ConflictOverwrite, entity.ConflictFail:
+	default:
+		return fmt.Errorf("import: unknown conflict strategy %q", *conflict)
+	}
+	if *format == "" {
+		*format = string(entity.FormatJSONL)
+		if strings.EqualFold(filepath.Ext(*file), ".csv") {
+			*format = string(entity.FormatCSV)
+		}
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	pg, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+	bannerService, _ := newServices(pg, cache.NewMemoryCache(1, 1))
+
+	report, err := bannerService.Import(context.Background(), r, &entity.ImportOptions{
+		Format:      entity.TransferFormat(*format),
+		Conflict:    entity.ConflictStrategy(*conflict),
+		DryRun:      *dryRun,
+		PreserveIDs: *preserveIDs,
+	})
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if encodeErr := encoder.Encode(report); encodeErr != nil {
+			return encodeErr
+		}
+	}
+	return err
+}
+
+func connect(cfg *config.Config) (*postgres.DB, error) {
+	pgURL, _ := os.LookupEnv("PG_URL")
+	return postgres.New(pgURL, cfg.PG.PoolMax, cfg.PG.ConnAttempts, cfg.PG.ConnTimeout)
+}
+
+func optionalID(id int) *int32 {
+	if id == 0 {
+		return nil
+	}
+	converted := int32(id)
+	return &converted
+}
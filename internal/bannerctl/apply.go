@@ -0,0 +1,171 @@
+package bannerctl
+
+import (
+	"banner/pkg/client"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest - баннер в файле. Файл с id описывает существующий баннер, без id - новый.
+// version - ожидаемая версия существующего баннера
+type Manifest struct {
+	ID      *int32 `json:"id,omitempty"`
+	Version *int32 `json:"version,omitempty"`
+	client.NewBanner
+}
+
+// manifestExtensions - расширения файлов, которые читает apply
+var manifestExtensions = map[string]bool{".json": true, ".yaml": true, ".yml": true}
+
+// apply собирает баннеры из файлов каталога в один пакет bulk: баннеры с id обновляются целиком,
+// остальные создаются. Файлы обрабатываются в порядке имен
+func apply(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("apply")
+	dir := flags.String("d", "", "каталог с файлами баннеров .json, .yaml и .yml")
+	mode := flags.String("mode", "atomic", "atomic - все или ничего, best_effort - каждый файл независимо")
+	dryRun := flags.Bool("dry-run", false, "показать операции, не выполняя их")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return errors.New("apply: expected -d DIR")
+	}
+	files, err := manifestFiles(*dir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("apply: no banner files in %s", *dir)
+	}
+	request := &client.BulkRequest{Mode: *mode}
+	for _, file := range files {
+		var manifest Manifest
+		if err := readManifest(file, &manifest); err != nil {
+			return err
+		}
+		request.Operations = append(request.Operations, manifest.operation())
+	}
+	if *dryRun {
+		return render(env.Out, env.Format, request.Operations, func() *table {
+			t := &table{header: []string{"FILE", "ACTION", "ID"}}
+			for i, operation := range request.Operations {
+				id := ""
+				if operation.ID != nil {
+					id = strconv.Itoa(int(*operation.ID))
+				}
+				t.rows = append(t.rows, []string{files[i], operation.Action, id})
+			}
+			return t
+		})
+	}
+
+	// отмененный атомарный пакет возвращает и результаты, и ошибку: результаты печатаются, чтобы было
+	// видно, какая операция его отменила
+	response, err := env.Client.Bulk(ctx, request)
+	if response == nil {
+		return err
+	}
+	if err := render(env.Out, env.Format, response, func() *table {
+		t := &table{header: []string{"FILE", "ACTION", "ID", "STATUS", "ERROR"}}
+		for _, result := range response.Results {
+			id := ""
+			if result.ID != nil {
+				id = strconv.Itoa(int(*result.ID))
+			}
+			t.rows = append(t.rows, []string{files[result.Index], result.Action, id, result.Status, resultError(result)})
+		}
+		return t
+	}); err != nil {
+		return err
+	}
+	if response.Failed > 0 {
+		return fmt.Errorf("apply: %d of %d operations failed", response.Failed, len(request.Operations))
+	}
+	return err
+}
+
+func (m *Manifest) operation() client.BulkOperation {
+	if m.ID == nil {
+		return client.BulkOperation{Action: "create", Banner: &m.NewBanner}
+	}
+	return client.BulkOperation{
+		Action: "update",
+		ID:     m.ID,
+		Update: &client.BannerUpdate{
+			TagIDs:    &m.TagIDs,
+			FeatureID: &m.FeatureID,
+			Content:   &m.Content,
+			IsActive:  &m.IsActive,
+		},
+		Version: m.Version,
+	}
+}
+
+func resultError(result *client.BulkResult) string {
+	message := result.Error
+	for _, violation := range result.Violations {
+		message += fmt.Sprintf("; %s: %s", violation.Pointer, violation.Message)
+	}
+	return message
+}
+
+func manifestFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && manifestExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readManifest читает баннер из JSON или YAML, формат определяется по расширению. "-" - stdin в любом из форматов
+func readManifest(path string, manifest *Manifest) error {
+	var (
+		raw []byte
+		err error
+	)
+	if path == "-" {
+		raw, err = io.ReadAll(os.Stdin)
+	} else {
+		raw, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(raw, manifest); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		return nil
+	}
+	// JSON - подмножество YAML, поэтому stdin и .yaml разбираются как YAML. Дальше значение проходит
+	// через json, чтобы поля разбирались по тем же тегам, что и в API
+	var generic interface{}
+	if err := yaml.Unmarshal(raw, &generic); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	converted, err := json.Marshal(generic)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := json.Unmarshal(converted, manifest); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
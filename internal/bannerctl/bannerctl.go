@@ -0,0 +1,133 @@
+// Package bannerctl - команды административного клиента сервиса баннеров
+package bannerctl
+
+import (
+	"banner/pkg/client"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Env - окружение команды: клиент API и формат вывода
+type Env struct {
+	Client *client.Client
+	Out    io.Writer
+	Format Format
+}
+
+type Command struct {
+	Usage string
+	Run   func(ctx context.Context, env *Env, args []string) error
+}
+
+var Commands = map[string]Command{
+	"create":      {"-f FILE - создать баннер из JSON или YAML", create},
+	"list":        {"[-feature-id ID] [-tag-id ID] [-active true|false] [-q TEXT] [-sort FIELD] [-order asc|desc] [-limit N] [-offset N] [-all] - список баннеров", list},
+	"get":         {"ID - показать баннер", get},
+	"history":     {"ID - предыдущие версии баннера", history},
+	"diff":        {"ID [-version N] - отличия текущей версии от версии N, по умолчанию от предыдущей", diff},
+	"rollback":    {"ID -version N [-if-version V] - вернуть баннер к версии из истории", rollback},
+	"activate":    {"ID [-if-version V] - включить баннер", activate},
+	"deactivate":  {"ID [-if-version V] - выключить баннер", deactivate},
+	"delete":      {"ID [-if-version V] - перенести баннер в корзину", remove},
+	"apply":       {"-d DIR [-mode atomic|best_effort] [-dry-run] - создать и обновить баннеры из файлов каталога одним пакетом", apply},
+	"user-banner": {"-tag-id ID -feature-id ID [-last] - баннер пользователя", userBanner},
+	"trash":       {"[-limit N] [-offset N] - баннеры в корзине", trash},
+	"restore":     {"ID [-if-version V] - вернуть баннер из корзины", restore},
+	"delete-by":   {"[-feature-id ID] [-tag-id ID] [-wait] - удалить баннеры по фиче и/или тегу в фоне", deleteBy},
+	"job":         {"ID - состояние фоновой задачи", job},
+}
+
+var Order = []string{
+	"list", "get", "create", "apply", "activate", "deactivate", "delete", "history", "diff", "rollback",
+	"trash", "restore", "delete-by", "job", "user-banner",
+}
+
+// parseFlags разбирает флаги вперемешку с позиционными аргументами, чтобы работали и diff 5 -version 3,
+// и diff -version 3 5
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// parseID разбирает единственный позиционный аргумент - id баннера
+func parseID(flags *flag.FlagSet, args []string) (int32, error) {
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return 0, err
+	}
+	if len(positional) != 1 {
+		return 0, fmt.Errorf("%s: expected banner ID", flags.Name())
+	}
+	id, err := strconv.ParseInt(positional[0], 10, 32)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%s: invalid banner ID %q", flags.Name(), positional[0])
+	}
+	return int32(id), nil
+}
+
+// optionalInt32 - флаг, который может быть не задан
+type optionalInt32 struct {
+	value *int32
+}
+
+func (o *optionalInt32) String() string {
+	if o.value == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*o.value))
+}
+
+func (o *optionalInt32) Set(raw string) error {
+	value, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil {
+		return errors.New("expected integer")
+	}
+	converted := int32(value)
+	o.value = &converted
+	return nil
+}
+
+// optionalBool - флаг true/false, который может быть не задан
+type optionalBool struct {
+	value *bool
+}
+
+func (o *optionalBool) String() string {
+	if o.value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*o.value)
+}
+
+func (o *optionalBool) Set(raw string) error {
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return errors.New("expected true or false")
+	}
+	o.value = &value
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
+
+// ifVersion - флаг -if-version: ожидаемая версия баннера для If-Match
+func ifVersion(flags *flag.FlagSet) *optionalInt32 {
+	version := &optionalInt32{}
+	flags.Var(version, "if-version", "изменить, только если версия баннера равна V")
+	return version
+}
@@ -0,0 +1,334 @@
+package bannerctl
+
+import (
+	"banner/pkg/client"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// jobPollInterval - как часто delete-by -wait проверяет состояние задачи
+const jobPollInterval = time.Second
+
+func create(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("create")
+	file := flags.String("f", "", "файл баннера в JSON или YAML, - для stdin")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("create: expected -f FILE")
+	}
+	var manifest Manifest
+	if err := readManifest(*file, &manifest); err != nil {
+		return err
+	}
+	id, err := env.Client.Save(ctx, &manifest.NewBanner)
+	if err != nil {
+		return err
+	}
+	return render(env.Out, env.Format, map[string]int32{"banner_id": id}, func() *table {
+		return &table{header: []string{"BANNER_ID"}, rows: [][]string{{strconv.Itoa(int(id))}}}
+	})
+}
+
+func list(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("list")
+	var (
+		query     client.BannersQuery
+		featureID optionalInt32
+		tagID     optionalInt32
+		isActive  optionalBool
+		limit     optionalInt32
+		offset    optionalInt32
+	)
+	flags.Var(&featureID, "feature-id", "фича")
+	flags.Var(&tagID, "tag-id", "тег")
+	flags.Var(&isActive, "active", "только активные (true) или выключенные (false)")
+	flags.StringVar(&query.Search, "q", "", "поиск по содержимому")
+	flags.StringVar(&query.ContentPath, "content-path", "", "фильтр по значению в содержимом, например /title=Sale")
+	flags.StringVar(&query.SortBy, "sort", "", "сортировка: id, created_at, updated_at или feature_id")
+	flags.StringVar(&query.Order, "order", "", "порядок: asc или desc")
+	flags.Var(&limit, "limit", "размер страницы")
+	flags.Var(&offset, "offset", "сколько баннеров пропустить")
+	all := flags.Bool("all", false, "выгрузить все страницы по курсору")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	query.FeatureID, query.TagID, query.IsActive = featureID.value, tagID.value, isActive.value
+	query.Limit, query.Offset = limit.value, offset.value
+
+	var banners []*client.Banner
+	if *all {
+		if query.Offset != nil {
+			return errors.New("list: -all cannot be combined with -offset")
+		}
+		for cursor := ""; ; {
+			page, err := env.Client.GetBannersPage(ctx, &query, cursor)
+			if err != nil {
+				return err
+			}
+			banners = append(banners, page.Banners...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+	} else {
+		var err error
+		if banners, err = env.Client.GetBanners(ctx, &query); err != nil {
+			return err
+		}
+	}
+	return render(env.Out, env.Format, banners, func() *table {
+		return bannersTable(banners)
+	})
+}
+
+func get(ctx context.Context, env *Env, args []string) error {
+	id, err := parseID(newFlagSet("get"), args)
+	if err != nil {
+		return err
+	}
+	banner, err := env.Client.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return render(env.Out, env.Format, banner, func() *table {
+		return bannersTable([]*client.Banner{banner})
+	})
+}
+
+func history(ctx context.Context, env *Env, args []string) error {
+	id, err := parseID(newFlagSet("history"), args)
+	if err != nil {
+		return err
+	}
+	items, err := env.Client.GetBannersHistoryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return render(env.Out, env.Format, items, func() *table {
+		banners := make([]*client.Banner, len(items))
+		for i, item := range items {
+			banners[i] = item.Banner
+		}
+		return bannersTable(banners)
+	})
+}
+
+// diff сравнивает версию баннера из истории с текущей через GET /banner/:id/diff. В таблице выводится
+// построчный diff, в json и yaml - ответ сервиса
+func diff(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("diff")
+	var version optionalInt32
+	flags.Var(&version, "version", "версия из истории, по умолчанию предыдущая")
+	id, err := parseID(flags, args)
+	if err != nil {
+		return err
+	}
+	from := version.value
+	if from == nil {
+		if from, err = previousVersion(ctx, env, id); err != nil {
+			return err
+		}
+	}
+	changes, err := env.Client.Diff(ctx, id, *from, nil, env.Format == FormatTable)
+	if err != nil {
+		return err
+	}
+	if env.Format != FormatTable {
+		return render(env.Out, env.Format, changes, nil)
+	}
+	_, err = io.WriteString(env.Out, changes.Unified)
+	return err
+}
+
+// previousVersion - версия из истории, предшествующая текущей
+func previousVersion(ctx context.Context, env *Env, id int32) (*int32, error) {
+	current, err := env.Client.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := env.Client.GetBannersHistoryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// история отдается новыми версиями первыми
+	for _, item := range items {
+		if item.Banner.Version < current.Version {
+			return &item.Banner.Version, nil
+		}
+	}
+	return nil, fmt.Errorf("diff: banner %d has no previous versions", id)
+}
+
+func rollback(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("rollback")
+	var version optionalInt32
+	flags.Var(&version, "version", "версия из истории")
+	expected := ifVersion(flags)
+	id, err := parseID(flags, args)
+	if err != nil {
+		return err
+	}
+	if version.value == nil {
+		return errors.New("rollback: expected -version N")
+	}
+	if err := env.Client.Rollback(ctx, id, *version.value, expected.value); err != nil {
+		return err
+	}
+	return get(ctx, env, []string{strconv.Itoa(int(id))})
+}
+
+func activate(ctx context.Context, env *Env, args []string) error {
+	return setActive(ctx, env, "activate", true, args)
+}
+
+func deactivate(ctx context.Context, env *Env, args []string) error {
+	return setActive(ctx, env, "deactivate", false, args)
+}
+
+func setActive(ctx context.Context, env *Env, name string, isActive bool, args []string) error {
+	flags := newFlagSet(name)
+	expected := ifVersion(flags)
+	id, err := parseID(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := env.Client.Update(ctx, id, &client.BannerUpdate{IsActive: &isActive, Version: expected.value}); err != nil {
+		return err
+	}
+	return get(ctx, env, []string{strconv.Itoa(int(id))})
+}
+
+func remove(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("delete")
+	expected := ifVersion(flags)
+	id, err := parseID(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := env.Client.Delete(ctx, id, expected.value); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Banner %d moved to trash\n", id)
+	return nil
+}
+
+func userBanner(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("user-banner")
+	var tagID, featureID optionalInt32
+	flags.Var(&tagID, "tag-id", "тег пользователя")
+	flags.Var(&featureID, "feature-id", "фича")
+	last := flags.Bool("last", false, "актуальная версия в обход кэша")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if tagID.value == nil || featureID.value == nil {
+		return errors.New("user-banner: expected -tag-id and -feature-id")
+	}
+	content, err := env.Client.GetForUser(ctx, *tagID.value, *featureID.value, *last)
+	if err != nil {
+		return err
+	}
+	if env.Format == FormatTable {
+		// у содержимого нет фиксированных колонок
+		return render(env.Out, FormatJSON, content, nil)
+	}
+	return render(env.Out, env.Format, content, nil)
+}
+
+func trash(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("trash")
+	var limit, offset optionalInt32
+	flags.Var(&limit, "limit", "размер страницы")
+	flags.Var(&offset, "offset", "сколько баннеров пропустить")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	banners, err := env.Client.Trash(ctx, limit.value, offset.value)
+	if err != nil {
+		return err
+	}
+	return render(env.Out, env.Format, banners, func() *table {
+		t := bannersTable(nil)
+		t.header = append(t.header, "DELETED")
+		for _, banner := range banners {
+			t.rows = append(t.rows, append(bannerRow(&banner.Banner), banner.DeletedAt.Local().Format(time.DateTime)))
+		}
+		return t
+	})
+}
+
+func restore(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("restore")
+	expected := ifVersion(flags)
+	id, err := parseID(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := env.Client.Restore(ctx, id, expected.value); err != nil {
+		return err
+	}
+	return get(ctx, env, []string{strconv.Itoa(int(id))})
+}
+
+func deleteBy(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("delete-by")
+	var featureID, tagID optionalInt32
+	flags.Var(&featureID, "feature-id", "фича")
+	flags.Var(&tagID, "tag-id", "тег")
+	wait := flags.Bool("wait", false, "дождаться завершения задачи")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if featureID.value == nil && tagID.value == nil {
+		return errors.New("delete-by: expected -feature-id and/or -tag-id")
+	}
+	queued, err := env.Client.DeleteBanners(ctx, featureID.value, tagID.value)
+	if err != nil {
+		return err
+	}
+	for *wait && queued.Status != "done" && queued.Status != "failed" {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jobPollInterval):
+		}
+		if queued, err = env.Client.GetJob(ctx, queued.ID); err != nil {
+			return err
+		}
+	}
+	return printJob(env, queued)
+}
+
+func job(ctx context.Context, env *Env, args []string) error {
+	flags := newFlagSet("job")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("job: expected job ID")
+	}
+	id, err := strconv.ParseInt(positional[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("job: invalid job ID %q", positional[0])
+	}
+	found, err := env.Client.GetJob(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printJob(env, found)
+}
+
+func printJob(env *Env, found *client.Job) error {
+	return render(env.Out, env.Format, found, func() *table {
+		return jobsTable(found)
+	})
+}
@@ -0,0 +1,135 @@
+package bannerctl
+
+import (
+	"banner/pkg/client"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format - формат вывода команд
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+)
+
+func ParseFormat(raw string) (Format, error) {
+	switch format := Format(raw); format {
+	case FormatTable, FormatJSON, FormatYAML:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown output format %q, expected table, json or yaml", raw)
+}
+
+// table - табличное представление результата команды
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// render выводит value в формате format. Для table используется rows, value выводится как есть
+// в json и yaml, чтобы вывод можно было передать в create или apply
+func render(out io.Writer, format Format, value interface{}, rows func() *table) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case FormatYAML:
+		// yaml.v3 не знает json-тегов, поэтому значение проходит через json
+		generic, err := toGeneric(value)
+		if err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(generic); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	t := rows()
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func toGeneric(value interface{}) (interface{}, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+func bannersTable(banners []*client.Banner) *table {
+	t := &table{header: []string{"ID", "FEATURE", "TAGS", "ACTIVE", "VERSION", "UPDATED", "CONTENT"}}
+	for _, banner := range banners {
+		t.rows = append(t.rows, bannerRow(banner))
+	}
+	return t
+}
+
+func bannerRow(banner *client.Banner) []string {
+	return []string{
+		strconv.Itoa(int(banner.ID)),
+		strconv.Itoa(int(banner.FeatureID)),
+		joinIDs(banner.TagIDs),
+		strconv.FormatBool(banner.IsActive),
+		strconv.Itoa(int(banner.Version)),
+		banner.UpdatedAt.Local().Format(time.DateTime),
+		contentSummary(banner.Content),
+	}
+}
+
+func jobsTable(jobs ...*client.Job) *table {
+	t := &table{header: []string{"ID", "KIND", "STATUS", "PROGRESS", "ATTEMPTS", "ERROR"}}
+	for _, job := range jobs {
+		t.rows = append(t.rows, []string{
+			strconv.FormatInt(job.ID, 10),
+			job.Kind,
+			job.Status,
+			fmt.Sprintf("%d/%d", job.Processed, job.Total),
+			fmt.Sprintf("%d/%d", job.Attempts, job.MaxAttempts),
+			job.Error,
+		})
+	}
+	return t
+}
+
+func joinIDs(ids []int32) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(int(id))
+	}
+	return strings.Join(parts, ",")
+}
+
+// contentSummary - содержимое одной строкой, длинное обрезается
+func contentSummary(content map[string]interface{}) string {
+	const maxLength = 60
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return ""
+	}
+	if summary := []rune(string(raw)); len(summary) > maxLength {
+		return string(summary[:maxLength-3]) + "..."
+	}
+	return string(raw)
+}
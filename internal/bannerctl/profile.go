@@ -0,0 +1,85 @@
+package bannerctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoServer - адрес сервиса не задан ни в профиле, ни в BANNER_URL
+var ErrNoServer = errors.New("banner service URL is not set")
+
+// Profile - адрес сервиса и токен администратора
+type Profile struct {
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+}
+
+// profilesFile - файл профилей: профиль current используется, если --profile не задан
+type profilesFile struct {
+	Current  string             `yaml:"current"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// DefaultProfilesPath - $BANNERCTL_CONFIG или ~/.config/bannerctl/config.yaml
+func DefaultProfilesPath() string {
+	if path, ok := os.LookupEnv("BANNERCTL_CONFIG"); ok {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bannerctl", "config.yaml")
+}
+
+// LoadProfile читает профиль name из файла path. Отсутствие файла не ошибка, если профиль не
+// запрошен явно. BANNER_URL и BANNER_TOKEN переопределяют значения профиля
+func LoadProfile(path, name string) (*Profile, error) {
+	var profile Profile
+	file, err := readProfiles(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && name == "":
+	case err != nil:
+		return nil, err
+	default:
+		if name == "" {
+			name = file.Current
+		}
+		if name != "" {
+			found, ok := file.Profiles[name]
+			if !ok {
+				return nil, fmt.Errorf("profile %q not found in %s", name, path)
+			}
+			profile = found
+		}
+	}
+	if url, ok := os.LookupEnv("BANNER_URL"); ok {
+		profile.URL = url
+	}
+	if token, ok := os.LookupEnv("BANNER_TOKEN"); ok {
+		profile.Token = token
+	}
+	if profile.URL == "" {
+		return nil, ErrNoServer
+	}
+	return &profile, nil
+}
+
+func readProfiles(path string) (*profilesFile, error) {
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file profilesFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &file, nil
+}
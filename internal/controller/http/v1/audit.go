@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"banner/internal/service"
+	"banner/pkg/i18n"
+	"banner/pkg/logger"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// defaultAuditLimit - сколько записей отдает /audit без limit
+const defaultAuditLimit = 100
+
+type AuditController struct {
+	controller
+	audit service.AuditLog
+}
+
+func NewAuditController(audit service.AuditLog, logger logger.Logger, messages *i18n.Catalog) *AuditController {
+	return &AuditController{
+		controller: controller{l: logger, messages: messages},
+		audit:      audit,
+	}
+}
+
+func (h *AuditController) Register(group *gin.RouterGroup) {
+	group.GET("/audit", h.getAudit)
+	group.GET("/audit/export", h.exportAudit)
+}
+
+// auditQuery - фильтры аудита, limit - не больше 1000 записей за запрос
+type auditQuery struct {
+	BannerID *int32                 `form:"banner_id" binding:"omitempty,gt=0"`
+	Actor    string                 `form:"actor" binding:"max=200"`
+	Action   entity.BannerEventType `form:"action" binding:"omitempty,oneof=created updated deleted restored activated deactivated"`
+	Limit    *int32                 `form:"limit" binding:"omitempty,gt=0,lte=1000"`
+	Offset   *int32                 `form:"offset" binding:"omitempty,gte=0"`
+}
+
+// getAudit отдает записи аудита по фильтрам, новые первыми
+func (h *AuditController) getAudit(c *gin.Context) {
+	query, ok := h.parseAuditQuery(c)
+	if !ok {
+		return
+	}
+	if query.Limit == nil {
+		limit := int32(defaultAuditLimit)
+		query.Limit = &limit
+	}
+	entries, err := h.audit.List(c.Request.Context(), query)
+	if err != nil {
+		h.l.Error("Failed to get audit log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	if entries == nil {
+		entries = []*entity.AuditEntry{}
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// exportAudit выгружает в CSV все записи аудита по фильтрам, без лимита по умолчанию
+func (h *AuditController) exportAudit(c *gin.Context) {
+	query, ok := h.parseAuditQuery(c)
+	if !ok {
+		return
+	}
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="audit.csv"`)
+	c.Status(http.StatusOK)
+	if err := h.audit.Export(c.Request.Context(), c.Writer, query); err != nil {
+		h.l.Error("Failed to export audit log: %v", err)
+		// если выгрузка уже началась, статус изменить нельзя - клиент получит оборванный файл
+		if !c.Writer.Written() {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		}
+		return
+	}
+	h.l.Info("Audit log exported successfully")
+}
+
+// parseAuditQuery проверяет права администратора и разбирает фильтры аудита. Время from и to -
+// в RFC 3339 или в виде 2006-01-02, from включается, to - нет
+func (h *AuditController) parseAuditQuery(c *gin.Context) (*entity.AuditQuery, bool) {
+	if !c.GetBool("isAdmin") {
+		c.JSON(http.StatusForbidden, nil)
+		return nil, false
+	}
+	fields := fieldErrors{}
+	query := auditQuery{
+		BannerID: h.queryInt32(c, "banner_id", fields),
+		Actor:    strings.TrimSpace(c.Query("actor")),
+		Action:   entity.BannerEventType(c.Query("action")),
+		Limit:    h.queryInt32(c, "limit", fields),
+		Offset:   h.queryInt32(c, "offset", fields),
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	audit := &entity.AuditQuery{
+		BannerID: query.BannerID,
+		From:     h.queryTime(c, "from", fields),
+		To:       h.queryTime(c, "to", fields),
+		Limit:    query.Limit,
+	}
+	if audit.From != nil && audit.To != nil && !audit.From.Before(*audit.To) {
+		fields["to"] = h.localize(c, msgValidationDateRange)
+	}
+	if len(fields) > 0 {
+		h.l.Error("Failed to parse audit query: %v", fields)
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return nil, false
+	}
+	if query.Actor != "" {
+		audit.Actor = &query.Actor
+	}
+	if query.Action != "" {
+		audit.Action = &query.Action
+	}
+	if query.Offset != nil {
+		audit.Offset = *query.Offset
+	}
+	return audit, true
+}
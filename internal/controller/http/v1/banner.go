@@ -2,22 +2,31 @@ package v1
 
 import (
 	"banner/internal/entity"
+	"banner/internal/repository"
 	"banner/internal/service"
+	"banner/pkg/i18n"
 	"banner/pkg/logger"
+	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"net/http"
 	"strconv"
 )
 
 type BannerController struct {
+	controller
 	bannerService service.Service
-	l             logger.Logger
+	idempotency   service.IdempotencyStore
+	tokenSecret   []byte
+	requireReview bool
 }
 
-func NewBannerController(bannerService service.Service, logger logger.Logger) *BannerController {
+func NewBannerController(bannerService service.Service, logger logger.Logger, messages *i18n.Catalog) *BannerController {
 	return &BannerController{
+		controller:    controller{l: logger, messages: messages},
 		bannerService: bannerService,
-		l:             logger,
 	}
 }
 func (h *BannerController) createBanner(c *gin.Context) {
@@ -30,17 +39,20 @@ func (h *BannerController) createBanner(c *gin.Context) {
 	err := c.ShouldBindJSON(&banner)
 	if err != nil {
 		h.l.Error("Failed to parse request data: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные"})
+		h.abortWithValidation(c, msgInvalidData, h.validationErrors(c, err))
 		return
 	}
 	bannerID, err := h.bannerService.Save(c.Request.Context(), &banner)
 	if err != nil {
 		h.l.Error("Failed to create banner: %v", err)
+		if h.abortOnContentMismatch(c, err) {
+			return
+		}
 		if err.Error() == "record with same featureId and tagId already exists" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Баннер с таким featureId и tagId уже существует"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgBannerExists)})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
 		return
 	}
 	banner.ID = bannerID
@@ -60,16 +72,16 @@ func (h *BannerController) getBanner(c *gin.Context) {
 	tagID, err := strconv.ParseInt(c.Query("tag_id"), 10, 32)
 	if err != nil {
 		h.l.Error("Failed to parse tag ID: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректные данные tagId"})
+		c.JSON(http.StatusBadRequest, gin.H{"message": h.localize(c, msgInvalidTagID)})
 		return
 	}
 	featureID, err := strconv.ParseInt(c.Query("feature_id"), 10, 32)
 	if err != nil {
 		h.l.Error("Failed to parse feature ID: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректные данные featureId"})
+		c.JSON(http.StatusBadRequest, gin.H{"message": h.localize(c, msgInvalidFeatureID)})
 		return
 	}
-	content, err := h.bannerService.GetForUser(c.Request.Context(), int32(tagID), int32(featureID), isAdmin.(bool), lastRevision)
+	banner, err := h.bannerService.GetForUser(c.Request.Context(), int32(tagID), int32(featureID), isAdmin.(bool), lastRevision)
 	if err != nil {
 		if err.Error() == "no banner found" {
 			h.l.Info("No banner found for tag ID: %d, feature ID: %d", tagID, featureID)
@@ -77,11 +89,17 @@ func (h *BannerController) getBanner(c *gin.Context) {
 			return
 		}
 		h.l.Error("Failed to get content: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	c.Header("ETag", banner.ETag)
+	c.Header("Cache-Control", cacheControl(banner.MaxAge))
+	if ifNoneMatch(c, banner.ETag) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 	h.l.Info("Content retrieved successfully")
-	c.JSON(http.StatusOK, content)
+	c.JSON(http.StatusOK, banner.Content)
 
 }
 func (h *BannerController) getBanners(c *gin.Context) {
@@ -90,33 +108,97 @@ func (h *BannerController) getBanners(c *gin.Context) {
 		c.JSON(http.StatusForbidden, nil)
 		return
 	}
-	featureID, _ := strconv.ParseInt(c.DefaultQuery("feature_id", "0"), 10, 32)
-	tagID, _ := strconv.ParseInt(c.DefaultQuery("tag_id", "0"), 10, 32)
-	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "0"), 10, 32)
-	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
-
-	var featureIDPtr, tagIDPtr, limitPtr *int32
-	if featureID != 0 {
-		featureIDConverted := int32(featureID)
-		featureIDPtr = &featureIDConverted
+	query, fields := h.parseBannersQuery(c)
+	if len(fields) > 0 {
+		h.l.Error("Failed to parse banners query: %v", fields)
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
 	}
-	if tagID != 0 {
-		tagIDConverted := int32(tagID)
-		tagIDPtr = &tagIDConverted
+	var offset int32
+	if query.Offset != nil {
+		offset = *query.Offset
 	}
-	if limit != 0 {
-		limitConverted := int32(limit)
-		limitPtr = &limitConverted
+	limit := query.Limit
+	if limit != nil && *limit == 0 {
+		limit = nil
 	}
 
-	banners, err := h.bannerService.GetBanners(c.Request.Context(), featureIDPtr, tagIDPtr, limitPtr, int32(offset))
+	page, err := h.bannerService.GetBanners(c.Request.Context(), &entity.BannersQuery{
+		BannerFilter: *query.Filter,
+		SortBy:       query.SortBy,
+		Order:        query.Order,
+		Limit:        limit,
+		Offset:       offset,
+		Keyset:       query.Keyset,
+		Cursor:       query.Cursor,
+	})
+	if errors.Is(err, repository.ErrInvalidFilter) {
+		h.l.Info("Invalid banners filter: %v", err)
+		h.abortWithValidation(c, msgInvalidQuery, fieldErrors{"content_path": h.localize(c, msgValidationInvalid)})
+		return
+	}
 	if err != nil {
 		h.l.Error("Failed to get banners: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
 		return
 	}
 	h.l.Info("Banners retrieved successfully")
-	c.JSON(http.StatusOK, banners)
+	c.Header("ETag", listETag(page.Banners))
+	// без cursor ответ остается массивом, как до появления постраничной выдачи
+	if !query.Keyset {
+		c.JSON(http.StatusOK, page.Banners)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// bannersQuery - фильтры и пагинация списка баннеров, limit=0 означает отсутствие лимита
+type bannersQuery struct {
+	Filter *entity.BannerFilter `binding:"-"`
+	Limit  *int32               `form:"limit" binding:"omitempty,gte=0"`
+	Offset *int32               `form:"offset" binding:"omitempty,gte=0"`
+	SortBy entity.SortField     `form:"sort_by" binding:"omitempty,oneof=id created_at updated_at feature_id"`
+	Order  entity.SortOrder     `form:"order" binding:"omitempty,oneof=asc desc"`
+	// Keyset - в запросе есть cursor, пустой cursor означает первую страницу
+	Keyset bool
+	Cursor *entity.BannerCursor
+}
+
+func (h *BannerController) parseBannersQuery(c *gin.Context) (*bannersQuery, fieldErrors) {
+	fields := fieldErrors{}
+	query := &bannersQuery{
+		Filter: h.parseBannerFilter(c, fields),
+		Limit:  h.queryInt32(c, "limit", fields),
+		Offset: h.queryInt32(c, "offset", fields),
+		SortBy: entity.SortField(c.Query("sort_by")),
+		Order:  entity.SortOrder(c.Query("order")),
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	rawCursor, keyset := c.GetQuery("cursor")
+	query.Keyset = keyset
+	if rawCursor != "" {
+		cursor, err := entity.DecodeCursor(rawCursor)
+		// сортировка продолжения должна совпадать с сортировкой, по которой выдан курсор
+		if err != nil || (query.SortBy != "" && query.SortBy != cursor.SortBy) || (query.Order != "" && query.Order != cursor.Order) {
+			fields["cursor"] = h.localize(c, msgValidationInvalid)
+			return query, fields
+		}
+		query.Cursor, query.SortBy, query.Order = cursor, cursor.SortBy, cursor.Order
+	}
+	if query.Keyset && query.Offset != nil {
+		fields["offset"] = h.localize(c, msgValidationInvalid)
+	}
+	if query.SortBy == "" {
+		query.SortBy = entity.SortByID
+	}
+	if query.Order == "" {
+		query.Order = entity.OrderAsc
+	}
+	return query, fields
 }
 func (h *BannerController) deleteBanner(c *gin.Context) {
 	token := c.GetBool("isAdmin")
@@ -127,18 +209,28 @@ func (h *BannerController) deleteBanner(c *gin.Context) {
 	bannerID, err := strconv.ParseInt(c.Param("id"), 10, 32)
 	if err != nil {
 		h.l.Error("Failed to parse banner ID: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные bannerID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidBannerID)})
 		return
 	}
-	err = h.bannerService.Delete(c.Request.Context(), int32(bannerID))
+	version, ok := ifMatch(c)
+	if !ok {
+		h.abortPreconditionFailed(c)
+		return
+	}
+	err = h.bannerService.Delete(c.Request.Context(), int32(bannerID), version)
 	if err != nil {
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			h.l.Info("Banner %d version mismatch", bannerID)
+			h.abortPreconditionFailed(c)
+			return
+		}
 		if err.Error() == "no banner found" {
 			h.l.Info("No banner found with ID: %d", bannerID)
 			c.JSON(http.StatusNotFound, nil)
 			return
 		}
 		h.l.Error("Failed to delete banner: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
 		return
 	}
 	h.l.Info("Banner deleted successfully")
@@ -153,31 +245,105 @@ func (h *BannerController) updateBanner(c *gin.Context) {
 	bannerID, err := strconv.ParseInt(c.Param("id"), 10, 32)
 	if err != nil {
 		h.l.Error("Failed to parse banner ID: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные bannerID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidBannerID)})
+		return
+	}
+	bannerIDConverted := int32(bannerID)
+	if h.abortWithoutReview(c, &bannerIDConverted) {
+		h.l.Info("Direct update of banner %d rejected: review is required", bannerID)
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		h.abortPreconditionFailed(c)
+		return
+	}
+	switch c.ContentType() {
+	case mimeMergePatch, mimeJSONPatch:
+		h.patchBanner(c, int32(bannerID), version)
+		return
+	case "", binding.MIMEJSON:
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": h.localize(c, msgUnsupportedMediaType)})
 		return
 	}
 	var bannerUpdate entity.BannerUpdate
 	if err := c.ShouldBindJSON(&bannerUpdate); err != nil {
 		h.l.Error("Failed to bind banner JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при разборе данных баннера"})
+		h.abortWithValidation(c, msgInvalidBannerData, h.validationErrors(c, err))
 		return
 	}
-	bannerIDConverted := int32(bannerID)
 	bannerUpdate.ID = &bannerIDConverted
+	bannerUpdate.Version = version
 	err = h.bannerService.Update(c.Request.Context(), &bannerUpdate)
 	if err != nil {
+		if h.abortOnContentMismatch(c, err) {
+			h.l.Info("Banner content rejected by schema: %v", err)
+			return
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			h.l.Info("Banner %d version mismatch", bannerID)
+			h.abortPreconditionFailed(c)
+			return
+		}
 		if err.Error() == "no banner found" {
 			h.l.Info("No banner found with ID: %d", bannerID)
 			c.JSON(http.StatusNotFound, nil)
 			return
 		}
 		h.l.Error("Failed to update banner: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
 		return
 	}
 	h.l.Info("Banner updated successfully")
 	c.JSON(http.StatusNoContent, nil)
 }
+
+const (
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+// patchBanner применяет к баннеру JSON Merge Patch или JSON Patch. Патч адресует документ
+// {tag_ids, feature_id, content, is_active}, например /content/title
+func (h *BannerController) patchBanner(c *gin.Context, bannerID int32, version *int32) {
+	body, err := c.GetRawData()
+	if err != nil || !json.Valid(body) {
+		h.l.Error("Failed to read patch: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidBannerData)})
+		return
+	}
+	patch := &entity.BannerPatch{ID: bannerID, Type: entity.MergePatch, Patch: body, Version: version}
+	if c.ContentType() == mimeJSONPatch {
+		patch.Type = entity.JSONPatch
+	}
+	err = h.bannerService.Patch(c.Request.Context(), patch)
+	if err != nil {
+		var validationErrs validator.ValidationErrors
+		switch {
+		case h.abortOnContentMismatch(c, err):
+			h.l.Info("Banner content rejected by schema: %v", err)
+		case errors.Is(err, repository.ErrBannerNotFound):
+			h.l.Info("No banner found with ID: %d", bannerID)
+			c.JSON(http.StatusNotFound, nil)
+		case errors.Is(err, repository.ErrVersionMismatch):
+			h.l.Info("Banner %d version mismatch", bannerID)
+			h.abortPreconditionFailed(c)
+		case errors.Is(err, repository.ErrInvalidPatch):
+			h.l.Info("Failed to apply patch to banner %d: %v", bannerID, err)
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": h.localize(c, msgInvalidPatch), "details": err.Error()})
+		case errors.As(err, &validationErrs):
+			h.l.Info("Patched banner %d is invalid: %v", bannerID, err)
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": h.localize(c, msgInvalidPatch), "fields": h.validationErrors(c, err)})
+		default:
+			h.l.Error("Failed to patch banner: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		}
+		return
+	}
+	h.l.Info("Banner patched successfully")
+	c.JSON(http.StatusNoContent, nil)
+}
 func (h *BannerController) getBannersHistoryByID(c *gin.Context) {
 	token := c.GetBool("isAdmin")
 	if !token {
@@ -187,15 +353,106 @@ func (h *BannerController) getBannersHistoryByID(c *gin.Context) {
 	bannerID, err := strconv.ParseInt(c.Param("id"), 10, 32)
 	if err != nil {
 		h.l.Error("Failed to parse banner ID: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные bannerID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidBannerID)})
 		return
 	}
 	banners, err := h.bannerService.GetBannersHistoryByID(c.Request.Context(), int32(bannerID))
 	if err != nil {
 		h.l.Error("Failed to get banner history: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	// ETag текущей версии нужен, чтобы откатить баннер к версии из истории с If-Match
+	current, err := h.bannerService.Get(c.Request.Context(), int32(bannerID))
+	if err != nil && !errors.Is(err, repository.ErrBannerNotFound) {
+		h.l.Error("Failed to get banner: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
 		return
 	}
+	if current != nil {
+		c.Header("ETag", bannerETag(current.Version))
+	}
 	h.l.Info("Banner history retrieved successfully")
 	c.JSON(http.StatusOK, banners)
 }
+func (h *BannerController) getBannerByID(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	bannerID, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		h.l.Error("Failed to parse banner ID: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidBannerID)})
+		return
+	}
+	banner, err := h.bannerService.Get(c.Request.Context(), int32(bannerID))
+	if err != nil {
+		if errors.Is(err, repository.ErrBannerNotFound) {
+			h.l.Info("No banner found with ID: %d", bannerID)
+			c.JSON(http.StatusNotFound, nil)
+			return
+		}
+		h.l.Error("Failed to get banner: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	c.Header("ETag", bannerETag(banner.Version))
+	c.JSON(http.StatusOK, banner)
+}
+
+type rollbackRequest struct {
+	Version int32 `json:"version" binding:"required,gt=0"`
+}
+
+// rollbackBanner возвращает баннер к версии из истории, текущая версия может быть задана в If-Match
+func (h *BannerController) rollbackBanner(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	bannerID, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		h.l.Error("Failed to parse banner ID: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidBannerID)})
+		return
+	}
+	bannerIDConverted := int32(bannerID)
+	if h.abortWithoutReview(c, &bannerIDConverted) {
+		h.l.Info("Rollback of banner %d rejected: review is required", bannerID)
+		return
+	}
+	var request rollbackRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.l.Error("Failed to parse rollback request: %v", err)
+		h.abortWithValidation(c, msgInvalidData, h.validationErrors(c, err))
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		h.abortPreconditionFailed(c)
+		return
+	}
+	err = h.bannerService.Rollback(c.Request.Context(), int32(bannerID), request.Version, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrBannerNotFound):
+			h.l.Info("No banner found with ID: %d", bannerID)
+			c.JSON(http.StatusNotFound, nil)
+		case errors.Is(err, repository.ErrVersionNotFound):
+			h.l.Info("No version %d in history of banner %d", request.Version, bannerID)
+			c.JSON(http.StatusNotFound, gin.H{"error": h.localize(c, msgVersionNotFound)})
+		case errors.Is(err, repository.ErrVersionMismatch):
+			h.l.Info("Banner %d version mismatch", bannerID)
+			h.abortPreconditionFailed(c)
+		default:
+			h.l.Error("Failed to rollback banner: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		}
+		return
+	}
+	h.l.Info("Banner rolled back successfully")
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// bulkBanners выполняет пакет операций над баннерами и возвращает итог по каждой операции.
+// Отмененный атомарный пакет отвечает 409 с тем же итогом
+func (h *BannerController) bulkBanners(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	var request entity.BulkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.l.Error("Failed to parse bulk request: %v", err)
+		h.abortWithValidation(c, msgInvalidData, h.validationErrors(c, err))
+		return
+	}
+	if request.Mode == "" {
+		request.Mode = entity.BulkAtomic
+	}
+	if fields := h.duplicateIDs(c, &request); len(fields) > 0 {
+		h.l.Info("Bulk request rejected: banner changed twice in one atomic batch")
+		h.abortWithValidation(c, msgInvalidData, fields)
+		return
+	}
+	if changesBanners(&request) && h.abortWithoutReview(c, nil) {
+		h.l.Info("Bulk update rejected: review is required")
+		return
+	}
+	results, err := h.bannerService.Bulk(c.Request.Context(), &request)
+	if err != nil {
+		h.l.Error("Failed to apply bulk operations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		failed++
+		h.l.Info("Bulk operation %d (%s) failed: %v", result.Index, result.Action, result.Err)
+		result.Error = h.bulkErrorMessage(c, result.Err)
+		var validationErr *entity.ContentValidationError
+		if errors.As(result.Err, &validationErr) {
+			result.Violations = validationErr.Violations
+		}
+	}
+	// атомарный пакет с ошибкой отменен целиком, клиент не должен считать его выполненным
+	if request.Mode == entity.BulkAtomic && failed > 0 {
+		h.l.Info("Bulk operations rolled back: %d of %d failed", failed, len(results))
+		c.JSON(http.StatusConflict, gin.H{"error": h.localize(c, msgBulkRolledBack), "mode": request.Mode, "failed": failed, "results": results})
+		return
+	}
+	h.l.Info("Bulk operations applied: %d of %d failed", failed, len(results))
+	c.JSON(http.StatusOK, gin.H{"mode": request.Mode, "failed": failed, "results": results})
+}
+
+// duplicateIDs отмечает операции атомарного пакета над баннером, который уже меняет предыдущая операция.
+// Все операции атомарного пакета выполняются в одной транзакции, а история версий не различает два изменения
+// баннера в одной транзакции. Независимые операции best_effort этого ограничения не имеют
+func (h *BannerController) duplicateIDs(c *gin.Context, request *entity.BulkRequest) fieldErrors {
+	fields := fieldErrors{}
+	if request.Mode != entity.BulkAtomic {
+		return fields
+	}
+	seen := make(map[int32]struct{}, len(request.Operations))
+	for i, operation := range request.Operations {
+		if operation.ID == nil {
+			continue
+		}
+		if _, ok := seen[*operation.ID]; ok {
+			fields[fmt.Sprintf("operations[%d].id", i)] = h.localize(c, msgValidationDuplicateID)
+		}
+		seen[*operation.ID] = struct{}{}
+	}
+	return fields
+}
+
+// changesBanners сообщает, меняет ли пакет существующие баннеры: создание и удаление ревью не требуют
+func changesBanners(request *entity.BulkRequest) bool {
+	for _, operation := range request.Operations {
+		switch operation.Action {
+		case entity.BulkUpdate, entity.BulkActivate, entity.BulkDeactivate:
+			return true
+		}
+	}
+	return false
+}
+
+func (h *BannerController) bulkErrorMessage(c *gin.Context, err error) string {
+	var validationErr *entity.ContentValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return h.localize(c, msgContentMismatch)
+	case errors.Is(err, repository.ErrBannerNotFound):
+		return h.localize(c, msgBannerNotFound)
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return h.localize(c, msgVersionMismatch)
+	case err.Error() == "record with same featureId and tagId already exists":
+		return h.localize(c, msgBannerExists)
+	default:
+		return h.localize(c, msgInternalError)
+	}
+}
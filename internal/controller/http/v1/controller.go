@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"banner/pkg/i18n"
+	"banner/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// controller - общие для всех контроллеров зависимости: логгер и каталог сообщений
+type controller struct {
+	l        logger.Logger
+	messages *i18n.Catalog
+}
+
+// Registrar регистрирует маршруты дополнительного контроллера в группе с аутентификацией
+type Registrar interface {
+	Register(group *gin.RouterGroup)
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"banner/internal/repository"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// diffQuery - версии для сравнения, без to версия from сравнивается с текущей
+type diffQuery struct {
+	From *int32 `form:"from" binding:"required,gt=0"`
+	To   *int32 `form:"to" binding:"omitempty,gt=0"`
+}
+
+// getBannerDiff сравнивает две версии баннера из истории. С unified=true ответ дополнительно содержит
+// построчный diff версий
+func (h *BannerController) getBannerDiff(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	bannerID, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		h.l.Error("Failed to parse banner ID: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidBannerID)})
+		return
+	}
+	fields := fieldErrors{}
+	query := diffQuery{
+		From: h.queryInt32(c, "from", fields),
+		To:   h.queryInt32(c, "to", fields),
+	}
+	unified := h.queryBool(c, "unified", fields)
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			if _, ok := fields[field]; !ok {
+				fields[field] = msg
+			}
+		}
+	}
+	if len(fields) > 0 {
+		h.l.Error("Failed to parse diff query: %v", fields)
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	diff, err := h.bannerService.Diff(c.Request.Context(), int32(bannerID), *query.From, query.To, unified)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrBannerNotFound):
+			h.l.Info("No banner found with ID: %d", bannerID)
+			c.JSON(http.StatusNotFound, nil)
+		case errors.Is(err, repository.ErrVersionNotFound):
+			h.l.Info("Versions to compare are not in history of banner %d", bannerID)
+			c.JSON(http.StatusNotFound, gin.H{"error": h.localize(c, msgVersionNotFound)})
+		default:
+			h.l.Error("Failed to diff banner versions: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
@@ -0,0 +1,339 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/internal/service"
+	"banner/pkg/i18n"
+	"banner/pkg/logger"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+type DraftController struct {
+	controller
+	drafts service.DraftWorkflow
+}
+
+func NewDraftController(drafts service.DraftWorkflow, logger logger.Logger, messages *i18n.Catalog) *DraftController {
+	return &DraftController{
+		controller: controller{l: logger, messages: messages},
+		drafts:     drafts,
+	}
+}
+
+func (h *DraftController) Register(group *gin.RouterGroup) {
+	group.POST("/banner/:id/drafts", h.createDraft)
+	group.GET("/drafts", h.getDrafts)
+	group.GET("/drafts/:id", h.getDraft)
+	group.PATCH("/drafts/:id", h.updateDraft)
+	group.DELETE("/drafts/:id", h.discardDraft)
+	group.GET("/drafts/:id/diff", h.getDraftDiff)
+	group.POST("/drafts/:id/submit", h.submitDraft)
+	group.POST("/drafts/:id/approve", h.approveDraft)
+	group.POST("/drafts/:id/reject", h.rejectDraft)
+}
+
+// reviewRequest - решение ревьюера, при отклонении комментарий обязателен
+type reviewRequest struct {
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+// createDraft делает черновик от текущей версии баннера и отвечает 201. Тело - те же поля, что и у
+// PATCH /banner/:id; баннер не меняется, пока черновик не одобрят
+func (h *DraftController) createDraft(c *gin.Context) {
+	if !h.authorize(c) {
+		return
+	}
+	bannerID, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || bannerID <= 0 {
+		h.l.Error("Failed to parse banner ID: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidBannerID)})
+		return
+	}
+	var changes entity.BannerUpdate
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		h.l.Error("Failed to parse draft: %v", err)
+		h.abortWithValidation(c, msgInvalidBannerData, h.validationErrors(c, err))
+		return
+	}
+	draft, err := h.drafts.CreateDraft(c.Request.Context(), int32(bannerID), &changes)
+	if h.abortOnDraftError(c, err) {
+		return
+	}
+	h.l.Info("Draft %d of banner %d created", draft.ID, bannerID)
+	c.Header("Location", fmt.Sprintf("/drafts/%d", draft.ID))
+	c.JSON(http.StatusCreated, draft)
+}
+
+// draftsQuery - фильтры очереди черновиков, limit=0 означает отсутствие лимита
+type draftsQuery struct {
+	BannerID *int32             `form:"banner_id" binding:"omitempty,gt=0"`
+	Status   entity.DraftStatus `form:"status" binding:"omitempty,oneof=draft pending approved rejected discarded"`
+	Limit    *int32             `form:"limit" binding:"omitempty,gte=0"`
+	Offset   *int32             `form:"offset" binding:"omitempty,gte=0"`
+}
+
+// getDrafts отдает черновики, новые первыми; status=pending - очередь на ревью
+func (h *DraftController) getDrafts(c *gin.Context) {
+	if !h.authorize(c) {
+		return
+	}
+	fields := fieldErrors{}
+	query := draftsQuery{
+		BannerID: h.queryInt32(c, "banner_id", fields),
+		Status:   entity.DraftStatus(c.Query("status")),
+		Limit:    h.queryInt32(c, "limit", fields),
+		Offset:   h.queryInt32(c, "offset", fields),
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	if len(fields) > 0 {
+		h.l.Error("Failed to parse drafts query: %v", fields)
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	drafts := &entity.DraftsQuery{BannerID: query.BannerID, Limit: query.Limit}
+	if query.Status != "" {
+		drafts.Status = &query.Status
+	}
+	if query.Offset != nil {
+		drafts.Offset = *query.Offset
+	}
+	if drafts.Limit != nil && *drafts.Limit == 0 {
+		drafts.Limit = nil
+	}
+	result, err := h.drafts.Drafts(c.Request.Context(), drafts)
+	if h.abortOnDraftError(c, err) {
+		return
+	}
+	if result == nil {
+		result = []*entity.BannerDraft{}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *DraftController) getDraft(c *gin.Context) {
+	id, ok := h.draftID(c)
+	if !ok {
+		return
+	}
+	draft, err := h.drafts.GetDraft(c.Request.Context(), id)
+	if h.abortOnDraftError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+// updateDraft меняет черновик; менять его может только автор, пока черновик не отправлен на ревью
+func (h *DraftController) updateDraft(c *gin.Context) {
+	id, ok := h.draftID(c)
+	if !ok {
+		return
+	}
+	var changes entity.BannerUpdate
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		h.l.Error("Failed to parse draft: %v", err)
+		h.abortWithValidation(c, msgInvalidBannerData, h.validationErrors(c, err))
+		return
+	}
+	draft, err := h.drafts.UpdateDraft(c.Request.Context(), id, &changes)
+	if h.abortOnDraftError(c, err) {
+		return
+	}
+	h.l.Info("Draft %d updated", id)
+	c.JSON(http.StatusOK, draft)
+}
+
+// discardDraft отменяет неопубликованный черновик автора, запись о нем остается
+func (h *DraftController) discardDraft(c *gin.Context) {
+	id, ok := h.draftID(c)
+	if !ok {
+		return
+	}
+	_, err := h.drafts.DiscardDraft(c.Request.Context(), id)
+	if h.abortOnDraftError(c, err) {
+		return
+	}
+	h.l.Info("Draft %d discarded", id)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// getDraftDiff сравнивает опубликованную версию баннера с черновиком, как GET /banner/:id/diff
+func (h *DraftController) getDraftDiff(c *gin.Context) {
+	id, ok := h.draftID(c)
+	if !ok {
+		return
+	}
+	fields := fieldErrors{}
+	unified := h.queryBool(c, "unified", fields)
+	if len(fields) > 0 {
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	diff, err := h.drafts.DraftDiff(c.Request.Context(), id, unified)
+	if h.abortOnDraftError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *DraftController) submitDraft(c *gin.Context) {
+	id, ok := h.draftID(c)
+	if !ok {
+		return
+	}
+	draft, err := h.drafts.SubmitDraft(c.Request.Context(), id)
+	if h.abortOnDraftError(c, err) {
+		return
+	}
+	h.l.Info("Draft %d submitted for review", id)
+	c.JSON(http.StatusOK, draft)
+}
+
+// approveDraft одобряет черновик и публикует его; автор черновика одобрить его не может
+func (h *DraftController) approveDraft(c *gin.Context) {
+	id, ok := h.draftID(c)
+	if !ok {
+		return
+	}
+	request, ok := h.bindReview(c, false)
+	if !ok {
+		return
+	}
+	draft, err := h.drafts.ApproveDraft(c.Request.Context(), id, request.Comment)
+	if h.abortOnDraftError(c, err) {
+		return
+	}
+	h.l.Info("Draft %d approved and published as version %d", id, *draft.PublishedVersion)
+	c.JSON(http.StatusOK, draft)
+}
+
+// rejectDraft возвращает черновик автору, комментарий объясняет, что исправить
+func (h *DraftController) rejectDraft(c *gin.Context) {
+	id, ok := h.draftID(c)
+	if !ok {
+		return
+	}
+	request, ok := h.bindReview(c, true)
+	if !ok {
+		return
+	}
+	draft, err := h.drafts.RejectDraft(c.Request.Context(), id, request.Comment)
+	if h.abortOnDraftError(c, err) {
+		return
+	}
+	h.l.Info("Draft %d rejected", id)
+	c.JSON(http.StatusOK, draft)
+}
+
+// bindReview разбирает необязательное тело с комментарием ревьюера
+func (h *DraftController) bindReview(c *gin.Context, commentRequired bool) (*reviewRequest, bool) {
+	var request reviewRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			h.l.Error("Failed to parse review: %v", err)
+			h.abortWithValidation(c, msgInvalidReview, h.validationErrors(c, err))
+			return nil, false
+		}
+	}
+	if commentRequired && request.Comment == "" {
+		h.abortWithValidation(c, msgInvalidReview, fieldErrors{"comment": h.localize(c, msgValidationRequired)})
+		return nil, false
+	}
+	return &request, true
+}
+
+// authorize пускает к черновикам администратора с подписанным токеном, в котором задан субъект.
+// Статические токены и токены без субъекта не различают авторов, и правило ревью для них не проверить
+func (h *DraftController) authorize(c *gin.Context) bool {
+	if !c.GetBool("isAdmin") {
+		c.JSON(http.StatusForbidden, nil)
+		return false
+	}
+	if c.GetString("subject") == "" {
+		h.l.Info("Draft request without token subject rejected")
+		c.JSON(http.StatusForbidden, gin.H{"error": h.localize(c, msgUnknownActor)})
+		return false
+	}
+	return true
+}
+
+// draftID проверяет права на черновики и разбирает идентификатор черновика из пути
+func (h *DraftController) draftID(c *gin.Context) (int64, bool) {
+	if !h.authorize(c) {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		h.l.Error("Failed to parse draft ID: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidDraftID)})
+		return 0, false
+	}
+	return id, true
+}
+
+// abortOnDraftError переводит ошибки черновиков в ответ: 404 - нет черновика или баннера, 403 - нарушено
+// правило ревью, 409 - действие недоступно на текущем этапе или баннер изменился
+func (h *DraftController) abortOnDraftError(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	var validationErrs validator.ValidationErrors
+	switch {
+	case h.abortOnContentMismatch(c, err):
+		h.l.Info("Draft content rejected by schema: %v", err)
+	case errors.As(err, &validationErrs):
+		h.l.Info("Draft is invalid: %v", err)
+		h.abortWithValidation(c, msgInvalidBannerData, h.validationErrors(c, err))
+	case errors.Is(err, repository.ErrDraftNotFound), errors.Is(err, repository.ErrBannerNotFound):
+		c.JSON(http.StatusNotFound, nil)
+	case errors.Is(err, service.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{"error": h.localize(c, msgSelfApproval)})
+	case errors.Is(err, service.ErrNotDraftAuthor):
+		c.JSON(http.StatusForbidden, gin.H{"error": h.localize(c, msgNotDraftAuthor)})
+	case errors.Is(err, service.ErrUnknownActor):
+		c.JSON(http.StatusForbidden, gin.H{"error": h.localize(c, msgUnknownActor)})
+	case errors.Is(err, repository.ErrDraftExists):
+		c.JSON(http.StatusConflict, gin.H{"error": h.localize(c, msgDraftExists)})
+	case errors.Is(err, service.ErrDraftState):
+		c.JSON(http.StatusConflict, gin.H{"error": h.localize(c, msgDraftState)})
+	case errors.Is(err, service.ErrDraftOutdated):
+		c.JSON(http.StatusConflict, gin.H{"error": h.localize(c, msgDraftOutdated)})
+	default:
+		h.l.Error("Draft request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+	}
+	return true
+}
+
+// WithRequireReview включает обязательное ревью: опубликованный баннер меняется только одобрением черновика,
+// прямые PATCH, откат, восстановление из корзины, обновления в пакете и загрузка с перезаписью отклоняются.
+// Создание и удаление баннеров ревью не требуют
+func (h *BannerController) WithRequireReview(require bool) *BannerController {
+	h.requireReview = require
+	return h
+}
+
+// abortWithoutReview отвечает 409 со ссылкой на черновики баннера, если изменения требуют ревью.
+// bannerID nil - запрос меняет несколько баннеров
+func (h *BannerController) abortWithoutReview(c *gin.Context, bannerID *int32) bool {
+	if !h.requireReview {
+		return false
+	}
+	response := gin.H{"error": h.localize(c, msgReviewRequired)}
+	if bannerID != nil {
+		response["drafts"] = fmt.Sprintf("/banner/%d/drafts", *bannerID)
+	}
+	c.JSON(http.StatusConflict, response)
+	return true
+}
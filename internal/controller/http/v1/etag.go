@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bannerETag - сильный ETag версии баннера
+func bannerETag(version int32) string {
+	return strconv.Quote(strconv.Itoa(int(version)))
+}
+
+// listETag - слабый ETag списка баннеров, меняется при изменении любого из них
+func listETag(banners []*entity.FilteredBanner) string {
+	hash := sha256.New()
+	for _, banner := range banners {
+		_, _ = fmt.Fprintf(hash, "%d:%d;", banner.ID, banner.Version)
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil))[:16] + `"`
+}
+
+// ifMatch разбирает If-Match в ожидаемую версию баннера. Без заголовка или с "*" проверка не нужна (nil, true).
+// Слабые, некорректные и множественные ETag не могут совпасть с версией, для них возвращается false
+func ifMatch(c *gin.Context) (*int32, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return nil, false
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 32)
+	if err != nil {
+		return nil, false
+	}
+	converted := int32(version)
+	return &converted, true
+}
+
+// ifNoneMatch проверяет, есть ли etag среди ETag из If-None-Match. Сравнение слабое, как требует RFC 9110
+func ifNoneMatch(c *gin.Context, etag string) bool {
+	header := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	if header == "" || etag == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheControl - Cache-Control для баннера пользователя: сколько секунд ему осталось жить в кэше сервиса
+func cacheControl(maxAge time.Duration) string {
+	if maxAge < time.Second {
+		return "private, no-cache"
+	}
+	return "private, max-age=" + strconv.Itoa(int(maxAge/time.Second))
+}
+
+// abortPreconditionFailed отвечает 412, если версия баннера не совпала с If-Match
+func (h *controller) abortPreconditionFailed(c *gin.Context) {
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": h.localize(c, msgVersionMismatch)})
+}
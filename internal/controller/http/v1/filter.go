@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bannerFilterQuery - ограничения на фильтры списка баннеров, общие для GET /banner и выгрузки
+type bannerFilterQuery struct {
+	FeatureID   *int32          `form:"feature_id" binding:"omitempty,gt=0"`
+	TagID       *int32          `form:"tag_id" binding:"omitempty,gt=0"`
+	FeatureIDs  []int32         `form:"feature_ids" binding:"max=100,dive,gt=0"`
+	TagIDs      []int32         `form:"tag_ids" binding:"max=100,dive,gt=0"`
+	TagMatch    entity.TagMatch `form:"tag_match" binding:"omitempty,oneof=any all"`
+	Search      string          `form:"q" binding:"max=200"`
+	ContentPath string          `form:"content_path" binding:"max=1000"`
+}
+
+// parseBannerFilter разбирает фильтры списка баннеров. Списки id принимаются через запятую
+// или повтором параметра, даты - в RFC 3339 или в виде 2006-01-02
+func (h *controller) parseBannerFilter(c *gin.Context, fields fieldErrors) *entity.BannerFilter {
+	query := &bannerFilterQuery{
+		FeatureID:   h.queryInt32(c, "feature_id", fields),
+		TagID:       h.queryInt32(c, "tag_id", fields),
+		FeatureIDs:  h.queryInt32List(c, "feature_ids", fields),
+		TagIDs:      h.queryInt32List(c, "tag_ids", fields),
+		TagMatch:    entity.TagMatch(c.DefaultQuery("tag_match", string(entity.TagMatchAny))),
+		Search:      strings.TrimSpace(c.Query("q")),
+		ContentPath: strings.TrimSpace(c.Query("content_path")),
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	filter := &entity.BannerFilter{
+		FeatureID:   query.FeatureID,
+		TagID:       query.TagID,
+		FeatureIDs:  query.FeatureIDs,
+		TagIDs:      query.TagIDs,
+		TagMatch:    query.TagMatch,
+		IsActive:    h.queryOptionalBool(c, "is_active", fields),
+		CreatedFrom: h.queryTime(c, "created_from", fields),
+		CreatedTo:   h.queryTime(c, "created_to", fields),
+		UpdatedFrom: h.queryTime(c, "updated_from", fields),
+		UpdatedTo:   h.queryTime(c, "updated_to", fields),
+		Search:      query.Search,
+		ContentPath: query.ContentPath,
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		fields["created_to"] = h.localize(c, msgValidationDateRange)
+	}
+	if filter.UpdatedFrom != nil && filter.UpdatedTo != nil && !filter.UpdatedFrom.Before(*filter.UpdatedTo) {
+		fields["updated_to"] = h.localize(c, msgValidationDateRange)
+	}
+	return filter
+}
+
+// queryInt32List разбирает список целых чисел через запятую или повтором параметра
+func (h *controller) queryInt32List(c *gin.Context, name string, fields fieldErrors) []int32 {
+	var values []int32
+	for _, raw := range c.QueryArray(name) {
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			value, err := strconv.ParseInt(part, 10, 32)
+			if err != nil {
+				fields[name] = h.localize(c, msgValidationInteger)
+				return nil
+			}
+			values = append(values, int32(value))
+		}
+	}
+	return values
+}
+
+// queryOptionalBool разбирает логический query-параметр, nil - параметр не передан
+func (h *controller) queryOptionalBool(c *gin.Context, name string, fields fieldErrors) *bool {
+	if _, ok := c.GetQuery(name); !ok {
+		return nil
+	}
+	value := h.queryBool(c, name, fields)
+	return &value
+}
+
+// queryTime разбирает дату или время; дата без времени означает начало суток в UTC
+func (h *controller) queryTime(c *gin.Context, name string, fields fieldErrors) *time.Time {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if value, err := time.Parse(layout, raw); err == nil {
+			return &value
+		}
+	}
+	fields[name] = h.localize(c, msgValidationDate)
+	return nil
+}
@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"banner/internal/service"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// WithIdempotency включает поддержку Idempotency-Key для создания баннеров
+func (h *BannerController) WithIdempotency(store service.IdempotencyStore) *BannerController {
+	h.idempotency = store
+	return h
+}
+
+// idempotent повторно отдает сохраненный ответ на запрос с уже использованным Idempotency-Key.
+// Ключи действуют в пределах автора запроса. Ответы с кодом 5xx не сохраняются, ключ освобождается,
+// чтобы запрос можно было повторить
+func (h *BannerController) idempotent(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if h.idempotency == nil || key == "" || !c.GetBool("isAdmin") {
+		c.Next()
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidIdempotencyKey)})
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.l.Error("Failed to read request body: %v", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidData)})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	response, err := h.idempotency.Begin(c.Request.Context(), key, requestFingerprint(c.Request, body))
+	switch {
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": h.localize(c, msgIdempotencyKeyReused)})
+		return
+	case errors.Is(err, service.ErrIdempotencyKeyInProgress):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": h.localize(c, msgIdempotencyKeyInProgress)})
+		return
+	case err != nil:
+		h.l.Error("Failed to reserve idempotency key: %v", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	case response != nil:
+		h.l.Info("Replaying response for idempotency key %s", key)
+		c.Header(idempotencyReplayedHeader, "true")
+		c.Data(response.StatusCode, response.ContentType, response.Body)
+		c.Abort()
+		return
+	}
+
+	// запрос уже выполнен, поэтому ключ сохраняем даже если клиент успел отключиться
+	ctx := context.WithoutCancel(c.Request.Context())
+	// ключ освобождается, если обработчик упал с паникой или ответил 5xx, иначе он остался бы занятым
+	// до конца окна хранения
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if err := h.idempotency.Abort(ctx, key); err != nil {
+			h.l.Error("Failed to release idempotency key: %v", err)
+		}
+	}()
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	if recorder.Status() >= http.StatusInternalServerError {
+		return
+	}
+	// запрос выполнен: если ответ не удастся сохранить, ключ все равно не освобождается, чтобы повтор
+	// не создал баннер второй раз
+	completed = true
+	err = h.idempotency.Complete(ctx, key, &entity.IdempotentResponse{
+		StatusCode:  recorder.Status(),
+		ContentType: recorder.Header().Get("Content-Type"),
+		Body:        recorder.body.Bytes(),
+	})
+	if err != nil {
+		h.l.Error("Failed to store idempotent response: %v", err)
+	}
+}
+
+// requestFingerprint - хэш метода, пути и тела запроса. JSON приводится к каноническому виду,
+// чтобы ретрай с другим форматированием или порядком ключей считался тем же запросом
+func requestFingerprint(r *http.Request, body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err == nil {
+		if canonical, err := json.Marshal(value); err == nil {
+			body = canonical
+		}
+	}
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder дублирует тело ответа, чтобы сохранить его для повторов
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,153 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/internal/service"
+	"banner/pkg/i18n"
+	"banner/pkg/logger"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type JobController struct {
+	controller
+	jobs service.JobQueue
+}
+
+func NewJobController(jobs service.JobQueue, logger logger.Logger, messages *i18n.Catalog) *JobController {
+	return &JobController{
+		controller: controller{l: logger, messages: messages},
+		jobs:       jobs,
+	}
+}
+
+func (h *JobController) Register(group *gin.RouterGroup) {
+	group.DELETE("/banner", h.deleteBanners)
+	group.GET("/jobs", h.getJobs)
+	group.GET("/jobs/:id", h.getJob)
+}
+
+// deleteBannersQuery - фильтр массового удаления, без фильтра удалились бы все баннеры
+type deleteBannersQuery struct {
+	FeatureID *int32 `form:"feature_id" binding:"required_without=TagID,omitempty,gt=0"`
+	TagID     *int32 `form:"tag_id" binding:"required_without=FeatureID,omitempty,gt=0"`
+}
+
+// deleteBanners ставит в очередь удаление баннеров по фиче и/или тегу и отвечает 202 с задачей
+func (h *JobController) deleteBanners(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	fields := fieldErrors{}
+	query := deleteBannersQuery{
+		FeatureID: h.queryInt32(c, "feature_id", fields),
+		TagID:     h.queryInt32(c, "tag_id", fields),
+	}
+	if len(fields) == 0 {
+		if err := binding.Validator.ValidateStruct(query); err != nil {
+			fields = h.validationErrors(c, err)
+		}
+	}
+	if len(fields) > 0 {
+		h.l.Error("Failed to parse delete query: %v", fields)
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	job, err := h.jobs.EnqueueDeleteBanners(c.Request.Context(), &entity.DeleteBannersParams{FeatureID: query.FeatureID, TagID: query.TagID})
+	if err != nil {
+		h.l.Error("Failed to enqueue banners deletion: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	h.l.Info("Banners deletion job %d enqueued", job.ID)
+	c.Header("Location", fmt.Sprintf("/jobs/%d", job.ID))
+	c.JSON(http.StatusAccepted, job)
+}
+
+// jobsQuery - фильтры и пагинация списка задач, limit=0 означает отсутствие лимита
+type jobsQuery struct {
+	Status entity.JobStatus `form:"status" binding:"omitempty,oneof=queued running done failed"`
+	Kind   entity.JobKind   `form:"kind"`
+	Limit  *int32           `form:"limit" binding:"omitempty,gte=0"`
+	Offset *int32           `form:"offset" binding:"omitempty,gte=0"`
+}
+
+// getJobs отдает задачи очереди, новые первыми
+func (h *JobController) getJobs(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	fields := fieldErrors{}
+	query := jobsQuery{
+		Status: entity.JobStatus(c.Query("status")),
+		Kind:   entity.JobKind(c.Query("kind")),
+		Limit:  h.queryInt32(c, "limit", fields),
+		Offset: h.queryInt32(c, "offset", fields),
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	if len(fields) > 0 {
+		h.l.Error("Failed to parse jobs query: %v", fields)
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	jobs := &entity.JobsQuery{Limit: query.Limit}
+	if query.Status != "" {
+		jobs.Status = &query.Status
+	}
+	if query.Kind != "" {
+		jobs.Kind = &query.Kind
+	}
+	if query.Offset != nil {
+		jobs.Offset = *query.Offset
+	}
+	if jobs.Limit != nil && *jobs.Limit == 0 {
+		jobs.Limit = nil
+	}
+	result, err := h.jobs.List(c.Request.Context(), jobs)
+	if err != nil {
+		h.l.Error("Failed to get jobs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *JobController) getJob(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.l.Error("Failed to parse job ID: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidJobID)})
+		return
+	}
+	job, err := h.jobs.Get(c.Request.Context(), jobID)
+	if errors.Is(err, repository.ErrJobNotFound) {
+		h.l.Info("No job found with ID: %d", jobID)
+		c.JSON(http.StatusNotFound, nil)
+		return
+	}
+	if err != nil {
+		h.l.Error("Failed to get job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
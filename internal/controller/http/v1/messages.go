@@ -0,0 +1,194 @@
+package v1
+
+import (
+	"banner/pkg/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// ключи сообщений об ошибках, отдаваемых клиенту
+const (
+	msgInvalidData       = "invalid_data"
+	msgBannerExists      = "banner_exists"
+	msgInternalError     = "internal_error"
+	msgInvalidTagID      = "invalid_tag_id"
+	msgInvalidFeatureID  = "invalid_feature_id"
+	msgInvalidBannerID   = "invalid_banner_id"
+	msgInvalidJobID      = "invalid_job_id"
+	msgInvalidWebhookID  = "invalid_webhook_id"
+	msgInvalidDelivery   = "invalid_delivery_id"
+	msgInvalidWebhook    = "invalid_webhook"
+	msgDeliveryPending   = "delivery_pending"
+	msgInvalidDraftID    = "invalid_draft_id"
+	msgInvalidReview     = "invalid_review"
+	msgInvalidBannerData = "invalid_banner_data"
+	msgInvalidQuery      = "invalid_query"
+	msgInvalidSchema     = "invalid_schema"
+	msgContentMismatch   = "content_schema_mismatch"
+	msgInvalidPatch      = "invalid_patch"
+
+	msgUnsupportedMediaType = "unsupported_media_type"
+	msgVersionMismatch      = "version_mismatch"
+	msgVersionNotFound      = "version_not_found"
+
+	msgInvalidIdempotencyKey    = "invalid_idempotency_key"
+	msgIdempotencyKeyReused     = "idempotency_key_reused"
+	msgIdempotencyKeyInProgress = "idempotency_key_in_progress"
+
+	msgBannerNotFound  = "banner_not_found"
+	msgImportConflict  = "import_conflict"
+	msgImportTooLarge  = "import_too_large"
+	msgRestoreConflict = "restore_conflict"
+	msgBulkRolledBack  = "bulk_rolled_back"
+
+	msgDraftExists    = "draft_exists"
+	msgDraftState     = "draft_state"
+	msgDraftOutdated  = "draft_outdated"
+	msgSelfApproval   = "self_approval"
+	msgNotDraftAuthor = "not_draft_author"
+	msgUnknownActor   = "unknown_actor"
+	msgReviewRequired = "review_required"
+
+	msgValidationRequired    = "validation_required"
+	msgValidationGt          = "validation_gt"
+	msgValidationGte         = "validation_gte"
+	msgValidationLte         = "validation_lte"
+	msgValidationMin         = "validation_min"
+	msgValidationMax         = "validation_max"
+	msgValidationUnique      = "validation_unique"
+	msgValidationContentSize = "validation_content_size"
+	msgValidationInteger     = "validation_integer"
+	msgValidationInvalid     = "validation_invalid"
+	msgValidationOneOf       = "validation_oneof"
+	msgValidationDate        = "validation_date"
+	msgValidationDateRange   = "validation_date_range"
+	msgValidationDuplicateID = "validation_duplicate_id"
+)
+
+var messages = map[string]i18n.Messages{
+	"ru": {
+		msgInvalidData:       "Некорректные данные",
+		msgBannerExists:      "Баннер с таким featureId и tagId уже существует",
+		msgInternalError:     "Внутренняя ошибка сервера",
+		msgInvalidTagID:      "Некорректные данные tagId",
+		msgInvalidFeatureID:  "Некорректные данные featureId",
+		msgInvalidBannerID:   "Некорректные данные bannerID",
+		msgInvalidJobID:      "Некорректный идентификатор задачи",
+		msgInvalidWebhookID:  "Некорректный идентификатор подписки",
+		msgInvalidDelivery:   "Некорректный идентификатор доставки",
+		msgInvalidWebhook:    "Некорректные данные подписки",
+		msgDeliveryPending:   "Доставка еще не завершена",
+		msgInvalidDraftID:    "Некорректный идентификатор черновика",
+		msgInvalidReview:     "Некорректные данные ревью",
+		msgInvalidBannerData: "Ошибка при разборе данных баннера",
+		msgInvalidQuery:      "Некорректные параметры запроса",
+		msgInvalidSchema:     "Некорректная JSON Schema",
+		msgContentMismatch:   "Содержимое баннера не соответствует схеме фичи",
+		msgInvalidPatch:      "Патч не может быть применен к баннеру",
+
+		msgUnsupportedMediaType: "Неподдерживаемый Content-Type",
+		msgVersionMismatch:      "Баннер был изменен, версия не совпадает с If-Match",
+		msgVersionNotFound:      "Версия баннера не найдена в истории",
+
+		msgInvalidIdempotencyKey:    "Некорректный Idempotency-Key",
+		msgIdempotencyKeyReused:     "Idempotency-Key уже использован с другим запросом",
+		msgIdempotencyKeyInProgress: "Запрос с этим Idempotency-Key еще выполняется",
+
+		msgBannerNotFound:  "Баннер не найден",
+		msgImportConflict:  "Загружаемый баннер конфликтует с существующим, загрузка отменена",
+		msgImportTooLarge:  "Файл загрузки слишком большой",
+		msgRestoreConflict: "Фича и теги баннера уже заняты другим баннером",
+		msgBulkRolledBack:  "Операция пакета не выполнена, пакет отменен целиком",
+
+		msgDraftExists:    "У баннера уже есть открытый черновик",
+		msgDraftState:     "Действие недоступно на текущем этапе ревью черновика",
+		msgDraftOutdated:  "Баннер изменился после создания черновика, создайте черновик заново",
+		msgSelfApproval:   "Автор не может одобрить свой черновик",
+		msgNotDraftAuthor: "Менять, отправлять на ревью и отменять черновик может только автор",
+		msgUnknownActor:   "Для работы с черновиками нужен токен с известным субъектом",
+		msgReviewRequired: "Баннер меняется только через черновик и ревью: POST /banner/:id/drafts",
+
+		msgValidationRequired:    "Обязательное поле",
+		msgValidationGt:          "Значение должно быть больше %s",
+		msgValidationGte:         "Значение должно быть не меньше %s",
+		msgValidationLte:         "Значение должно быть не больше %s",
+		msgValidationMin:         "Должно содержать не менее %s элементов",
+		msgValidationMax:         "Должно содержать не более %s элементов",
+		msgValidationUnique:      "Значения должны быть уникальными",
+		msgValidationContentSize: "Размер не должен превышать %s байт",
+		msgValidationInteger:     "Значение должно быть целым числом",
+		msgValidationInvalid:     "Некорректное значение",
+		msgValidationOneOf:       "Допустимые значения: %s",
+		msgValidationDate:        "Ожидается дата в формате RFC 3339 или ГГГГ-ММ-ДД",
+		msgValidationDateRange:   "Конец периода должен быть позже начала",
+		msgValidationDuplicateID: "Баннер уже изменяет другая операция",
+	},
+	"en": {
+		msgInvalidData:       "Invalid data",
+		msgBannerExists:      "Banner with the same featureId and tagId already exists",
+		msgInternalError:     "Internal server error",
+		msgInvalidTagID:      "Invalid tagId",
+		msgInvalidFeatureID:  "Invalid featureId",
+		msgInvalidBannerID:   "Invalid bannerID",
+		msgInvalidJobID:      "Invalid job ID",
+		msgInvalidWebhookID:  "Invalid webhook ID",
+		msgInvalidDelivery:   "Invalid delivery ID",
+		msgInvalidWebhook:    "Invalid webhook data",
+		msgDeliveryPending:   "Delivery is still pending",
+		msgInvalidDraftID:    "Invalid draft ID",
+		msgInvalidReview:     "Invalid review data",
+		msgInvalidBannerData: "Failed to parse banner data",
+		msgInvalidQuery:      "Invalid query parameters",
+		msgInvalidSchema:     "Invalid JSON Schema",
+		msgContentMismatch:   "Banner content does not match the feature schema",
+		msgInvalidPatch:      "Patch cannot be applied to the banner",
+
+		msgUnsupportedMediaType: "Unsupported Content-Type",
+		msgVersionMismatch:      "Banner has been modified, version does not match If-Match",
+		msgVersionNotFound:      "Banner version not found in history",
+
+		msgInvalidIdempotencyKey:    "Invalid Idempotency-Key",
+		msgIdempotencyKeyReused:     "Idempotency-Key has already been used with a different request",
+		msgIdempotencyKeyInProgress: "Request with this Idempotency-Key is still in progress",
+
+		msgBannerNotFound:  "Banner not found",
+		msgImportConflict:  "Imported banner conflicts with an existing one, import aborted",
+		msgImportTooLarge:  "Import file is too large",
+		msgRestoreConflict: "Another banner already uses this feature and tags",
+		msgBulkRolledBack:  "An operation in the batch failed, the whole batch was rolled back",
+
+		msgDraftExists:    "Banner already has an open draft",
+		msgDraftState:     "Action is not allowed at the current review stage of the draft",
+		msgDraftOutdated:  "Banner has changed since the draft was created, create a new draft",
+		msgSelfApproval:   "Author cannot approve their own draft",
+		msgNotDraftAuthor: "Only the author can change, submit or discard the draft",
+		msgUnknownActor:   "Drafts require a token with a known subject",
+		msgReviewRequired: "Banner changes require review, create a draft with POST /banner/:id/drafts",
+
+		msgValidationRequired:    "Field is required",
+		msgValidationGt:          "Value must be greater than %s",
+		msgValidationGte:         "Value must be greater than or equal to %s",
+		msgValidationLte:         "Value must be less than or equal to %s",
+		msgValidationMin:         "Must contain at least %s items",
+		msgValidationMax:         "Must contain at most %s items",
+		msgValidationUnique:      "Values must be unique",
+		msgValidationContentSize: "Size must not exceed %s bytes",
+		msgValidationInteger:     "Value must be an integer",
+		msgValidationInvalid:     "Invalid value",
+		msgValidationOneOf:       "Allowed values: %s",
+		msgValidationDate:        "Expected a date in RFC 3339 or YYYY-MM-DD format",
+		msgValidationDateRange:   "End of the period must be after its start",
+		msgValidationDuplicateID: "Banner is already changed by another operation",
+	},
+}
+
+// NewCatalog создает каталог сообщений API с языком по умолчанию defaultLang
+func NewCatalog(defaultLang string) (*i18n.Catalog, error) {
+	return i18n.New(defaultLang, messages)
+}
+
+// localize переводит сообщение по ключу на язык из заголовка Accept-Language
+func (h *controller) localize(c *gin.Context, key string) string {
+	lang := h.messages.Negotiate(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", lang.String())
+	return h.messages.Translate(lang, key)
+}
@@ -1,6 +1,10 @@
 package v1
 
 import (
+	"banner/internal/entity"
+	"banner/pkg/token"
+	"crypto/rand"
+	"encoding/hex"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
@@ -8,19 +12,82 @@ import (
 const (
 	adminToken = "admin_token"
 	userToken  = "user_token"
+	// requestIDHeader - идентификатор запроса: клиент может передать свой, иначе сервер создает его сам
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLength - более длинный идентификатор клиента заменяется новым
+	maxRequestIDLength = 128
 )
 
-func authenticate(context *gin.Context) {
-	token := context.Request.Header.Get("token")
-	// must be some auth verify
-	switch token {
+// WithTokenSecret включает проверку токенов, выпущенных командой token issue, наряду со статическими
+func (h *BannerController) WithTokenSecret(secret string) *BannerController {
+	h.tokenSecret = []byte(secret)
+	return h
+}
+
+// authenticate проверяет токен и запоминает роль и автора изменений: роль статического токена
+// или субъект подписанного, если он задан. Субъект подписанного токена запоминается и отдельно:
+// черновики принимают только его, роль не отличает одного администратора от другого
+func (h *BannerController) authenticate(context *gin.Context) {
+	header := context.Request.Header.Get("token")
+	var actor string
+	switch header {
 	case adminToken:
 		context.Set("isAdmin", true)
+		actor = string(token.RoleAdmin)
 	case userToken:
 		context.Set("isAdmin", false)
+		actor = string(token.RoleUser)
 	default:
-		context.AbortWithStatus(http.StatusUnauthorized)
-		return
+		if len(h.tokenSecret) == 0 {
+			context.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		claims, err := token.Verify(h.tokenSecret, header)
+		if err != nil {
+			context.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		context.Set("isAdmin", claims.Role == token.RoleAdmin)
+		context.Set("subject", claims.Subject)
+		actor = claims.Subject
+		if actor == "" {
+			actor = string(claims.Role)
+		}
+	}
+	context.Set("actor", actor)
+	context.Request = context.Request.WithContext(entity.WithActor(context.Request.Context(), actor))
+	context.Next()
+}
+
+// requestID запоминает адрес клиента и идентификатор запроса, их записывает аудит изменений.
+// Идентификатор возвращается в заголовке X-Request-ID, чтобы запрос можно было найти в аудите.
+// Адрес клиента берется из X-Forwarded-For только за доверенными прокси, см. gin.Engine.SetTrustedProxies
+func requestID(context *gin.Context) {
+	id := context.GetHeader(requestIDHeader)
+	if !validRequestID(id) {
+		raw := make([]byte, 16)
+		if _, err := rand.Read(raw); err != nil {
+			context.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		id = hex.EncodeToString(raw)
 	}
+	context.Header(requestIDHeader, id)
+	context.Set("requestID", id)
+	request := entity.Request{ClientIP: context.ClientIP(), RequestID: id}
+	context.Request = context.Request.WithContext(entity.WithRequest(context.Request.Context(), request))
 	context.Next()
 }
+
+// validRequestID допускает непустой идентификатор из печатных символов ASCII без пробелов
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -4,15 +4,28 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(server *gin.Engine, bannerController *BannerController) {
+func RegisterRoutes(server *gin.Engine, bannerController *BannerController, controllers ...Registrar) {
+	registerValidators()
 	server.Use(gin.Logger())
 	server.Use(gin.Recovery())
+	server.Use(requestID)
 	authenticated := server.Group("/")
-	authenticated.Use(authenticate)
-	authenticated.POST("/banner", bannerController.createBanner)
+	authenticated.Use(bannerController.authenticate)
+	authenticated.POST("/banner", bannerController.idempotent, bannerController.createBanner)
+	authenticated.POST("/banner/bulk", bannerController.bulkBanners)
+	authenticated.GET("/banner/export", bannerController.exportBanners)
+	authenticated.POST("/banner/import", bannerController.importBanners)
+	authenticated.GET("/banner/trash", bannerController.getTrash)
 	authenticated.GET("/user_banner", bannerController.getBanner)
 	authenticated.GET("/banner", bannerController.getBanners)
 	authenticated.DELETE("/banner/:id", bannerController.deleteBanner)
 	authenticated.PATCH("/banner/:id", bannerController.updateBanner)
+	authenticated.GET("/banner/:id", bannerController.getBannerByID)
 	authenticated.GET("/banner/history/:id", bannerController.getBannersHistoryByID)
+	authenticated.POST("/banner/:id/rollback", bannerController.rollbackBanner)
+	authenticated.GET("/banner/:id/diff", bannerController.getBannerDiff)
+	authenticated.POST("/banner/:id/restore", bannerController.restoreBanner)
+	for _, c := range controllers {
+		c.Register(authenticated)
+	}
 }
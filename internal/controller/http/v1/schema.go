@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/internal/service"
+	"banner/pkg/i18n"
+	"banner/pkg/logger"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type SchemaController struct {
+	controller
+	schemaRegistry service.SchemaRegistry
+}
+
+func NewSchemaController(schemaRegistry service.SchemaRegistry, logger logger.Logger, messages *i18n.Catalog) *SchemaController {
+	return &SchemaController{
+		controller:     controller{l: logger, messages: messages},
+		schemaRegistry: schemaRegistry,
+	}
+}
+
+func (h *SchemaController) Register(group *gin.RouterGroup) {
+	group.PUT("/feature/:id/schema", h.saveSchema)
+	group.GET("/feature/:id/schema", h.getSchema)
+	group.DELETE("/feature/:id/schema", h.deleteSchema)
+	group.GET("/feature/:id/schema/report", h.getSchemaReport)
+}
+
+func (h *SchemaController) saveSchema(c *gin.Context) {
+	featureID, ok := h.featureID(c)
+	if !ok {
+		return
+	}
+	var schema map[string]interface{}
+	if err := c.ShouldBindJSON(&schema); err != nil || schema == nil {
+		h.l.Error("Failed to parse schema: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidSchema)})
+		return
+	}
+	err := h.schemaRegistry.Save(c.Request.Context(), &entity.FeatureSchema{FeatureID: featureID, Schema: schema})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSchema) {
+			h.l.Info("Invalid schema for feature ID %d: %v", featureID, err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidSchema), "details": err.Error()})
+			return
+		}
+		h.l.Error("Failed to save schema: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	h.l.Info("Schema saved successfully")
+	c.JSON(http.StatusNoContent, nil)
+}
+func (h *SchemaController) getSchema(c *gin.Context) {
+	featureID, ok := h.featureID(c)
+	if !ok {
+		return
+	}
+	schema, err := h.schemaRegistry.Get(c.Request.Context(), featureID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSchemaNotFound) {
+			c.JSON(http.StatusNotFound, nil)
+			return
+		}
+		h.l.Error("Failed to get schema: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	c.JSON(http.StatusOK, schema)
+}
+func (h *SchemaController) deleteSchema(c *gin.Context) {
+	featureID, ok := h.featureID(c)
+	if !ok {
+		return
+	}
+	err := h.schemaRegistry.Delete(c.Request.Context(), featureID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSchemaNotFound) {
+			c.JSON(http.StatusNotFound, nil)
+			return
+		}
+		h.l.Error("Failed to delete schema: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	h.l.Info("Schema deleted successfully")
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// getSchemaReport проверяет существующие баннеры фичи по ее схеме без изменения данных
+func (h *SchemaController) getSchemaReport(c *gin.Context) {
+	featureID, ok := h.featureID(c)
+	if !ok {
+		return
+	}
+	report, err := h.schemaRegistry.Report(c.Request.Context(), featureID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSchemaNotFound) {
+			c.JSON(http.StatusNotFound, nil)
+			return
+		}
+		h.l.Error("Failed to build schema report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// featureID проверяет права администратора и разбирает идентификатор фичи из пути
+func (h *SchemaController) featureID(c *gin.Context) (int32, bool) {
+	if !c.GetBool("isAdmin") {
+		c.JSON(http.StatusForbidden, nil)
+		return 0, false
+	}
+	featureID, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || featureID <= 0 {
+		h.l.Error("Failed to parse feature ID: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidFeatureID)})
+		return 0, false
+	}
+	return int32(featureID), true
+}
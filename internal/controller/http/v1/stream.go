@@ -0,0 +1,228 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"banner/internal/service"
+	"banner/pkg/i18n"
+	"banner/pkg/logger"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	// streamBatchSize - сколько событий поток читает из журнала за один запрос
+	streamBatchSize = 100
+	// defaultEventsLimit - сколько событий отдает /banner/events без limit
+	defaultEventsLimit = 100
+)
+
+// StreamOptions - параметры потока изменений
+type StreamOptions struct {
+	// PollInterval - как часто поток проверяет журнал на новые события
+	PollInterval time.Duration
+	// Heartbeat - через сколько без событий отправить комментарий, чтобы прокси не закрыли соединение
+	Heartbeat time.Duration
+}
+
+type StreamController struct {
+	controller
+	events  service.EventLog
+	options StreamOptions
+
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+func NewStreamController(events service.EventLog, logger logger.Logger, messages *i18n.Catalog, options StreamOptions) *StreamController {
+	return &StreamController{
+		controller: controller{l: logger, messages: messages},
+		events:     events,
+		options:    options,
+		closing:    make(chan struct{}),
+	}
+}
+
+func (h *StreamController) Register(group *gin.RouterGroup) {
+	group.GET("/banner/stream", h.stream)
+	group.GET("/banner/events", h.getEvents)
+}
+
+// Close завершает открытые потоки, чтобы остановка сервера не ждала их. Клиенты переподключатся
+// к другой реплике с Last-Event-ID
+func (h *StreamController) Close() {
+	h.closeOnce.Do(func() {
+		close(h.closing)
+	})
+}
+
+type streamQuery struct {
+	FeatureID   *int32 `form:"feature_id" binding:"omitempty,gt=0"`
+	LastEventID *int64 `binding:"omitempty,gte=0"`
+}
+
+// stream отдает изменения баннеров как Server-Sent Events. Поток продолжается после события из
+// Last-Event-ID (или параметра last_event_id, его может передать EventSource при первом подключении),
+// без него - с текущего момента
+func (h *StreamController) stream(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	fields := fieldErrors{}
+	query := streamQuery{
+		FeatureID:   h.queryInt32(c, "feature_id", fields),
+		LastEventID: h.lastEventID(c, fields),
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	if len(fields) > 0 {
+		h.l.Error("Failed to parse stream query: %v", fields)
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	ctx := c.Request.Context()
+	var afterID int64
+	if query.LastEventID != nil {
+		afterID = *query.LastEventID
+	} else {
+		var err error
+		if afterID, err = h.events.LastEventID(ctx); err != nil {
+			h.l.Error("Failed to get last event ID: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+			return
+		}
+	}
+
+	// поток живет дольше http.Server.WriteTimeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.l.Warn("Failed to reset stream write deadline: %v", err)
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteString(": stream started\n\n")
+	c.Writer.Flush()
+
+	h.l.Info("Banner stream started after event %d", afterID)
+	ticker := time.NewTicker(h.options.PollInterval)
+	defer ticker.Stop()
+	lastWrite := time.Now()
+	for {
+		events, err := h.events.ReadEvents(ctx, &entity.BannerEventsQuery{AfterID: afterID, FeatureID: query.FeatureID, Limit: streamBatchSize})
+		if err != nil {
+			if ctx.Err() == nil {
+				// клиент переподключится с Last-Event-ID
+				h.l.Error("Failed to read banner events: %v", err)
+			}
+			return
+		}
+		for _, event := range events {
+			c.Render(-1, sse.Event{Id: strconv.FormatInt(event.ID, 10), Event: string(event.Type), Data: event})
+			afterID = event.ID
+		}
+		switch {
+		case len(events) > 0:
+			c.Writer.Flush()
+			lastWrite = time.Now()
+			if len(events) == streamBatchSize {
+				// журнал прочитан не до конца
+				continue
+			}
+		case time.Since(lastWrite) >= h.options.Heartbeat:
+			c.Writer.WriteString(": ping\n\n")
+			c.Writer.Flush()
+			lastWrite = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.closing:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// eventsQuery - чтение журнала после номера after, limit - не больше 1000 событий за запрос
+type eventsQuery struct {
+	After     *int64 `form:"after" binding:"omitempty,gte=0"`
+	FeatureID *int32 `form:"feature_id" binding:"omitempty,gt=0"`
+	Limit     *int32 `form:"limit" binding:"omitempty,gt=0,lte=1000"`
+}
+
+// getEvents отдает события журнала изменений по порядку начиная с номера after + 1. Потребитель
+// запоминает next из ответа и продолжает с него; пустая страница - журнал прочитан до конца
+func (h *StreamController) getEvents(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	fields := fieldErrors{}
+	query := eventsQuery{
+		After:     h.queryInt64(c, "after", fields),
+		FeatureID: h.queryInt32(c, "feature_id", fields),
+		Limit:     h.queryInt32(c, "limit", fields),
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	if len(fields) > 0 {
+		h.l.Error("Failed to parse events query: %v", fields)
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	eventsQuery := &entity.BannerEventsQuery{FeatureID: query.FeatureID, Limit: defaultEventsLimit}
+	if query.After != nil {
+		eventsQuery.AfterID = *query.After
+	}
+	if query.Limit != nil {
+		eventsQuery.Limit = int(*query.Limit)
+	}
+	events, err := h.events.ReadEvents(c.Request.Context(), eventsQuery)
+	if err != nil {
+		h.l.Error("Failed to read banner events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	page := &entity.BannerEventsPage{Events: events, Next: eventsQuery.AfterID}
+	if len(events) > 0 {
+		page.Next = events[len(events)-1].ID
+	}
+	if page.Events == nil {
+		page.Events = []*entity.BannerEvent{}
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// lastEventID разбирает Last-Event-ID из заголовка или параметра last_event_id
+func (h *StreamController) lastEventID(c *gin.Context, fields fieldErrors) *int64 {
+	raw := strings.TrimSpace(c.GetHeader("Last-Event-ID"))
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw == "" {
+		return nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		fields["last_event_id"] = h.localize(c, msgValidationInvalid)
+		return nil
+	}
+	return &id
+}
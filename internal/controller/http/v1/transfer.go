@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/internal/service"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"net/http"
+	"strconv"
+)
+
+// maxImportSize - предельный размер файла загрузки, строки читаются потоково, но загрузка идет в одной
+// транзакции, и слишком большой файл надолго занял бы ее
+const maxImportSize = 64 << 20
+
+// contentTypes - Content-Type выгрузки в каждом формате
+var contentTypes = map[entity.TransferFormat]string{
+	entity.FormatJSONL: "application/x-ndjson",
+	entity.FormatCSV:   "text/csv",
+}
+
+type exportQuery struct {
+	Format entity.TransferFormat `form:"format" binding:"omitempty,oneof=jsonl csv"`
+	Filter *entity.BannerFilter  `binding:"-"`
+}
+
+type importQuery struct {
+	Format      entity.TransferFormat   `form:"format" binding:"omitempty,oneof=jsonl csv"`
+	Conflict    entity.ConflictStrategy `form:"conflict" binding:"omitempty,oneof=skip overwrite fail"`
+	DryRun      bool                    `form:"dry_run"`
+	PreserveIDs bool                    `form:"preserve_ids"`
+}
+
+// exportBanners потоково отдает баннеры в JSON Lines или CSV с фильтрами как у GET /banner
+func (h *BannerController) exportBanners(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	fields := fieldErrors{}
+	query := &exportQuery{
+		Format: entity.TransferFormat(c.DefaultQuery("format", string(entity.FormatJSONL))),
+		Filter: h.parseBannerFilter(c, fields),
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	if len(fields) > 0 {
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	writer := &exportWriter{writer: c.Writer, format: query.Format}
+	err := h.bannerService.Export(c.Request.Context(), writer, query.Format, query.Filter)
+	if errors.Is(err, repository.ErrInvalidFilter) && !c.Writer.Written() {
+		h.l.Info("Invalid export filter: %v", err)
+		h.abortWithValidation(c, msgInvalidQuery, fieldErrors{"content_path": h.localize(c, msgValidationInvalid)})
+		return
+	}
+	if err != nil {
+		h.l.Error("Failed to export banners: %v", err)
+		// если выгрузка уже началась, статус изменить нельзя - клиент получит оборванный файл
+		if !c.Writer.Written() {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		}
+		return
+	}
+	// пустая выгрузка - тоже файл
+	writer.start()
+	h.l.Info("Banners exported successfully")
+}
+
+// exportWriter выставляет заголовки файла выгрузки перед первой записью: пока выгрузка не началась,
+// ошибку, например некорректный фильтр, еще можно вернуть обычным JSON-ответом
+type exportWriter struct {
+	writer  gin.ResponseWriter
+	format  entity.TransferFormat
+	started bool
+}
+
+func (w *exportWriter) Write(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	w.start()
+	return w.writer.Write(data)
+}
+
+func (w *exportWriter) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.writer.Header().Set("Content-Type", contentTypes[w.format])
+	w.writer.Header().Set("Content-Disposition", `attachment; filename="banners.`+string(w.format)+`"`)
+	w.writer.WriteHeader(http.StatusOK)
+}
+
+// importBanners загружает баннеры из тела запроса и возвращает отчет с отклоненными строками
+func (h *BannerController) importBanners(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	fields := fieldErrors{}
+	query := &importQuery{
+		Format:      entity.TransferFormat(c.Query("format")),
+		Conflict:    entity.ConflictStrategy(c.DefaultQuery("conflict", string(entity.ConflictSkip))),
+		DryRun:      h.queryBool(c, "dry_run", fields),
+		PreserveIDs: h.queryBool(c, "preserve_ids", fields),
+	}
+	if query.Format == "" {
+		query.Format = entity.FormatJSONL
+		if c.ContentType() == contentTypes[entity.FormatCSV] {
+			query.Format = entity.FormatCSV
+		}
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	if len(fields) > 0 {
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	if query.Conflict == entity.ConflictOverwrite && h.abortWithoutReview(c, nil) {
+		h.l.Info("Import with overwrite rejected: review is required")
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	report, err := h.bannerService.Import(c.Request.Context(), body, &entity.ImportOptions{
+		Format:      query.Format,
+		Conflict:    query.Conflict,
+		DryRun:      query.DryRun,
+		PreserveIDs: query.PreserveIDs,
+	})
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.l.Info("Import rejected: %v", err)
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": h.localize(c, msgImportTooLarge)})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidImport) {
+		h.l.Info("Import rejected: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidData), "detail": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrImportConflict) {
+		h.l.Info("Import aborted: %v", err)
+		c.JSON(http.StatusConflict, gin.H{"error": h.localize(c, msgImportConflict), "detail": err.Error()})
+		return
+	}
+	if err != nil {
+		h.l.Error("Failed to import banners: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	h.l.Info("Banners imported: %d created, %d updated, %d rejected", report.Created, report.Updated, len(report.Rejected))
+	c.JSON(http.StatusOK, report)
+}
+
+// queryBool строго разбирает необязательный логический query-параметр
+func (h *controller) queryBool(c *gin.Context, name string, fields fieldErrors) bool {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return false
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		fields[name] = h.localize(c, msgValidationInvalid)
+	}
+	return value
+}
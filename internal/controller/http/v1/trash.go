@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"banner/internal/repository"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// trashQuery - пагинация корзины, limit=0 означает отсутствие лимита
+type trashQuery struct {
+	Limit  *int32 `form:"limit" binding:"omitempty,gte=0"`
+	Offset *int32 `form:"offset" binding:"omitempty,gte=0"`
+}
+
+// getTrash отдает удаленные баннеры, которые еще не очищены
+func (h *BannerController) getTrash(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	fields := fieldErrors{}
+	query := trashQuery{
+		Limit:  h.queryInt32(c, "limit", fields),
+		Offset: h.queryInt32(c, "offset", fields),
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	if len(fields) > 0 {
+		h.l.Error("Failed to parse trash query: %v", fields)
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	var offset int32
+	if query.Offset != nil {
+		offset = *query.Offset
+	}
+	limit := query.Limit
+	if limit != nil && *limit == 0 {
+		limit = nil
+	}
+	banners, err := h.bannerService.Trash(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.l.Error("Failed to get trash: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	c.JSON(http.StatusOK, banners)
+}
+
+// restoreBanner возвращает баннер из корзины, версия удаленного баннера может быть задана в If-Match
+func (h *BannerController) restoreBanner(c *gin.Context) {
+	token := c.GetBool("isAdmin")
+	if !token {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	bannerID, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		h.l.Error("Failed to parse banner ID: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidBannerID)})
+		return
+	}
+	// восстановление возвращает содержимое в выдачу, а черновик к баннеру в корзине не создать
+	if h.abortWithoutReview(c, nil) {
+		h.l.Info("Restore of banner %d rejected: review is required", bannerID)
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		h.abortPreconditionFailed(c)
+		return
+	}
+	err = h.bannerService.Restore(c.Request.Context(), int32(bannerID), version)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrBannerNotFound):
+			h.l.Info("No deleted banner found with ID: %d", bannerID)
+			c.JSON(http.StatusNotFound, gin.H{"error": h.localize(c, msgBannerNotFound)})
+		case errors.Is(err, repository.ErrRestoreConflict):
+			h.l.Info("Banner %d conflicts with an active banner", bannerID)
+			c.JSON(http.StatusConflict, gin.H{"error": h.localize(c, msgRestoreConflict)})
+		case errors.Is(err, repository.ErrVersionMismatch):
+			h.l.Info("Banner %d version mismatch", bannerID)
+			h.abortPreconditionFailed(c)
+		default:
+			h.l.Error("Failed to restore banner: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		}
+		return
+	}
+	h.l.Info("Banner restored successfully")
+	c.JSON(http.StatusNoContent, nil)
+}
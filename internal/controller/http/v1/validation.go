@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var registerValidatorsOnce sync.Once
+
+// registerValidators подключает к валидатору gin правила сущностей
+func registerValidators() {
+	registerValidatorsOnce.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			entity.RegisterValidations(v)
+		}
+	})
+}
+
+// fieldErrors - ошибки валидации в разрезе полей запроса
+type fieldErrors map[string]string
+
+// validationErrors переводит ошибку разбора или валидации в ошибки по полям
+func (h *controller) validationErrors(c *gin.Context, err error) fieldErrors {
+	fields := fieldErrors{}
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			fields[fieldPath(fe)] = h.validationMessage(c, fe.Tag(), fe.Param())
+		}
+	case errors.As(err, &typeErr):
+		fields[typeErr.Field] = h.localize(c, msgValidationInvalid)
+	}
+	return fields
+}
+
+// fieldPath - путь к полю без имени корневой структуры, например operations[0].banner.tag_ids
+func fieldPath(fe validator.FieldError) string {
+	parts := strings.SplitN(fe.Namespace(), ".", 2)
+	if len(parts) < 2 {
+		return fe.Field()
+	}
+	return parts[1]
+}
+
+func (h *controller) validationMessage(c *gin.Context, tag, param string) string {
+	key, ok := validationMessages[tag]
+	if !ok {
+		return h.localize(c, msgValidationInvalid)
+	}
+	msg := h.localize(c, key)
+	if strings.Contains(msg, "%s") {
+		return fmt.Sprintf(msg, param)
+	}
+	return msg
+}
+
+var validationMessages = map[string]string{
+	"required":         msgValidationRequired,
+	"required_if":      msgValidationRequired,
+	"required_unless":  msgValidationRequired,
+	"required_without": msgValidationRequired,
+	"oneof":            msgValidationOneOf,
+	"gt":               msgValidationGt,
+	"gte":              msgValidationGte,
+	"lte":              msgValidationLte,
+	"min":              msgValidationMin,
+	"max":              msgValidationMax,
+	"unique":           msgValidationUnique,
+	"contentsize":      msgValidationContentSize,
+}
+
+// abortWithValidation отвечает 400 с общим сообщением и подробностями по полям
+func (h *controller) abortWithValidation(c *gin.Context, key string, fields fieldErrors) {
+	body := gin.H{"error": h.localize(c, key)}
+	if len(fields) > 0 {
+		body["fields"] = fields
+	}
+	c.JSON(http.StatusBadRequest, body)
+}
+
+// queryInt32 строго разбирает необязательный целочисленный query-параметр
+func (h *controller) queryInt32(c *gin.Context, name string, fields fieldErrors) *int32 {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil {
+		fields[name] = h.localize(c, msgValidationInteger)
+		return nil
+	}
+	converted := int32(value)
+	return &converted
+}
+
+// queryInt64 строго разбирает необязательный query-параметр int64, например номер события
+func (h *controller) queryInt64(c *gin.Context, name string, fields fieldErrors) *int64 {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		fields[name] = h.localize(c, msgValidationInteger)
+		return nil
+	}
+	return &value
+}
+
+// abortOnContentMismatch отвечает 400 со списком JSON-указателей, если содержимое не прошло проверку схемой
+func (h *controller) abortOnContentMismatch(c *gin.Context, err error) bool {
+	var validationErr *entity.ContentValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgContentMismatch), "violations": validationErr.Violations})
+	return true
+}
@@ -0,0 +1,256 @@
+package v1
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/internal/service"
+	"banner/pkg/i18n"
+	"banner/pkg/logger"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type WebhookController struct {
+	controller
+	webhooks service.WebhookRegistry
+}
+
+func NewWebhookController(webhooks service.WebhookRegistry, logger logger.Logger, messages *i18n.Catalog) *WebhookController {
+	return &WebhookController{
+		controller: controller{l: logger, messages: messages},
+		webhooks:   webhooks,
+	}
+}
+
+func (h *WebhookController) Register(group *gin.RouterGroup) {
+	group.POST("/webhooks", h.createWebhook)
+	group.GET("/webhooks", h.getWebhooks)
+	group.GET("/webhooks/:id", h.getWebhook)
+	group.PATCH("/webhooks/:id", h.updateWebhook)
+	group.DELETE("/webhooks/:id", h.deleteWebhook)
+	group.GET("/webhooks/:id/deliveries", h.getDeliveries)
+	group.POST("/webhooks/:id/deliveries/:delivery_id/retry", h.redeliver)
+}
+
+// webhookRequest - новая подписка. Пустые event_types и feature_ids - все события и все фичи
+type webhookRequest struct {
+	URL        string                   `json:"url" binding:"required,http_url"`
+	Secret     string                   `json:"secret"`
+	EventTypes []entity.BannerEventType `json:"event_types" binding:"omitempty,unique,dive,oneof=created updated deleted restored activated deactivated"`
+	FeatureIDs []int32                  `json:"feature_ids" binding:"omitempty,unique,dive,gt=0"`
+	IsActive   *bool                    `json:"is_active"`
+}
+
+// webhookUpdateRequest - изменение подписки, отсутствующие поля не меняются
+type webhookUpdateRequest struct {
+	URL        *string                   `json:"url" binding:"omitempty,http_url"`
+	Secret     *string                   `json:"secret" binding:"omitempty,min=1"`
+	EventTypes *[]entity.BannerEventType `json:"event_types" binding:"omitempty,unique,dive,oneof=created updated deleted restored activated deactivated"`
+	FeatureIDs *[]int32                  `json:"feature_ids" binding:"omitempty,unique,dive,gt=0"`
+	IsActive   *bool                     `json:"is_active"`
+}
+
+// createWebhook создает подписку и отвечает 201 с ключом подписи, позже API его не показывает
+func (h *WebhookController) createWebhook(c *gin.Context) {
+	if !c.GetBool("isAdmin") {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	var request webhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.l.Error("Failed to parse webhook: %v", err)
+		h.abortWithValidation(c, msgInvalidWebhook, h.validationErrors(c, err))
+		return
+	}
+	hook := &entity.Webhook{
+		URL:        request.URL,
+		Secret:     request.Secret,
+		EventTypes: request.EventTypes,
+		FeatureIDs: request.FeatureIDs,
+		IsActive:   request.IsActive == nil || *request.IsActive,
+	}
+	hook, err := h.webhooks.Create(c.Request.Context(), hook)
+	if err != nil {
+		h.l.Error("Failed to create webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	h.l.Info("Webhook %d created", hook.ID)
+	c.Header("Location", fmt.Sprintf("/webhooks/%d", hook.ID))
+	c.JSON(http.StatusCreated, hook)
+}
+
+func (h *WebhookController) getWebhooks(c *gin.Context) {
+	if !c.GetBool("isAdmin") {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+	hooks, err := h.webhooks.List(c.Request.Context())
+	if err != nil {
+		h.l.Error("Failed to get webhooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+func (h *WebhookController) getWebhook(c *gin.Context) {
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+	hook, err := h.webhooks.Get(c.Request.Context(), id)
+	if h.abortOnWebhookError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+func (h *WebhookController) updateWebhook(c *gin.Context) {
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+	var request webhookUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.l.Error("Failed to parse webhook update: %v", err)
+		h.abortWithValidation(c, msgInvalidWebhook, h.validationErrors(c, err))
+		return
+	}
+	hook, err := h.webhooks.Update(c.Request.Context(), &entity.WebhookUpdate{
+		ID:         id,
+		URL:        request.URL,
+		Secret:     request.Secret,
+		EventTypes: request.EventTypes,
+		FeatureIDs: request.FeatureIDs,
+		IsActive:   request.IsActive,
+	})
+	if h.abortOnWebhookError(c, err) {
+		return
+	}
+	h.l.Info("Webhook %d updated", id)
+	c.JSON(http.StatusOK, hook)
+}
+
+func (h *WebhookController) deleteWebhook(c *gin.Context) {
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+	if h.abortOnWebhookError(c, h.webhooks.Delete(c.Request.Context(), id)) {
+		return
+	}
+	h.l.Info("Webhook %d deleted", id)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// deliveriesQuery - фильтр и пагинация журнала доставок, limit=0 означает отсутствие лимита
+type deliveriesQuery struct {
+	Status entity.DeliveryStatus `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+	Limit  *int32                `form:"limit" binding:"omitempty,gte=0"`
+	Offset *int32                `form:"offset" binding:"omitempty,gte=0"`
+}
+
+// getDeliveries отдает журнал доставок подписки, новые первыми
+func (h *WebhookController) getDeliveries(c *gin.Context) {
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+	fields := fieldErrors{}
+	query := deliveriesQuery{
+		Status: entity.DeliveryStatus(c.Query("status")),
+		Limit:  h.queryInt32(c, "limit", fields),
+		Offset: h.queryInt32(c, "offset", fields),
+	}
+	if err := binding.Validator.ValidateStruct(query); err != nil {
+		for field, msg := range h.validationErrors(c, err) {
+			fields[field] = msg
+		}
+	}
+	if len(fields) > 0 {
+		h.l.Error("Failed to parse deliveries query: %v", fields)
+		h.abortWithValidation(c, msgInvalidQuery, fields)
+		return
+	}
+	deliveries := &entity.WebhookDeliveriesQuery{WebhookID: id, Limit: query.Limit}
+	if query.Status != "" {
+		deliveries.Status = &query.Status
+	}
+	if query.Offset != nil {
+		deliveries.Offset = *query.Offset
+	}
+	if deliveries.Limit != nil && *deliveries.Limit == 0 {
+		deliveries.Limit = nil
+	}
+	result, err := h.webhooks.Deliveries(c.Request.Context(), deliveries)
+	if h.abortOnWebhookError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// redeliver ставит доставленную или брошенную доставку в очередь заново и отвечает 202
+func (h *WebhookController) redeliver(c *gin.Context) {
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil || deliveryID <= 0 {
+		h.l.Error("Failed to parse delivery ID: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidDelivery)})
+		return
+	}
+	delivery, err := h.webhooks.Redeliver(c.Request.Context(), id, deliveryID)
+	if errors.Is(err, repository.ErrDeliveryNotFound) {
+		h.l.Info("No delivery found with ID: %d", deliveryID)
+		c.JSON(http.StatusNotFound, nil)
+		return
+	}
+	if errors.Is(err, repository.ErrDeliveryPending) {
+		c.JSON(http.StatusConflict, gin.H{"error": h.localize(c, msgDeliveryPending)})
+		return
+	}
+	if err != nil {
+		h.l.Error("Failed to redeliver webhook delivery: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+		return
+	}
+	h.l.Info("Webhook delivery %d queued again", deliveryID)
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// webhookID проверяет права администратора и разбирает идентификатор подписки из пути
+func (h *WebhookController) webhookID(c *gin.Context) (int32, bool) {
+	if !c.GetBool("isAdmin") {
+		c.JSON(http.StatusForbidden, nil)
+		return 0, false
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		h.l.Error("Failed to parse webhook ID: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.localize(c, msgInvalidWebhookID)})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// abortOnWebhookError отвечает 404 на отсутствующую подписку и 500 на остальные ошибки
+func (h *WebhookController) abortOnWebhookError(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, repository.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, nil)
+		return true
+	}
+	h.l.Error("Webhook request failed: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": h.localize(c, msgInternalError)})
+	return true
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor запоминает в ctx, кто выполняет изменение: субъект токена или роль статического токена.
+// Репозиторий передает его в транзакцию, и автор попадает в журнал изменений
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext - автор изменения из ctx, пустая строка - неизвестен
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+type requestKey struct{}
+
+// Request - откуда пришло изменение: адрес клиента и идентификатор запроса для аудита
+type Request struct {
+	ClientIP  string
+	RequestID string
+}
+
+// WithRequest запоминает в ctx данные запроса; репозиторий передает их в транзакцию вместе с автором
+func WithRequest(ctx context.Context, request Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, request)
+}
+
+// RequestFromContext - данные запроса из ctx, пустые - изменение пришло не из HTTP-запроса
+func RequestFromContext(ctx context.Context) Request {
+	request, _ := ctx.Value(requestKey{}).(Request)
+	return request
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// AuditChange - изменение одного значения баннера, Path - JSON Pointer, например /content/title или /tag_ids/0
+type AuditChange struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AuditEntry - запись аудита: кто, откуда и что изменил. Action совпадает с типом события журнала
+// изменений EventID. ClientIP и RequestID пусты у изменений не из HTTP-запросов
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	EventID   int64           `json:"event_id"`
+	BannerID  int32           `json:"banner_id"`
+	Action    BannerEventType `json:"action"`
+	Actor     string          `json:"actor,omitempty"`
+	ClientIP  string          `json:"client_ip,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Diff      []AuditChange   `json:"diff"`
+	CreatedAt time.Time       `json:"created_at"`
+	// Before и After - снимки баннера из события журнала, по ним строится Diff
+	Before map[string]interface{} `json:"-"`
+	After  map[string]interface{} `json:"-"`
+}
+
+// AuditQuery - фильтры и пагинация аудита. From включительно, To - нет; Limit nil - без лимита
+type AuditQuery struct {
+	BannerID *int32
+	Actor    *string
+	Action   *BannerEventType
+	From     *time.Time
+	To       *time.Time
+	Limit    *int32
+	Offset   int32
+}
@@ -2,19 +2,23 @@ package entity
 
 import "time"
 
+// Ограничения баннера проверяются при разборе запроса: не более 100 уникальных тегов
+// и не более 64 КБ содержимого в JSON-представлении
 type Banner struct {
 	ID        int32                  `json:"id"`
-	TagIDs    []int32                `json:"tag_ids"`
-	FeatureID int32                  `json:"feature_id"`
-	Content   map[string]interface{} `json:"content"`
+	TagIDs    []int32                `json:"tag_ids" binding:"required,min=1,max=100,unique,dive,gt=0"`
+	FeatureID int32                  `json:"feature_id" binding:"required,gt=0"`
+	Content   map[string]interface{} `json:"content" binding:"required,contentsize=65536"`
 	IsActive  bool                   `json:"is_active"`
 }
 type BannerUpdate struct {
 	ID        *int32                  `json:"id,omitempty"`
-	TagIDs    *[]int32                `json:"tag_ids,omitempty"`
-	FeatureID *int32                  `json:"feature_id,omitempty"`
-	Content   *map[string]interface{} `json:"content,omitempty"`
+	TagIDs    *[]int32                `json:"tag_ids,omitempty" binding:"omitempty,min=1,max=100,unique,dive,gt=0"`
+	FeatureID *int32                  `json:"feature_id,omitempty" binding:"omitempty,gt=0"`
+	Content   *map[string]interface{} `json:"content,omitempty" binding:"omitempty,contentsize=65536"`
 	IsActive  *bool                   `json:"is_active,omitempty"`
+	// Version - ожидаемая текущая версия баннера из If-Match, nil отключает проверку
+	Version *int32 `json:"-"`
 }
 type FilteredBanner struct {
 	ID        int32                  `json:"id"`
@@ -24,8 +28,39 @@ type FilteredBanner struct {
 	IsActive  bool                   `json:"is_active"`
 	CreatedAt time.Time              `json:"created_at"`
 	UpdatedAt time.Time              `json:"updated_at"`
+	Version   int32                  `json:"version"`
+}
+
+// DeletedBanner - баннер в корзине
+type DeletedBanner struct {
+	FilteredBanner
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// UserBanner - содержимое баннера для пользователя вместе с данными для условных запросов
+type UserBanner struct {
+	Content map[string]interface{}
+	ETag    string
+	// MaxAge - сколько клиент может не перезапрашивать баннер, 0 - перепроверять каждый раз
+	MaxAge time.Duration
 }
 type BannerHistoryItem struct {
 	Index  int
 	Banner *FilteredBanner
 }
+
+// PatchType - формат частичного обновления баннера
+type PatchType int
+
+const (
+	MergePatch PatchType = iota // application/merge-patch+json, RFC 7396
+	JSONPatch                   // application/json-patch+json, RFC 6902
+)
+
+// BannerPatch - патч документа баннера {tag_ids, feature_id, content, is_active}
+type BannerPatch struct {
+	ID      int32
+	Type    PatchType
+	Patch   []byte
+	Version *int32
+}
@@ -0,0 +1,56 @@
+package entity
+
+// BulkAction - вид операции в пакетном запросе
+type BulkAction string
+
+const (
+	BulkCreate     BulkAction = "create"
+	BulkUpdate     BulkAction = "update"
+	BulkDelete     BulkAction = "delete"
+	BulkActivate   BulkAction = "activate"
+	BulkDeactivate BulkAction = "deactivate"
+)
+
+// BulkMode - режим выполнения пакета: atomic - все или ничего, best_effort - каждая операция независимо
+type BulkMode string
+
+const (
+	BulkAtomic     BulkMode = "atomic"
+	BulkBestEffort BulkMode = "best_effort"
+)
+
+type BulkRequest struct {
+	Mode       BulkMode        `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Operations []BulkOperation `json:"operations" binding:"required,min=1,max=100,dive"`
+}
+
+// BulkOperation - одна операция пакета. Для create нужен banner, для update - id и update,
+// для остальных - id. Version работает как If-Match для отдельного баннера
+type BulkOperation struct {
+	Action  BulkAction    `json:"action" binding:"required,oneof=create update delete activate deactivate"`
+	ID      *int32        `json:"id,omitempty" binding:"required_unless=Action create,omitempty,gt=0"`
+	Banner  *Banner       `json:"banner,omitempty" binding:"required_if=Action create"`
+	Update  *BannerUpdate `json:"update,omitempty" binding:"required_if=Action update"`
+	Version *int32        `json:"version,omitempty" binding:"omitempty,gt=0"`
+}
+
+// BulkStatus - итог операции пакета
+type BulkStatus string
+
+const (
+	BulkOK         BulkStatus = "ok"
+	BulkFailed     BulkStatus = "failed"
+	BulkRolledBack BulkStatus = "rolled_back" // выполнена, но отменена вместе с транзакцией пакета
+	BulkSkipped    BulkStatus = "skipped"     // не выполнялась, потому что пакет уже отменен
+)
+
+type BulkResult struct {
+	Index  int        `json:"index"`
+	Action BulkAction `json:"action"`
+	ID     *int32     `json:"id,omitempty"`
+	Status BulkStatus `json:"status"`
+	// Err - причина ошибки операции, в ответ попадает ее локализованное описание
+	Err        error              `json:"-"`
+	Error      string             `json:"error,omitempty"`
+	Violations []ContentViolation `json:"violations,omitempty"`
+}
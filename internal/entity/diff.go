@@ -0,0 +1,33 @@
+package entity
+
+import "banner/pkg/jsondiff"
+
+// TagIDsDiff - теги, которые появились и пропали между версиями
+type TagIDsDiff struct {
+	Added   []int32 `json:"added"`
+	Removed []int32 `json:"removed"`
+}
+
+type FeatureIDChange struct {
+	From int32 `json:"from"`
+	To   int32 `json:"to"`
+}
+
+type IsActiveChange struct {
+	From bool `json:"from"`
+	To   bool `json:"to"`
+}
+
+// BannerDiff - разница между версиями From и To баннера. FeatureID и IsActive есть, только если они изменились,
+// Content - JSON Patch (RFC 6902), который превращает содержимое версии From в содержимое версии To.
+// Unified - построчный diff версий для чтения человеком, есть только по запросу
+type BannerDiff struct {
+	ID        int32                `json:"id"`
+	From      int32                `json:"from"`
+	To        int32                `json:"to"`
+	TagIDs    TagIDsDiff           `json:"tag_ids"`
+	FeatureID *FeatureIDChange     `json:"feature_id,omitempty"`
+	IsActive  *IsActiveChange      `json:"is_active,omitempty"`
+	Content   []jsondiff.Operation `json:"content"`
+	Unified   string               `json:"unified,omitempty"`
+}
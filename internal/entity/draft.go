@@ -0,0 +1,69 @@
+package entity
+
+import "time"
+
+// DraftStatus - этап ревью черновика
+type DraftStatus string
+
+const (
+	// DraftOpen - черновик редактируется автором
+	DraftOpen DraftStatus = "draft"
+	// DraftPending - черновик отправлен на ревью и не меняется до решения
+	DraftPending DraftStatus = "pending"
+	// DraftApproved - черновик одобрен и опубликован в версии PublishedVersion
+	DraftApproved DraftStatus = "approved"
+	// DraftRejected - черновик отклонен, автор может исправить его и отправить снова
+	DraftRejected DraftStatus = "rejected"
+	// DraftDiscarded - черновик отменен
+	DraftDiscarded DraftStatus = "discarded"
+)
+
+// BannerDraft - предлагаемое состояние баннера BannerID, сделанное от версии BaseVersion. Пока черновик
+// не одобрен, пользователи получают опубликованную версию баннера. Comment - комментарий ревьюера
+type BannerDraft struct {
+	ID               int64                  `json:"id"`
+	BannerID         int32                  `json:"banner_id"`
+	BaseVersion      int32                  `json:"base_version"`
+	TagIDs           []int32                `json:"tag_ids"`
+	FeatureID        int32                  `json:"feature_id"`
+	Content          map[string]interface{} `json:"content"`
+	IsActive         bool                   `json:"is_active"`
+	Status           DraftStatus            `json:"status"`
+	Author           string                 `json:"author"`
+	Reviewer         string                 `json:"reviewer,omitempty"`
+	Comment          string                 `json:"comment,omitempty"`
+	PublishedVersion *int32                 `json:"published_version,omitempty"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
+	SubmittedAt      *time.Time             `json:"submitted_at,omitempty"`
+	ReviewedAt       *time.Time             `json:"reviewed_at,omitempty"`
+}
+
+// Banner - баннер в том виде, в котором его опубликует черновик
+func (d *BannerDraft) Banner() *Banner {
+	return &Banner{ID: d.BannerID, TagIDs: d.TagIDs, FeatureID: d.FeatureID, Content: d.Content, IsActive: d.IsActive}
+}
+
+// Apply переносит в черновик заданные поля изменения
+func (d *BannerDraft) Apply(changes *BannerUpdate) {
+	if changes.TagIDs != nil {
+		d.TagIDs = *changes.TagIDs
+	}
+	if changes.FeatureID != nil {
+		d.FeatureID = *changes.FeatureID
+	}
+	if changes.Content != nil {
+		d.Content = *changes.Content
+	}
+	if changes.IsActive != nil {
+		d.IsActive = *changes.IsActive
+	}
+}
+
+// DraftsQuery - фильтры и пагинация черновиков, Limit nil - без лимита
+type DraftsQuery struct {
+	BannerID *int32
+	Status   *DraftStatus
+	Limit    *int32
+	Offset   int32
+}
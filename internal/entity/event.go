@@ -0,0 +1,82 @@
+package entity
+
+import "time"
+
+// BannerEventType - вид изменения баннера в журнале изменений
+type BannerEventType string
+
+const (
+	EventCreated     BannerEventType = "created"
+	EventUpdated     BannerEventType = "updated"
+	EventDeleted     BannerEventType = "deleted"
+	EventRestored    BannerEventType = "restored"
+	EventActivated   BannerEventType = "activated"
+	EventDeactivated BannerEventType = "deactivated"
+)
+
+// TagFeature - пара тег-фича, по которой пользователи получают баннер
+type TagFeature struct {
+	TagID     int32 `json:"tag_id"`
+	FeatureID int32 `json:"feature_id"`
+}
+
+// BannerSnapshot - состояние баннера до или после изменения
+type BannerSnapshot struct {
+	ID        int32                  `json:"id"`
+	TagIDs    []int32                `json:"tag_ids"`
+	FeatureID int32                  `json:"feature_id"`
+	Content   map[string]interface{} `json:"content"`
+	IsActive  bool                   `json:"is_active"`
+	Version   int32                  `json:"version"`
+	DeletedAt *time.Time             `json:"deleted_at,omitempty"`
+}
+
+// BannerEvent - запись журнала изменений. ID - порядковый номер: растет в порядке фиксации изменений.
+// Content - содержимое после изменения, у удаления его нет. Before нет у создания, After - у удаления
+// строки из таблицы; у событий, записанных до появления снимков, нет обоих
+type BannerEvent struct {
+	ID        int64                  `json:"id"`
+	Type      BannerEventType        `json:"type"`
+	BannerID  int32                  `json:"banner_id"`
+	Version   int32                  `json:"version"`
+	FeatureID int32                  `json:"feature_id"`
+	TagIDs    []int32                `json:"tag_ids"`
+	Content   map[string]interface{} `json:"content,omitempty"`
+	IsActive  bool                   `json:"is_active"`
+	// Affected - пары тег-фича до и после изменения, ответы /user_banner по которым могли измениться
+	Affected []TagFeature `json:"affected"`
+	// Actor - кто сделал изменение, пусто - изменение без автора, например миграция данных
+	Actor     string          `json:"actor,omitempty"`
+	Before    *BannerSnapshot `json:"before,omitempty"`
+	After     *BannerSnapshot `json:"after,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// BannerEventsQuery - события после AfterID, только по фиче FeatureID, если она задана
+type BannerEventsQuery struct {
+	AfterID   int64
+	FeatureID *int32
+	Limit     int
+}
+
+// BannerEventsPage - события журнала по порядку и номер, с которого читать следующую страницу
+type BannerEventsPage struct {
+	Events []*BannerEvent `json:"events"`
+	Next   int64          `json:"next"`
+}
+
+// AffectedPairs - пары тег-фича из featureID и tagIDs без повторов, в порядке появления
+func AffectedPairs(featureIDs []int32, tagIDs [][]int32) []TagFeature {
+	seen := make(map[TagFeature]bool)
+	pairs := []TagFeature{}
+	for i, featureID := range featureIDs {
+		for _, tagID := range tagIDs[i] {
+			pair := TagFeature{TagID: tagID, FeatureID: featureID}
+			if !seen[pair] {
+				seen[pair] = true
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs
+}
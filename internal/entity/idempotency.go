@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// IdempotencyKey - запрос, выполненный с заголовком Idempotency-Key. Ключ действует в пределах автора
+// запроса Actor. Пока запрос выполняется, Response пустой
+type IdempotencyKey struct {
+	Actor       string
+	Key         string
+	Fingerprint string
+	Response    *IdempotentResponse
+	ExpiresAt   time.Time
+}
+
+// IdempotentResponse - сохраненный ответ, который отдается повторно на ретраи с тем же ключом
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
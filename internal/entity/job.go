@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobKind - тип фоновой задачи
+type JobKind string
+
+const (
+	JobDeleteBanners JobKind = "delete_banners"
+)
+
+// JobStatus - состояние фоновой задачи. Задача, которую повторят после ошибки, снова становится queued
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// Job - фоновая задача и ее прогресс: Processed из Total. Error - ошибка последней попытки,
+// RunAt - время, не раньше которого задачу возьмет воркер
+type Job struct {
+	ID          int64           `json:"id"`
+	Kind        JobKind         `json:"kind"`
+	Status      JobStatus       `json:"status"`
+	Params      json.RawMessage `json:"params"`
+	Total       int64           `json:"total"`
+	Processed   int64           `json:"processed"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"max_attempts"`
+	Error       string          `json:"error,omitempty"`
+	RunAt       time.Time       `json:"run_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+// JobsQuery - фильтр и пагинация списка задач
+type JobsQuery struct {
+	Status *JobStatus
+	Kind   *JobKind
+	Limit  *int32
+	Offset int32
+}
+
+// DeleteBannersParams - фильтр массового удаления, задан хотя бы один из идентификаторов
+type DeleteBannersParams struct {
+	FeatureID *int32 `json:"feature_id,omitempty"`
+	TagID     *int32 `json:"tag_id,omitempty"`
+}
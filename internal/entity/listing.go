@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// SortField - поле, по которому сортируется список баннеров
+type SortField string
+
+const (
+	SortByID        SortField = "id"
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+	SortByFeatureID SortField = "feature_id"
+)
+
+type SortOrder string
+
+const (
+	OrderAsc  SortOrder = "asc"
+	OrderDesc SortOrder = "desc"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TagMatch - как сопоставляются теги фильтра с тегами баннера
+type TagMatch string
+
+const (
+	TagMatchAny TagMatch = "any" // есть хотя бы один из тегов
+	TagMatchAll TagMatch = "all" // есть все теги
+)
+
+// BannerFilter - фильтры списка баннеров, общие для GET /banner и выгрузки. Пустые поля не фильтруют
+type BannerFilter struct {
+	FeatureID  *int32
+	TagID      *int32
+	FeatureIDs []int32
+	TagIDs     []int32
+	TagMatch   TagMatch
+	IsActive   *bool
+
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+
+	// Search - полнотекстовый поиск по строковым значениям content
+	Search string
+	// ContentPath - SQL/JSON path, которому должен удовлетворять content, например $.url ? (@ like_regex "example\.com")
+	ContentPath string
+}
+
+// BannersQuery - запрос страницы баннеров. Если Keyset, страница начинается после Cursor
+// (nil - первая страница), иначе используется Offset
+type BannersQuery struct {
+	BannerFilter
+	SortBy SortField
+	Order  SortOrder
+	Limit  *int32
+	Offset int32
+	Keyset bool
+	Cursor *BannerCursor
+}
+
+// BannersPage - страница баннеров с курсором следующей страницы и общим числом баннеров под фильтрами
+type BannersPage struct {
+	Banners    []*FilteredBanner `json:"banners"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Total      int64             `json:"total"`
+}
+
+// BannerCursor - позиция последнего баннера страницы: значение поля сортировки и id.
+// Клиенту отдается закодированным, сортировка в нем должна совпадать с сортировкой запроса
+type BannerCursor struct {
+	SortBy SortField `json:"s"`
+	Order  SortOrder `json:"o"`
+	Value  string    `json:"v,omitempty"`
+	ID     int32     `json:"i"`
+}
+
+// CursorAfter - курсор, указывающий на позицию сразу за banner
+func CursorAfter(banner *FilteredBanner, sortBy SortField, order SortOrder) *BannerCursor {
+	cursor := &BannerCursor{SortBy: sortBy, Order: order, ID: banner.ID}
+	switch sortBy {
+	case SortByCreatedAt:
+		cursor.Value = banner.CreatedAt.Format(time.RFC3339Nano)
+	case SortByUpdatedAt:
+		cursor.Value = banner.UpdatedAt.Format(time.RFC3339Nano)
+	case SortByFeatureID:
+		cursor.Value = strconv.Itoa(int(banner.FeatureID))
+	}
+	return cursor
+}
+
+func (c *BannerCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает курсор из запроса и проверяет, что значение подходит полю сортировки
+func DecodeCursor(raw string) (*BannerCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor BannerCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Order != OrderAsc && cursor.Order != OrderDesc {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := cursor.SortValue(); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// SortValue - значение поля сортировки в виде, пригодном для сравнения в бд
+func (c *BannerCursor) SortValue() (interface{}, error) {
+	switch c.SortBy {
+	case SortByID:
+		return c.ID, nil
+	case SortByCreatedAt, SortByUpdatedAt:
+		return time.Parse(time.RFC3339Nano, c.Value)
+	case SortByFeatureID:
+		value, err := strconv.ParseInt(c.Value, 10, 32)
+		return int32(value), err
+	default:
+		return nil, ErrInvalidCursor
+	}
+}
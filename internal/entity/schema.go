@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type FeatureSchema struct {
+	FeatureID int32                  `json:"feature_id"`
+	Schema    map[string]interface{} `json:"schema"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// ContentViolation - нарушение JSON Schema в содержимом баннера, Pointer указывает на место в content
+type ContentViolation struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// ContentValidationError возвращается, если содержимое баннера не соответствует схеме фичи
+type ContentValidationError struct {
+	FeatureID  int32
+	Violations []ContentViolation
+}
+
+func (e *ContentValidationError) Error() string {
+	pointers := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		pointers = append(pointers, v.Pointer)
+	}
+	return fmt.Sprintf("content does not match schema of feature %d: %s", e.FeatureID, strings.Join(pointers, ", "))
+}
+
+// SchemaReportItem - результат проверки существующего баннера по схеме фичи
+type SchemaReportItem struct {
+	BannerID   int32              `json:"banner_id"`
+	FeatureID  int32              `json:"feature_id"`
+	Valid      bool               `json:"valid"`
+	Violations []ContentViolation `json:"violations,omitempty"`
+}
@@ -0,0 +1,50 @@
+package entity
+
+// TransferFormat - формат выгрузки и загрузки баннеров
+type TransferFormat string
+
+const (
+	FormatJSONL TransferFormat = "jsonl"
+	FormatCSV   TransferFormat = "csv"
+)
+
+// ConflictStrategy - что делать с загружаемым баннером, который совпадает с существующим
+// по id (при сохранении id) или по фиче и тегам
+type ConflictStrategy string
+
+const (
+	ConflictSkip      ConflictStrategy = "skip"
+	ConflictOverwrite ConflictStrategy = "overwrite"
+	ConflictFail      ConflictStrategy = "fail"
+)
+
+type ImportOptions struct {
+	Format      TransferFormat
+	Conflict    ConflictStrategy
+	DryRun      bool
+	PreserveIDs bool
+}
+
+// ImportReport - итог загрузки. При DryRun изменения не сохраняются, но счетчики те же, что были бы при загрузке
+type ImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Total    int               `json:"total"`
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"`
+	Skipped  int               `json:"skipped"`
+	Rejected []ImportRejection `json:"rejected"`
+}
+
+// причины, по которым строка не загружена
+const (
+	RejectInvalid   = "invalid"
+	RejectConflict  = "conflict"
+	RejectDuplicate = "duplicate" // баннер уже загружен из другой строки файла
+)
+
+type ImportRejection struct {
+	Line   int    `json:"line"`
+	ID     int32  `json:"id,omitempty"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+}
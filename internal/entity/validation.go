@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterValidations подключает к валидатору собственные правила сущностей и берет
+// имена полей в ошибках из json/form тегов
+func RegisterValidations(v *validator.Validate) {
+	v.RegisterTagNameFunc(fieldName)
+	_ = v.RegisterValidation("contentsize", validateContentSize)
+}
+
+// NewValidator создает валидатор, проверяющий сущности по тем же binding-тегам, что и gin при разборе запроса
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	RegisterValidations(v)
+	return v
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// validateContentSize проверяет, что JSON-представление поля не превышает заданного количества байт
+func validateContentSize(fl validator.FieldLevel) bool {
+	limit, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return false
+	}
+	data, err := json.Marshal(fl.Field().Interface())
+	if err != nil {
+		return false
+	}
+	return len(data) <= limit
+}
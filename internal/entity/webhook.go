@@ -0,0 +1,60 @@
+package entity
+
+import "time"
+
+// Webhook - подписка на изменения баннеров. Пустые EventTypes и FeatureIDs - все события и все фичи.
+// Secret - ключ подписи доставок, API показывает его только при создании подписки
+type Webhook struct {
+	ID         int32             `json:"id"`
+	URL        string            `json:"url"`
+	Secret     string            `json:"secret,omitempty"`
+	EventTypes []BannerEventType `json:"event_types"`
+	FeatureIDs []int32           `json:"feature_ids"`
+	IsActive   bool              `json:"is_active"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// WebhookUpdate - частичное изменение подписки, nil - поле не меняется
+type WebhookUpdate struct {
+	ID         int32
+	URL        *string
+	Secret     *string
+	EventTypes *[]BannerEventType
+	FeatureIDs *[]int32
+	IsActive   *bool
+}
+
+// DeliveryStatus - состояние доставки. Доставка, которую повторят после ошибки, остается pending,
+// dead - попытки исчерпаны, доставку можно только отправить заново вручную
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryDead      DeliveryStatus = "dead"
+)
+
+// WebhookDelivery - доставка события подписчику. ResponseStatus и Error - результат последней попытки
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int32           `json:"webhook_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      BannerEventType `json:"event_type"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	ResponseStatus *int32          `json:"response_status,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// WebhookDeliveriesQuery - фильтр и пагинация журнала доставок подписки
+type WebhookDeliveriesQuery struct {
+	WebhookID int32
+	Status    *DeliveryStatus
+	Limit     *int32
+	Offset    int32
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"banner/internal/entity"
+	"banner/pkg/db/postgres"
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// auditColumns - порядок колонок, в котором их читает scanAudit. Снимки баннера берутся из события журнала
+var auditColumns = []string{"a.id", "a.event_id", "a.banner_id", "a.action", "COALESCE(a.actor, '')", "COALESCE(a.client_ip, '')", "COALESCE(a.request_id, '')", "e.before", "e.after", "a.created_at"}
+
+func scanAudit(row rowScanner) (*entity.AuditEntry, error) {
+	var entry entity.AuditEntry
+	err := row.Scan(&entry.ID, &entry.EventID, &entry.BannerID, &entry.Action, &entry.Actor, &entry.ClientIP, &entry.RequestID, &entry.Before, &entry.After, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+type AuditRepository struct {
+	db *postgres.DB
+}
+
+func NewAuditRepository(database *postgres.DB) *AuditRepository {
+	return &AuditRepository{
+		db: database,
+	}
+}
+
+// List отдает записи аудита по фильтрам, новые первыми
+func (r *AuditRepository) List(ctx context.Context, query *entity.AuditQuery) ([]*entity.AuditEntry, error) {
+	sql, args, err := r.selectAudit(query).ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.AuditEntry, error) {
+		return scanAudit(row)
+	})
+}
+
+// StreamAudit читает записи аудита по тем же фильтрам, что и List, не держа их в памяти
+func (r *AuditRepository) StreamAudit(ctx context.Context, query *entity.AuditQuery, fn func(*entity.AuditEntry) error) error {
+	sql, args, err := r.selectAudit(query).ToSql()
+	if err != nil {
+		return err
+	}
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		entry, err := scanAudit(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *AuditRepository) selectAudit(query *entity.AuditQuery) squirrel.SelectBuilder {
+	selectBuilder := r.db.Builder.
+		Select(auditColumns...).
+		From("audit_log a").
+		Join("banner_events e ON e.id = a.event_id").
+		OrderBy("a.id DESC").
+		Offset(uint64(query.Offset))
+	if query.BannerID != nil {
+		selectBuilder = selectBuilder.Where("a.banner_id = ?", *query.BannerID)
+	}
+	if query.Actor != nil {
+		selectBuilder = selectBuilder.Where("a.actor = ?", *query.Actor)
+	}
+	if query.Action != nil {
+		selectBuilder = selectBuilder.Where("a.action = ?", *query.Action)
+	}
+	if query.From != nil {
+		selectBuilder = selectBuilder.Where("a.created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		selectBuilder = selectBuilder.Where("a.created_at < ?", *query.To)
+	}
+	if query.Limit != nil {
+		selectBuilder = selectBuilder.Limit(uint64(*query.Limit))
+	}
+	return selectBuilder
+}
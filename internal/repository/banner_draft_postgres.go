@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"banner/internal/entity"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrDraftNotFound = errors.New("no banner draft found")
+	// ErrDraftExists - у баннера уже есть открытый черновик
+	ErrDraftExists = errors.New("banner already has an open draft")
+)
+
+// uniqueViolation - код ошибки postgres при нарушении уникального индекса
+const uniqueViolation = "23505"
+
+// draftColumns - порядок колонок, в котором их читает scanDraft
+var draftColumns = []string{"id", "banner_id", "base_version", "tag_ids", "feature_id", "content", "is_active", "status", "author",
+	"COALESCE(reviewer, '')", "COALESCE(comment, '')", "published_version", "created_at", "updated_at", "submitted_at", "reviewed_at"}
+
+func scanDraft(row rowScanner) (*entity.BannerDraft, error) {
+	var draft entity.BannerDraft
+	err := row.Scan(&draft.ID, &draft.BannerID, &draft.BaseVersion, &draft.TagIDs, &draft.FeatureID, &draft.Content, &draft.IsActive,
+		&draft.Status, &draft.Author, &draft.Reviewer, &draft.Comment, &draft.PublishedVersion, &draft.CreatedAt, &draft.UpdatedAt,
+		&draft.SubmittedAt, &draft.ReviewedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDraftNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// CreateDraft сохраняет новый черновик. Черновик не меняет баннер, поэтому в журнал изменений не попадает
+func (r *BannerRepository) CreateDraft(ctx context.Context, draft *entity.BannerDraft) (*entity.BannerDraft, error) {
+	sql, args, err := r.db.Builder.
+		Insert("banner_drafts").
+		SetMap(map[string]interface{}{
+			"banner_id":    draft.BannerID,
+			"base_version": draft.BaseVersion,
+			"tag_ids":      draft.TagIDs,
+			"feature_id":   draft.FeatureID,
+			"content":      draft.Content,
+			"is_active":    draft.IsActive,
+			"status":       entity.DraftOpen,
+			"author":       draft.Author,
+		}).
+		Suffix("RETURNING " + strings.Join(draftColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	created, err := scanDraft(r.conn.QueryRow(ctx, sql, args...))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, ErrDraftExists
+	}
+	return created, err
+}
+
+func (r *BannerRepository) GetDraft(ctx context.Context, id int64) (*entity.BannerDraft, error) {
+	sql, args, err := r.db.Builder.
+		Select(draftColumns...).
+		From("banner_drafts").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return scanDraft(r.conn.QueryRow(ctx, sql, args...))
+}
+
+// LockDraft читает черновик и блокирует его до конца транзакции, вызывается внутри InTx
+func (r *BannerRepository) LockDraft(ctx context.Context, id int64) (*entity.BannerDraft, error) {
+	sql, args, err := r.db.Builder.
+		Select(draftColumns...).
+		From("banner_drafts").
+		Where("id = ?", id).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return scanDraft(r.conn.QueryRow(ctx, sql, args...))
+}
+
+// Drafts отдает черновики по фильтрам, новые первыми
+func (r *BannerRepository) Drafts(ctx context.Context, query *entity.DraftsQuery) ([]*entity.BannerDraft, error) {
+	selectBuilder := r.db.Builder.
+		Select(draftColumns...).
+		From("banner_drafts").
+		OrderBy("id DESC").
+		Offset(uint64(query.Offset))
+	if query.BannerID != nil {
+		selectBuilder = selectBuilder.Where("banner_id = ?", *query.BannerID)
+	}
+	if query.Status != nil {
+		selectBuilder = selectBuilder.Where("status = ?", *query.Status)
+	}
+	if query.Limit != nil {
+		selectBuilder = selectBuilder.Limit(uint64(*query.Limit))
+	}
+	sql, args, err := selectBuilder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.BannerDraft, error) {
+		return scanDraft(row)
+	})
+}
+
+// SaveDraft записывает изменяемые поля черновика: содержимое, этап ревью и решение ревьюера
+func (r *BannerRepository) SaveDraft(ctx context.Context, draft *entity.BannerDraft) (*entity.BannerDraft, error) {
+	sql, args, err := r.db.Builder.
+		Update("banner_drafts").
+		Set("tag_ids", draft.TagIDs).
+		Set("feature_id", draft.FeatureID).
+		Set("content", draft.Content).
+		Set("is_active", draft.IsActive).
+		Set("status", draft.Status).
+		Set("reviewer", nullIfEmpty(draft.Reviewer)).
+		Set("comment", nullIfEmpty(draft.Comment)).
+		Set("published_version", draft.PublishedVersion).
+		Set("submitted_at", draft.SubmittedAt).
+		Set("reviewed_at", draft.ReviewedAt).
+		Set("updated_at", time.Now().UTC()).
+		Where("id = ?", draft.ID).
+		Suffix("RETURNING " + strings.Join(draftColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return scanDraft(r.conn.QueryRow(ctx, sql, args...))
+}
+
+func nullIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -3,22 +3,146 @@ package repository
 import (
 	"banner/internal/entity"
 	"banner/pkg/db/postgres"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrBannerNotFound  = errors.New("no banner found")
+	ErrInvalidPatch    = errors.New("patch cannot be applied")
+	ErrVersionMismatch = errors.New("banner version mismatch")
+	ErrVersionNotFound = errors.New("no banner version found")
+	ErrInvalidFilter   = errors.New("invalid banner filter")
+)
+
+// bannerColumns - порядок колонок, в котором их читает scanBanner; общий для banners и banners_history
+var bannerColumns = []string{"id", "tag_ids", "feature_id", "content", "is_active", "created_at", "updated_at", "version"}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBanner(row rowScanner) (*entity.FilteredBanner, error) {
+	var banner entity.FilteredBanner
+	err := row.Scan(&banner.ID, &banner.TagIDs, &banner.FeatureID, &banner.Content, &banner.IsActive, &banner.CreatedAt, &banner.UpdatedAt, &banner.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &banner, nil
+}
+
+// коды ошибок postgres, означающие некорректный патч
+const (
+	invalidParameterValue     = "22023" // так сообщают об ошибке функции jsonb_patch и jsonb_merge_patch
+	invalidTextRepresentation = "22P02" // текст патча не является корректным JSON
+)
+
+// коды ошибок postgres, означающие некорректный content_path в фильтре
+const (
+	syntaxError              = "42601"
+	invalidRegularExpression = "2201B"
 )
 
+// conn - общие методы пула и транзакции. Внутри транзакции Begin создает точку сохранения,
+// поэтому методы репозитория, открывающие свою транзакцию, работают и в InTx
+type conn interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type BannerRepository struct {
-	db *postgres.DB
+	db   *postgres.DB
+	conn conn
 }
 
 func NewBannerRepository(database *postgres.DB) *BannerRepository {
 	return &BannerRepository{
-		db: database,
+		db:   database,
+		conn: database.Pool,
+	}
+}
+
+// InTx выполняет fn с репозиторием, все запросы которого идут в одной транзакции.
+// Транзакция фиксируется, только если fn не вернула ошибку
+func (r *BannerRepository) InTx(ctx context.Context, fn func(repo *BannerRepository) error) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := fn(&BannerRepository{db: r.db, conn: tx}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// begin открывает транзакцию и передает в нее автора изменения и данные запроса из ctx, их записывают
+// триггеры журнала изменений и аудита
+func (r *BannerRepository) begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if actor, request, ok := changeAuthor(ctx); ok {
+		_, err := tx.Exec(ctx, "SELECT set_config('banner.actor', $1, true), set_config('banner.client_ip', $2, true), set_config('banner.request_id', $3, true)",
+			actor, request.ClientIP, request.RequestID)
+		if err != nil {
+			tx.Rollback(ctx)
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// changeAuthor - автор и данные запроса из ctx, ok = false - ни того, ни другого нет
+func changeAuthor(ctx context.Context) (actor string, request entity.Request, ok bool) {
+	actor, request = entity.ActorFromContext(ctx), entity.RequestFromContext(ctx)
+	return actor, request, actor != "" || request != entity.Request{}
+}
+
+// withActor выполняет изменение fn в транзакции с автором и данными запроса из ctx. Без них и внутри InTx,
+// где они уже переданы, отдельная транзакция не нужна
+func (r *BannerRepository) withActor(ctx context.Context, fn func(c conn) error) error {
+	if _, inTx := r.conn.(pgx.Tx); inTx {
+		return fn(r.conn)
+	}
+	if _, _, ok := changeAuthor(ctx); !ok {
+		return fn(r.conn)
+	}
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := fn(tx); err != nil {
+		return err
 	}
+	return tx.Commit(ctx)
+}
+
+// exec выполняет изменяющий запрос с автором из ctx
+func (r *BannerRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	var result pgconn.CommandTag
+	err := r.withActor(ctx, func(c conn) error {
+		var err error
+		result, err = c.Exec(ctx, sql, args...)
+		return err
+	})
+	return result, err
 }
+
 func (r *BannerRepository) Save(ctx context.Context, banner *entity.Banner) (int32, error) {
-	tx, err := r.db.Pool.Begin(ctx)
+	tx, err := r.begin(ctx)
 	if err != nil {
 		return -1, err
 	}
@@ -31,6 +155,7 @@ func (r *BannerRepository) Save(ctx context.Context, banner *entity.Banner) (int
 		From("banners").
 		Where("feature_id = ?", banner.FeatureID).
 		Where("tag_ids && ?", banner.TagIDs).
+		Where("deleted_at IS NULL").
 		ToSql()
 	if err != nil {
 		return -1, err
@@ -71,75 +196,215 @@ func (r *BannerRepository) GetBannerByTagsAndFeatureIDForUser(ctx context.Contex
 	sql, args, err := r.db.Builder.
 		Select("content").
 		From("banners").
-		Where("tag_ids @> ARRAY[$1] AND feature_id = $2 AND ($3 OR is_active = true)", tagID, featureID, isActiveParam).
+		Where("tag_ids @> ARRAY[$1] AND feature_id = $2 AND ($3 OR is_active = true) AND deleted_at IS NULL", tagID, featureID, isActiveParam).
 		Limit(1).
 		ToSql()
 	if err != nil {
 		return nil, err
 	}
 	var content map[string]interface{}
-	err = r.db.Pool.QueryRow(ctx, sql, args...).Scan(&content)
+	err = r.conn.QueryRow(ctx, sql, args...).Scan(&content)
 	//защищаем себя от того что текст ошибки может быть изменен
 	if content == nil {
-		return nil, errors.New("no banner found")
+		return nil, ErrBannerNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
 	return content, nil
 }
-func (r *BannerRepository) GetBannersWithOptionalFilters(ctx context.Context, featureID, tagID, limit *int32, offset int32) ([]*entity.FilteredBanner, error) {
-	sql, args, err := r.db.Builder.
-		Select("*").
-		From("banners").
-		Where("($1::integer IS NULL OR feature_id = $1) AND ($2::integer IS NULL OR tag_ids @> ARRAY[$2])", featureID, tagID).
-		Suffix("LIMIT $3 OFFSET $4", limit, offset).
-		ToSql()
+
+// GetBannersWithOptionalFilters отдает страницу баннеров. Порядок всегда однозначен: при равных значениях
+// поля сортировки баннеры упорядочены по id. В режиме Keyset страница начинается после query.Cursor
+func (r *BannerRepository) GetBannersWithOptionalFilters(ctx context.Context, query *entity.BannersQuery) ([]*entity.FilteredBanner, error) {
+	column, ok := sortColumns[query.SortBy]
+	if !ok {
+		column = "id"
+	}
+	direction, comparison := "ASC", ">"
+	if query.Order == entity.OrderDesc {
+		direction, comparison = "DESC", "<"
+	}
+	selectBuilder := filterBanners(r.db.Builder.Select(bannerColumns...).From("banners"), &query.BannerFilter)
+	if query.Keyset && query.Cursor != nil {
+		value, err := query.Cursor.SortValue()
+		if err != nil {
+			return nil, err
+		}
+		if column == "id" {
+			selectBuilder = selectBuilder.Where("id "+comparison+" ?", query.Cursor.ID)
+		} else {
+			selectBuilder = selectBuilder.Where("("+column+", id) "+comparison+" (?, ?)", value, query.Cursor.ID)
+		}
+	}
+	if column == "id" {
+		selectBuilder = selectBuilder.OrderBy("id " + direction)
+	} else {
+		selectBuilder = selectBuilder.OrderBy(column+" "+direction, "id "+direction)
+	}
+	if query.Limit != nil {
+		selectBuilder = selectBuilder.Limit(uint64(*query.Limit))
+	}
+	if !query.Keyset && query.Offset > 0 {
+		selectBuilder = selectBuilder.Offset(uint64(query.Offset))
+	}
+	sql, args, err := selectBuilder.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	rows, err := r.conn.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, err
+		return nil, filterError(err)
 	}
 	defer rows.Close()
 
 	var banners []*entity.FilteredBanner
 	for rows.Next() {
-		var banner entity.FilteredBanner
-		if err := rows.Scan(&banner.ID, &banner.TagIDs, &banner.FeatureID, &banner.Content, &banner.IsActive, &banner.CreatedAt, &banner.UpdatedAt); err != nil {
+		banner, err := scanBanner(rows)
+		if err != nil {
 			return nil, err
 		}
-		banners = append(banners, &banner)
+		banners = append(banners, banner)
 	}
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, filterError(err)
 	}
 	return banners, nil
 }
-func (r *BannerRepository) DeleteByID(ctx context.Context, id int32) error {
+
+// CountBanners считает баннеры, подходящие под фильтры
+func (r *BannerRepository) CountBanners(ctx context.Context, filter *entity.BannerFilter) (int64, error) {
+	sql, args, err := filterBanners(r.db.Builder.Select("COUNT(*)").From("banners"), filter).ToSql()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	err = r.conn.QueryRow(ctx, sql, args...).Scan(&total)
+	return total, filterError(err)
+}
+
+// sortColumns - колонки, по которым можно сортировать список; имя колонки подставляется в запрос как есть
+var sortColumns = map[entity.SortField]string{
+	entity.SortByID:        "id",
+	entity.SortByCreatedAt: "created_at",
+	entity.SortByUpdatedAt: "updated_at",
+	entity.SortByFeatureID: "feature_id",
+}
+
+// filterBanners отбирает баннеры по фильтрам; баннеры в корзине не попадают ни в какие списки
+func filterBanners(selectBuilder squirrel.SelectBuilder, filter *entity.BannerFilter) squirrel.SelectBuilder {
+	selectBuilder = selectBuilder.Where("deleted_at IS NULL")
+	if filter.FeatureID != nil {
+		selectBuilder = selectBuilder.Where("feature_id = ?", *filter.FeatureID)
+	}
+	if filter.TagID != nil {
+		selectBuilder = selectBuilder.Where("tag_ids @> ARRAY[?::integer]", *filter.TagID)
+	}
+	if len(filter.FeatureIDs) > 0 {
+		selectBuilder = selectBuilder.Where("feature_id = ANY(?::integer[])", filter.FeatureIDs)
+	}
+	if len(filter.TagIDs) > 0 {
+		if filter.TagMatch == entity.TagMatchAll {
+			selectBuilder = selectBuilder.Where("tag_ids @> ?::integer[]", filter.TagIDs)
+		} else {
+			selectBuilder = selectBuilder.Where("tag_ids && ?::integer[]", filter.TagIDs)
+		}
+	}
+	if filter.IsActive != nil {
+		selectBuilder = selectBuilder.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.CreatedFrom != nil {
+		selectBuilder = selectBuilder.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		selectBuilder = selectBuilder.Where("created_at < ?", *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		selectBuilder = selectBuilder.Where("updated_at >= ?", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		selectBuilder = selectBuilder.Where("updated_at < ?", *filter.UpdatedTo)
+	}
+	// выражения совпадают с индексами idx_banners_content_fts и idx_banners_content_path
+	if filter.Search != "" {
+		selectBuilder = selectBuilder.Where("to_tsvector('simple', content) @@ websearch_to_tsquery('simple', ?)", filter.Search)
+	}
+	if filter.ContentPath != "" {
+		// ?? - экранированный оператор @?, а не плейсхолдер
+		selectBuilder = selectBuilder.Where("content @?? ?::jsonpath", filter.ContentPath)
+	}
+	return selectBuilder
+}
+
+// filterError переводит ошибку бд из-за некорректного content_path в ErrInvalidFilter
+func filterError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == syntaxError || pgErr.Code == invalidRegularExpression) {
+		return fmt.Errorf("%w: %s", ErrInvalidFilter, pgErr.Message)
+	}
+	return err
+}
+func (r *BannerRepository) GetByID(ctx context.Context, id int32) (*entity.FilteredBanner, error) {
 	sql, args, err := r.db.Builder.
-		Delete("banners").
-		Where("id = $1", id).
+		Select(bannerColumns...).
+		From("banners").
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
 		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	banner, err := scanBanner(r.conn.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrBannerNotFound
+	}
+	return banner, err
+}
+
+// DeleteByID переносит баннер в корзину: он пропадает из выдачи, но остается в таблице до очистки.
+// Удаление - тоже изменение, поэтому версия увеличивается, а прежнее состояние попадает в историю.
+// Если передана version, баннер удаляется только в этой версии
+func (r *BannerRepository) DeleteByID(ctx context.Context, id int32, version *int32) error {
+	currentTime := time.Now().UTC()
+	updateBuilder := r.db.Builder.
+		Update("banners").
+		Set("deleted_at", currentTime).
+		Set("updated_at", currentTime).
+		Set("version", squirrel.Expr("version + 1")).
+		Where("id = ?", id).
+		Where("deleted_at IS NULL")
+	if version != nil {
+		updateBuilder = updateBuilder.Where("version = ?", *version)
+	}
+	sql, args, err := updateBuilder.ToSql()
 	if err != nil {
 		return err
 	}
 
-	result, err := r.db.Pool.Exec(ctx, sql, args...)
+	result, err := r.exec(ctx, sql, args...)
 	if err != nil {
 		return err
 	}
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return errors.New("no banner found")
+		if err := r.missReason(ctx, id, version); err != nil {
+			return err
+		}
+		// баннер изменили между запросами
+		return ErrVersionMismatch
 	}
 	return nil
 }
+
+// UpdateBanner обновляет переданные поля и увеличивает версию баннера. Если задана banner.Version,
+// обновление выполняется только при совпадении текущей версии
 func (r *BannerRepository) UpdateBanner(ctx context.Context, banner *entity.BannerUpdate) error {
 	currentTime := time.Now().UTC()
-	updateBuilder := r.db.Builder.Update("banners").Where("id = ?", banner.ID)
+	updateBuilder := r.db.Builder.Update("banners").Where("id = ?", banner.ID).Where("deleted_at IS NULL")
+	if banner.Version != nil {
+		updateBuilder = updateBuilder.Where("version = ?", *banner.Version)
+	}
 	if banner.TagIDs != nil {
 		updateBuilder = updateBuilder.Set("tag_ids", banner.TagIDs)
 	}
@@ -152,42 +417,211 @@ func (r *BannerRepository) UpdateBanner(ctx context.Context, banner *entity.Bann
 	if banner.IsActive != nil {
 		updateBuilder = updateBuilder.Set("is_active", banner.IsActive)
 	}
-	updateBuilder = updateBuilder.Set("updated_at", currentTime)
+	updateBuilder = updateBuilder.Set("updated_at", currentTime).Set("version", squirrel.Expr("version + 1"))
 	sql, args, err := updateBuilder.ToSql()
 	if err != nil {
 		return err
 	}
-	result, err := r.db.Pool.Exec(ctx, sql, args...)
+	result, err := r.exec(ctx, sql, args...)
 	if err != nil {
 		return err
 	}
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return errors.New("no banner found")
+		if err := r.missReason(ctx, *banner.ID, banner.Version); err != nil {
+			return err
+		}
+		// баннер изменили между запросами
+		return ErrVersionMismatch
 	}
 	return nil
 }
+
+// RollbackBanner возвращает баннер к версии из истории. Откат сам по себе создает новую версию
+func (r *BannerRepository) RollbackBanner(ctx context.Context, id, version int32, expectedVersion *int32) error {
+	updateBuilder := r.db.Builder.
+		Update("banners").
+		Set("tag_ids", squirrel.Expr("h.tag_ids")).
+		Set("feature_id", squirrel.Expr("h.feature_id")).
+		Set("content", squirrel.Expr("h.content")).
+		Set("is_active", squirrel.Expr("h.is_active")).
+		Set("updated_at", time.Now().UTC()).
+		Set("version", squirrel.Expr("banners.version + 1")).
+		From("banners_history h").
+		Where("banners.id = ?", id).
+		Where("banners.deleted_at IS NULL").
+		Where("h.id = banners.id AND h.version = ?", version)
+	if expectedVersion != nil {
+		updateBuilder = updateBuilder.Where("banners.version = ?", *expectedVersion)
+	}
+	sql, args, err := updateBuilder.ToSql()
+	if err != nil {
+		return err
+	}
+	result, err := r.exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		if err := r.missReason(ctx, id, expectedVersion); err != nil {
+			return err
+		}
+		return ErrVersionNotFound
+	}
+	return nil
+}
+
+// missReason объясняет, почему изменение не затронуло ни одной строки: баннера нет
+// или его версия отличается от ожидаемой
+func (r *BannerRepository) missReason(ctx context.Context, id int32, expectedVersion *int32) error {
+	banner, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if expectedVersion != nil && banner.Version != *expectedVersion {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// PatchBanner применяет патч к документу баннера средствами jsonb внутри транзакции со строкой,
+// заблокированной на запись. check получает результат до сохранения и может отменить изменение
+func (r *BannerRepository) PatchBanner(ctx context.Context, patch *entity.BannerPatch, check func(*entity.Banner) error) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	patchFunc := "jsonb_merge_patch"
+	if patch.Type == entity.JSONPatch {
+		patchFunc = "jsonb_patch"
+	}
+	sql, args, err := r.db.Builder.
+		Select().
+		Column(squirrel.Expr(patchFunc+"(jsonb_build_object('tag_ids', to_jsonb(tag_ids), 'feature_id', feature_id, 'content', content, 'is_active', is_active), ?::jsonb)", string(patch.Patch))).
+		Column("version").
+		From("banners").
+		Where("id = ?", patch.ID).
+		Where("deleted_at IS NULL").
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	var (
+		document []byte
+		version  int32
+	)
+	err = tx.QueryRow(ctx, sql, args...).Scan(&document, &version)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == invalidParameterValue || pgErr.Code == invalidTextRepresentation) {
+		return fmt.Errorf("%w: %s", ErrInvalidPatch, pgErr.Message)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrBannerNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if patch.Version != nil && *patch.Version != version {
+		return ErrVersionMismatch
+	}
+
+	var banner entity.Banner
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&banner); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	banner.ID = patch.ID
+	if err := check(&banner); err != nil {
+		return err
+	}
+
+	sql, args, err = r.db.Builder.
+		Update("banners").
+		Set("tag_ids", banner.TagIDs).
+		Set("feature_id", banner.FeatureID).
+		Set("content", banner.Content).
+		Set("is_active", banner.IsActive).
+		Set("updated_at", time.Now().UTC()).
+		Set("version", version+1).
+		Where("id = ?", patch.ID).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetVersion отдает версию баннера из снимков журнала событий: в отличие от истории, он хранит каждую версию.
+// Снимки берутся после последнего создания баннера, ведь импорт с сохранением id может занять id удаленного.
+// Версии, записанные до появления снимков, ищутся в истории
+func (r *BannerRepository) GetVersion(ctx context.Context, id, version int32) (*entity.FilteredBanner, error) {
+	sql, args, err := r.db.Builder.
+		Select("after").
+		From("banner_events").
+		Where("banner_id = ?", id).
+		Where("version = ?", version).
+		Where("after IS NOT NULL").
+		Where("id >= (SELECT COALESCE(max(id), 0) FROM banner_events WHERE banner_id = ? AND type = 'created')", id).
+		OrderBy("id DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var banner entity.FilteredBanner
+	err = r.conn.QueryRow(ctx, sql, args...).Scan(&banner)
+	if err == nil {
+		return &banner, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	sql, args, err = r.db.Builder.
+		Select(bannerColumns...).
+		From("banners_history").
+		Where("id = ?", id).
+		Where("version = ?", version).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	found, err := scanBanner(r.conn.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrVersionNotFound
+	}
+	return found, err
+}
+
 func (r *BannerRepository) GetBannersHistoryByID(ctx context.Context, id int32) ([]*entity.FilteredBanner, error) {
 	sql, args, err := r.db.Builder.
-		Select("*").
+		Select(bannerColumns...).
 		From("banners_history").
 		Where("id = $1", id).
+		OrderBy("version DESC").
 		ToSql()
 	if err != nil {
 		return nil, err
 	}
-	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	rows, err := r.conn.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var banners []*entity.FilteredBanner
 	for rows.Next() {
-		var banner entity.FilteredBanner
-		if err := rows.Scan(&banner.ID, &banner.TagIDs, &banner.FeatureID, &banner.Content, &banner.IsActive, &banner.CreatedAt, &banner.UpdatedAt); err != nil {
+		banner, err := scanBanner(rows)
+		if err != nil {
 			return nil, err
 		}
-		banners = append(banners, &banner)
+		banners = append(banners, banner)
 	}
 	if err = rows.Err(); err != nil {
 		return nil, err
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"banner/internal/entity"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StreamBanners читает баннеры по тем же фильтрам, что и GetBannersWithOptionalFilters, но не держит
+// их в памяти: каждый баннер по порядку id передается в fn
+func (r *BannerRepository) StreamBanners(ctx context.Context, filter *entity.BannerFilter, fn func(*entity.FilteredBanner) error) error {
+	sql, args, err := filterBanners(r.db.Builder.Select(bannerColumns...).From("banners"), filter).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	rows, err := r.conn.Query(ctx, sql, args...)
+	if err != nil {
+		return filterError(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		banner, err := scanBanner(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(banner); err != nil {
+			return err
+		}
+	}
+	return filterError(rows.Err())
+}
+
+// FindConflicting возвращает id баннеров той же фичи с пересекающимися тегами
+func (r *BannerRepository) FindConflicting(ctx context.Context, featureID int32, tagIDs []int32) ([]int32, error) {
+	sql, args, err := r.db.Builder.
+		Select("id").
+		From("banners").
+		Where("feature_id = ?", featureID).
+		Where("tag_ids && ?", tagIDs).
+		Where("deleted_at IS NULL").
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int32])
+}
+
+// IDState проверяет, занят ли id, и находится ли баннер с этим id в корзине
+func (r *BannerRepository) IDState(ctx context.Context, id int32) (exists, deleted bool, err error) {
+	sql, args, err := r.db.Builder.
+		Select("deleted_at IS NOT NULL").
+		From("banners").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		return false, false, err
+	}
+	err = r.conn.QueryRow(ctx, sql, args...).Scan(&deleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, deleted, nil
+}
+
+// Insert вставляет баннер без проверки пересечения тегов. С preserveID баннер получает banner.ID,
+// после такой вставки нужно вызвать SyncIDSequence
+func (r *BannerRepository) Insert(ctx context.Context, banner *entity.Banner, preserveID bool) (int32, error) {
+	columns := []string{"tag_ids", "feature_id", "content", "is_active"}
+	values := []interface{}{banner.TagIDs, banner.FeatureID, banner.Content, banner.IsActive}
+	if preserveID {
+		columns = append(columns, "id")
+		values = append(values, banner.ID)
+	}
+	sql, args, err := r.db.Builder.
+		Insert("banners").
+		Columns(columns...).
+		Values(values...).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return -1, err
+	}
+	var id int32
+	err = r.withActor(ctx, func(c conn) error {
+		return c.QueryRow(ctx, sql, args...).Scan(&id)
+	})
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// SyncIDSequence сдвигает последовательность id за максимальный id, чтобы новые баннеры
+// не столкнулись с загруженными с сохранением id
+func (r *BannerRepository) SyncIDSequence(ctx context.Context) error {
+	_, err := r.conn.Exec(ctx, "SELECT setval(pg_get_serial_sequence('banners', 'id'), GREATEST((SELECT max(id) FROM banners), 1))")
+	return err
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"banner/internal/entity"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrRestoreConflict = errors.New("banner with same featureId and tagId already exists")
+
+// GetDeletedBanners отдает баннеры из корзины, недавно удаленные первыми
+func (r *BannerRepository) GetDeletedBanners(ctx context.Context, limit *int32, offset int32) ([]*entity.DeletedBanner, error) {
+	selectBuilder := r.db.Builder.
+		Select(append(bannerColumns, "deleted_at")...).
+		From("banners").
+		Where("deleted_at IS NOT NULL").
+		OrderBy("deleted_at DESC", "id DESC").
+		Offset(uint64(offset))
+	if limit != nil {
+		selectBuilder = selectBuilder.Limit(uint64(*limit))
+	}
+	sql, args, err := selectBuilder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var banners []*entity.DeletedBanner
+	for rows.Next() {
+		var banner entity.DeletedBanner
+		err := rows.Scan(&banner.ID, &banner.TagIDs, &banner.FeatureID, &banner.Content, &banner.IsActive, &banner.CreatedAt, &banner.UpdatedAt, &banner.Version, &banner.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		banners = append(banners, &banner)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return banners, nil
+}
+
+// RestoreBanner возвращает баннер из корзины. Если за это время фичу и теги занял другой баннер,
+// восстановление отклоняется с ErrRestoreConflict
+func (r *BannerRepository) RestoreBanner(ctx context.Context, id int32, expectedVersion *int32) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.db.Builder.
+		Select("tag_ids", "feature_id", "version").
+		From("banners").
+		Where("id = ?", id).
+		Where("deleted_at IS NOT NULL").
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	var (
+		tagIDs    []int32
+		featureID int32
+		version   int32
+	)
+	err = tx.QueryRow(ctx, sql, args...).Scan(&tagIDs, &featureID, &version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrBannerNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if expectedVersion != nil && *expectedVersion != version {
+		return ErrVersionMismatch
+	}
+
+	sql, args, err = r.db.Builder.
+		Select("COUNT(*)").
+		From("banners").
+		Where("feature_id = ?", featureID).
+		Where("tag_ids && ?", tagIDs).
+		Where("deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	var count int
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRestoreConflict
+	}
+
+	sql, args, err = r.db.Builder.
+		Update("banners").
+		Set("deleted_at", nil).
+		Set("updated_at", time.Now().UTC()).
+		Set("version", squirrel.Expr("version + 1")).
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// PurgeDeleted окончательно удаляет не больше batchSize баннеров, пролежавших в корзине дольше before,
+// вместе с их историей. Возвращает число удаленных баннеров
+func (r *BannerRepository) PurgeDeleted(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.db.Builder.
+		Delete("banners").
+		Where(squirrel.Expr("id IN (SELECT id FROM banners WHERE deleted_at < ? ORDER BY deleted_at LIMIT ? FOR UPDATE SKIP LOCKED)", before, batchSize)).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	sql, args, err = r.db.Builder.
+		Delete("banners_history").
+		Where("id = ANY(?)", ids).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), tx.Commit(ctx)
+}
+
+// DeleteBatch переносит в корзину не больше batchSize баннеров, подходящих под filter, и возвращает
+// фичи и теги удаленных баннеров. Строки, заблокированные другими транзакциями, пропускаются до следующей пачки
+func (r *BannerRepository) DeleteBatch(ctx context.Context, filter *entity.BannerFilter, batchSize int) ([]*entity.Banner, error) {
+	// подзапрос собирается с плейсхолдерами ?, нумерацию $n проставит внешний запрос
+	selectBuilder := filterBanners(squirrel.Select("id").From("banners"), filter).
+		OrderBy("id").
+		Limit(uint64(batchSize)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+	currentTime := time.Now().UTC()
+	sql, args, err := r.db.Builder.
+		Update("banners").
+		Set("deleted_at", currentTime).
+		Set("updated_at", currentTime).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Expr("id IN (?)", selectBuilder)).
+		Suffix("RETURNING tag_ids, feature_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var banners []*entity.Banner
+	err = r.withActor(ctx, func(c conn) error {
+		rows, err := c.Query(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+		banners, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Banner, error) {
+			var banner entity.Banner
+			err := row.Scan(&banner.TagIDs, &banner.FeatureID)
+			return &banner, err
+		})
+		return err
+	})
+	return banners, err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"banner/internal/entity"
+	"banner/pkg/db/postgres"
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// eventColumns - порядок колонок, в котором их читает scanEvent
+var eventColumns = []string{"id", "type", "banner_id", "version", "feature_id", "tag_ids", "previous_feature_id", "previous_tag_ids", "content", "is_active", "COALESCE(actor, '')", "before", "after", "created_at"}
+
+// scanEvent читает событие и вычисляет затронутые пары тег-фича до и после изменения
+func scanEvent(row rowScanner) (*entity.BannerEvent, error) {
+	var (
+		event             entity.BannerEvent
+		previousFeatureID *int32
+		previousTagIDs    []int32
+	)
+	err := row.Scan(&event.ID, &event.Type, &event.BannerID, &event.Version, &event.FeatureID, &event.TagIDs,
+		&previousFeatureID, &previousTagIDs, &event.Content, &event.IsActive, &event.Actor, &event.Before, &event.After, &event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	featureIDs, tagIDs := []int32{event.FeatureID}, [][]int32{event.TagIDs}
+	if previousFeatureID != nil {
+		featureIDs, tagIDs = append(featureIDs, *previousFeatureID), append(tagIDs, previousTagIDs)
+	}
+	event.Affected = entity.AffectedPairs(featureIDs, tagIDs)
+	return &event, nil
+}
+
+type EventRepository struct {
+	db *postgres.DB
+}
+
+func NewEventRepository(database *postgres.DB) *EventRepository {
+	return &EventRepository{
+		db: database,
+	}
+}
+
+// ReadEvents отдает не больше query.Limit событий после query.AfterID по возрастанию id.
+// Фильтр по фиче учитывает и фичу до изменения, чтобы потребитель узнал, что баннер ушел из фичи
+func (r *EventRepository) ReadEvents(ctx context.Context, query *entity.BannerEventsQuery) ([]*entity.BannerEvent, error) {
+	selectBuilder := r.db.Builder.
+		Select(eventColumns...).
+		From("banner_events").
+		Where("id > ?", query.AfterID).
+		OrderBy("id").
+		Limit(uint64(query.Limit))
+	if query.FeatureID != nil {
+		selectBuilder = selectBuilder.Where(squirrel.Or{
+			squirrel.Eq{"feature_id": *query.FeatureID},
+			squirrel.Eq{"previous_feature_id": *query.FeatureID},
+		})
+	}
+	sql, args, err := selectBuilder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.BannerEvent, error) {
+		return scanEvent(row)
+	})
+}
+
+// LastEventID - id последнего события, 0 - журнал пуст
+func (r *EventRepository) LastEventID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.db.Pool.QueryRow(ctx, "SELECT COALESCE(MAX(id), 0) FROM banner_events").Scan(&id)
+	return id, err
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"banner/internal/entity"
+	"banner/pkg/db/postgres"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrIdempotencyKeyNotFound = errors.New("no idempotency key found")
+
+type IdempotencyRepository struct {
+	db *postgres.DB
+}
+
+func NewIdempotencyRepository(database *postgres.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db: database,
+	}
+}
+
+// Reserve занимает ключ автора за запросом. Ключ, окно хранения которого истекло, но который еще не
+// удалила очистка, занимается заново. false - ключ уже занят другим запросом
+func (r *IdempotencyRepository) Reserve(ctx context.Context, actor, key, fingerprint string, expiresAt time.Time) (bool, error) {
+	sql, args, err := r.db.Builder.
+		Insert("idempotency_keys").
+		Columns("actor", "key", "fingerprint", "created_at", "expires_at").
+		Values(actor, key, fingerprint, time.Now().UTC(), expiresAt).
+		Suffix(`ON CONFLICT (actor, key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, status_code = NULL,
+			content_type = NULL, body = NULL, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at < EXCLUDED.created_at`).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+	result, err := r.db.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *IdempotencyRepository) Get(ctx context.Context, actor, key string) (*entity.IdempotencyKey, error) {
+	sql, args, err := r.db.Builder.
+		Select("actor", "key", "fingerprint", "status_code", "content_type", "body", "expires_at").
+		From("idempotency_keys").
+		Where("actor = ? AND key = ?", actor, key).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		idempotencyKey entity.IdempotencyKey
+		statusCode     *int32
+		contentType    *string
+		body           []byte
+	)
+	err = r.db.Pool.QueryRow(ctx, sql, args...).Scan(&idempotencyKey.Actor, &idempotencyKey.Key, &idempotencyKey.Fingerprint, &statusCode, &contentType, &body, &idempotencyKey.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if statusCode != nil {
+		idempotencyKey.Response = &entity.IdempotentResponse{StatusCode: int(*statusCode), Body: body}
+		if contentType != nil {
+			idempotencyKey.Response.ContentType = *contentType
+		}
+	}
+	return &idempotencyKey, nil
+}
+
+// Complete сохраняет ответ на запрос, занявший ключ
+func (r *IdempotencyRepository) Complete(ctx context.Context, actor, key string, response *entity.IdempotentResponse) error {
+	sql, args, err := r.db.Builder.
+		Update("idempotency_keys").
+		Set("status_code", response.StatusCode).
+		Set("content_type", response.ContentType).
+		Set("body", response.Body).
+		Where("actor = ? AND key = ?", actor, key).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrIdempotencyKeyNotFound
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Delete(ctx context.Context, actor, key string) error {
+	sql, args, err := r.db.Builder.
+		Delete("idempotency_keys").
+		Where("actor = ? AND key = ?", actor, key).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Pool.Exec(ctx, sql, args...)
+	return err
+}
+
+// DeleteExpired удаляет ключи, окно хранения которых истекло, и возвращает их количество
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	sql, args, err := r.db.Builder.
+		Delete("idempotency_keys").
+		Where("expires_at < ?", now).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+	result, err := r.db.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"banner/internal/entity"
+	"banner/pkg/db/postgres"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrJobNotFound = errors.New("no job found")
+	// ErrJobLost - задачу забрал другой воркер, пока этот считался пропавшим
+	ErrJobLost = errors.New("job is locked by another worker")
+)
+
+var jobColumns = []string{"id", "kind", "status", "params", "total", "processed", "attempts", "max_attempts", "COALESCE(error, '')", "run_at", "created_at", "updated_at", "finished_at"}
+
+type JobRepository struct {
+	db *postgres.DB
+}
+
+func NewJobRepository(database *postgres.DB) *JobRepository {
+	return &JobRepository{
+		db: database,
+	}
+}
+
+func scanJob(row rowScanner) (*entity.Job, error) {
+	var job entity.Job
+	err := row.Scan(&job.ID, &job.Kind, &job.Status, &job.Params, &job.Total, &job.Processed, &job.Attempts, &job.MaxAttempts, &job.Error, &job.RunAt, &job.CreatedAt, &job.UpdatedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Enqueue ставит задачу в очередь, total - ожидаемый объем работы
+func (r *JobRepository) Enqueue(ctx context.Context, kind entity.JobKind, params interface{}, total int64, maxAttempts int32) (*entity.Job, error) {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	currentTime := time.Now().UTC()
+	sql, args, err := r.db.Builder.
+		Insert("jobs").
+		Columns("kind", "status", "params", "total", "max_attempts", "run_at", "created_at", "updated_at").
+		Values(kind, entity.JobQueued, rawParams, total, maxAttempts, currentTime, currentTime, currentTime).
+		Suffix("RETURNING " + strings.Join(jobColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return scanJob(r.db.Pool.QueryRow(ctx, sql, args...))
+}
+
+func (r *JobRepository) Get(ctx context.Context, id int64) (*entity.Job, error) {
+	sql, args, err := r.db.Builder.
+		Select(jobColumns...).
+		From("jobs").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	job, err := scanJob(r.db.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	return job, err
+}
+
+// List отдает задачи, новые первыми
+func (r *JobRepository) List(ctx context.Context, query *entity.JobsQuery) ([]*entity.Job, error) {
+	selectBuilder := r.db.Builder.
+		Select(jobColumns...).
+		From("jobs").
+		OrderBy("created_at DESC", "id DESC").
+		Offset(uint64(query.Offset))
+	if query.Status != nil {
+		selectBuilder = selectBuilder.Where("status = ?", *query.Status)
+	}
+	if query.Kind != nil {
+		selectBuilder = selectBuilder.Where("kind = ?", *query.Kind)
+	}
+	if query.Limit != nil {
+		selectBuilder = selectBuilder.Limit(uint64(*query.Limit))
+	}
+	sql, args, err := selectBuilder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Job, error) {
+		return scanJob(row)
+	})
+}
+
+// Claim забирает у очереди одну задачу, готовую к выполнению, и отмечает ее за воркером worker.
+// Задачи, от воркера которых не было сигнала с staleBefore, считаются брошенными и тоже выдаются.
+// Параллельные воркеры не ждут друг друга благодаря SKIP LOCKED. Пустая очередь - ErrJobNotFound
+func (r *JobRepository) Claim(ctx context.Context, worker string, staleBefore time.Time) (*entity.Job, error) {
+	currentTime := time.Now().UTC()
+	// подзапрос собирается с плейсхолдерами ?, нумерацию $n проставит внешний запрос
+	next := squirrel.
+		Select("id").
+		From("jobs").
+		Where(squirrel.Or{
+			squirrel.And{squirrel.Eq{"status": entity.JobQueued}, squirrel.LtOrEq{"run_at": currentTime}},
+			squirrel.And{squirrel.Eq{"status": entity.JobRunning}, squirrel.Lt{"locked_at": staleBefore}},
+		}).
+		OrderBy("run_at", "id").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED")
+	sql, args, err := r.db.Builder.
+		Update("jobs").
+		Set("status", entity.JobRunning).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("locked_at", currentTime).
+		Set("locked_by", worker).
+		Set("updated_at", currentTime).
+		Where(squirrel.Expr("id = (?)", next)).
+		Suffix("RETURNING " + strings.Join(jobColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	job, err := scanJob(r.db.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	return job, err
+}
+
+// AddProgress увеличивает число обработанных элементов задачи. Заодно это сигнал, что воркер жив
+func (r *JobRepository) AddProgress(ctx context.Context, id int64, worker string, processed int64) error {
+	currentTime := time.Now().UTC()
+	return r.updateLocked(ctx, id, worker, r.db.Builder.
+		Update("jobs").
+		Set("processed", squirrel.Expr("processed + ?", processed)).
+		Set("locked_at", currentTime).
+		Set("updated_at", currentTime))
+}
+
+// Complete отмечает задачу выполненной
+func (r *JobRepository) Complete(ctx context.Context, id int64, worker string) error {
+	currentTime := time.Now().UTC()
+	return r.updateLocked(ctx, id, worker, r.db.Builder.
+		Update("jobs").
+		Set("status", entity.JobDone).
+		Set("error", nil).
+		Set("locked_at", nil).
+		Set("locked_by", nil).
+		Set("updated_at", currentTime).
+		Set("finished_at", currentTime))
+}
+
+// Retry возвращает задачу в очередь, следующая попытка будет не раньше runAt
+func (r *JobRepository) Retry(ctx context.Context, id int64, worker string, runAt time.Time, jobErr error) error {
+	return r.updateLocked(ctx, id, worker, r.db.Builder.
+		Update("jobs").
+		Set("status", entity.JobQueued).
+		Set("error", jobErr.Error()).
+		Set("run_at", runAt).
+		Set("locked_at", nil).
+		Set("locked_by", nil).
+		Set("updated_at", time.Now().UTC()))
+}
+
+// Fail отмечает задачу окончательно невыполненной
+func (r *JobRepository) Fail(ctx context.Context, id int64, worker string, jobErr error) error {
+	currentTime := time.Now().UTC()
+	return r.updateLocked(ctx, id, worker, r.db.Builder.
+		Update("jobs").
+		Set("status", entity.JobFailed).
+		Set("error", jobErr.Error()).
+		Set("locked_at", nil).
+		Set("locked_by", nil).
+		Set("updated_at", currentTime).
+		Set("finished_at", currentTime))
+}
+
+// updateLocked применяет изменение, только пока задача выполняется воркером worker
+func (r *JobRepository) updateLocked(ctx context.Context, id int64, worker string, updateBuilder squirrel.UpdateBuilder) error {
+	sql, args, err := updateBuilder.
+		Where("id = ?", id).
+		Where("status = ?", entity.JobRunning).
+		Where("locked_by = ?", worker).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrJobLost
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"banner/internal/entity"
+	"banner/pkg/db/postgres"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrSchemaNotFound = errors.New("no schema found")
+
+type SchemaRepository struct {
+	db *postgres.DB
+}
+
+func NewSchemaRepository(database *postgres.DB) *SchemaRepository {
+	return &SchemaRepository{
+		db: database,
+	}
+}
+
+// Save создает или заменяет схему содержимого для фичи
+func (r *SchemaRepository) Save(ctx context.Context, schema *entity.FeatureSchema) error {
+	currentTime := time.Now().UTC()
+	sql, args, err := r.db.Builder.
+		Insert("feature_schemas").
+		Columns("feature_id", "schema", "created_at", "updated_at").
+		Values(schema.FeatureID, schema.Schema, currentTime, currentTime).
+		Suffix("ON CONFLICT (feature_id) DO UPDATE SET schema = EXCLUDED.schema, updated_at = EXCLUDED.updated_at").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Pool.Exec(ctx, sql, args...)
+	return err
+}
+
+func (r *SchemaRepository) GetByFeatureID(ctx context.Context, featureID int32) (*entity.FeatureSchema, error) {
+	sql, args, err := r.db.Builder.
+		Select("feature_id", "schema", "created_at", "updated_at").
+		From("feature_schemas").
+		Where("feature_id = ?", featureID).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var schema entity.FeatureSchema
+	err = r.db.Pool.QueryRow(ctx, sql, args...).Scan(&schema.FeatureID, &schema.Schema, &schema.CreatedAt, &schema.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSchemaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (r *SchemaRepository) DeleteByFeatureID(ctx context.Context, featureID int32) error {
+	sql, args, err := r.db.Builder.
+		Delete("feature_schemas").
+		Where("feature_id = ?", featureID).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrSchemaNotFound
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"banner/internal/entity"
+	"banner/pkg/db/postgres"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("no webhook found")
+	ErrDeliveryNotFound = errors.New("no webhook delivery found")
+	// ErrDeliveryPending - доставка еще не завершена, повторно отправить можно только доставленную или брошенную
+	ErrDeliveryPending = errors.New("webhook delivery is pending")
+	// ErrDeliveryLost - доставку забрал другой воркер или она удалена вместе с подпиской
+	ErrDeliveryLost = errors.New("webhook delivery is locked by another worker")
+)
+
+var (
+	webhookColumns  = []string{"id", "url", "secret", "event_types", "feature_ids", "is_active", "created_at", "updated_at"}
+	deliveryColumns = []string{"id", "webhook_id", "event_id", "event_type", "status", "attempts", "next_attempt_at", "response_status", "COALESCE(error, '')", "created_at", "updated_at", "delivered_at"}
+)
+
+type WebhookRepository struct {
+	db *postgres.DB
+}
+
+func NewWebhookRepository(database *postgres.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: database,
+	}
+}
+
+func scanWebhook(row rowScanner) (*entity.Webhook, error) {
+	var (
+		webhook    entity.Webhook
+		eventTypes []string
+	)
+	err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &eventTypes, &webhook.FeatureIDs, &webhook.IsActive, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	webhook.EventTypes = make([]entity.BannerEventType, len(eventTypes))
+	for i, eventType := range eventTypes {
+		webhook.EventTypes[i] = entity.BannerEventType(eventType)
+	}
+	return &webhook, nil
+}
+
+func scanDelivery(row rowScanner) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt,
+		&delivery.ResponseStatus, &delivery.Error, &delivery.CreatedAt, &delivery.UpdatedAt, &delivery.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// eventTypes - типы событий в виде, который pgx передаст как text[]
+func eventTypes(types []entity.BannerEventType) []string {
+	result := make([]string, len(types))
+	for i, eventType := range types {
+		result[i] = string(eventType)
+	}
+	return result
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	currentTime := time.Now().UTC()
+	featureIDs := webhook.FeatureIDs
+	if featureIDs == nil {
+		featureIDs = []int32{}
+	}
+	sql, args, err := r.db.Builder.
+		Insert("webhooks").
+		Columns("url", "secret", "event_types", "feature_ids", "is_active", "created_at", "updated_at").
+		Values(webhook.URL, webhook.Secret, eventTypes(webhook.EventTypes), featureIDs, webhook.IsActive, currentTime, currentTime).
+		Suffix("RETURNING " + strings.Join(webhookColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return scanWebhook(r.db.Pool.QueryRow(ctx, sql, args...))
+}
+
+func (r *WebhookRepository) Get(ctx context.Context, id int32) (*entity.Webhook, error) {
+	sql, args, err := r.db.Builder.
+		Select(webhookColumns...).
+		From("webhooks").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	webhook, err := scanWebhook(r.db.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+func (r *WebhookRepository) List(ctx context.Context) ([]*entity.Webhook, error) {
+	sql, args, err := r.db.Builder.
+		Select(webhookColumns...).
+		From("webhooks").
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Webhook, error) {
+		return scanWebhook(row)
+	})
+}
+
+// Update меняет заданные поля подписки. Доставки, уже поставленные в очередь, отправляются на новый url
+func (r *WebhookRepository) Update(ctx context.Context, update *entity.WebhookUpdate) (*entity.Webhook, error) {
+	updateBuilder := r.db.Builder.
+		Update("webhooks").
+		Set("updated_at", time.Now().UTC()).
+		Where("id = ?", update.ID).
+		Suffix("RETURNING " + strings.Join(webhookColumns, ", "))
+	if update.URL != nil {
+		updateBuilder = updateBuilder.Set("url", *update.URL)
+	}
+	if update.Secret != nil {
+		updateBuilder = updateBuilder.Set("secret", *update.Secret)
+	}
+	if update.EventTypes != nil {
+		updateBuilder = updateBuilder.Set("event_types", eventTypes(*update.EventTypes))
+	}
+	if update.FeatureIDs != nil {
+		featureIDs := *update.FeatureIDs
+		if featureIDs == nil {
+			featureIDs = []int32{}
+		}
+		updateBuilder = updateBuilder.Set("feature_ids", featureIDs)
+	}
+	if update.IsActive != nil {
+		updateBuilder = updateBuilder.Set("is_active", *update.IsActive)
+	}
+	sql, args, err := updateBuilder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	webhook, err := scanWebhook(r.db.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+// Delete удаляет подписку вместе с журналом ее доставок
+func (r *WebhookRepository) Delete(ctx context.Context, id int32) error {
+	sql, args, err := r.db.Builder.
+		Delete("webhooks").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// Deliveries отдает журнал доставок подписки, новые первыми
+func (r *WebhookRepository) Deliveries(ctx context.Context, query *entity.WebhookDeliveriesQuery) ([]*entity.WebhookDelivery, error) {
+	selectBuilder := r.db.Builder.
+		Select(deliveryColumns...).
+		From("webhook_deliveries").
+		Where("webhook_id = ?", query.WebhookID).
+		OrderBy("id DESC").
+		Offset(uint64(query.Offset))
+	if query.Status != nil {
+		selectBuilder = selectBuilder.Where("status = ?", *query.Status)
+	}
+	if query.Limit != nil {
+		selectBuilder = selectBuilder.Limit(uint64(*query.Limit))
+	}
+	sql, args, err := selectBuilder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.WebhookDelivery, error) {
+		return scanDelivery(row)
+	})
+}
+
+// Redeliver возвращает доставленную или брошенную доставку в очередь с новым счетчиком попыток
+func (r *WebhookRepository) Redeliver(ctx context.Context, webhookID int32, id int64) (*entity.WebhookDelivery, error) {
+	currentTime := time.Now().UTC()
+	sql, args, err := r.db.Builder.
+		Update("webhook_deliveries").
+		Set("status", entity.DeliveryPending).
+		Set("attempts", 0).
+		Set("next_attempt_at", currentTime).
+		Set("delivered_at", nil).
+		Set("updated_at", currentTime).
+		Where("id = ?", id).
+		Where("webhook_id = ?", webhookID).
+		Where("status <> ?", entity.DeliveryPending).
+		Suffix("RETURNING " + strings.Join(deliveryColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := scanDelivery(r.db.Pool.QueryRow(ctx, sql, args...))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return delivery, err
+	}
+	var status entity.DeliveryStatus
+	err = r.db.Pool.QueryRow(ctx, "SELECT status FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2", id, webhookID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, ErrDeliveryPending
+}
+
+// Event - событие журнала изменений, которое отправляет доставка
+func (r *WebhookRepository) Event(ctx context.Context, id int64) (*entity.BannerEvent, error) {
+	sql, args, err := r.db.Builder.
+		Select(eventColumns...).
+		From("banner_events").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return scanEvent(r.db.Pool.QueryRow(ctx, sql, args...))
+}
+
+// ClaimDelivery забирает одну доставку активной подписки, которой пора уйти, и отмечает ее за воркером worker.
+// Доставки, взятые раньше staleBefore, считаются брошенными и тоже выдаются. Пустая очередь - ErrDeliveryNotFound
+func (r *WebhookRepository) ClaimDelivery(ctx context.Context, worker string, staleBefore time.Time) (*entity.WebhookDelivery, error) {
+	currentTime := time.Now().UTC()
+	// подзапрос собирается с плейсхолдерами ?, нумерацию $n проставит внешний запрос
+	next := squirrel.
+		Select("id").
+		From("webhook_deliveries").
+		Where(squirrel.Eq{"status": entity.DeliveryPending}).
+		Where(squirrel.LtOrEq{"next_attempt_at": currentTime}).
+		Where(squirrel.Or{squirrel.Eq{"locked_at": nil}, squirrel.Lt{"locked_at": staleBefore}}).
+		Where("webhook_id IN (SELECT id FROM webhooks WHERE is_active)").
+		OrderBy("next_attempt_at", "id").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED")
+	sql, args, err := r.db.Builder.
+		Update("webhook_deliveries").
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("locked_at", currentTime).
+		Set("locked_by", worker).
+		Set("updated_at", currentTime).
+		Where(squirrel.Expr("id = (?)", next)).
+		Suffix("RETURNING " + strings.Join(deliveryColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := scanDelivery(r.db.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryNotFound
+	}
+	return delivery, err
+}
+
+// CompleteDelivery отмечает доставку успешной
+func (r *WebhookRepository) CompleteDelivery(ctx context.Context, id int64, worker string, responseStatus *int32) error {
+	currentTime := time.Now().UTC()
+	return r.updateLocked(ctx, id, worker, r.db.Builder.
+		Update("webhook_deliveries").
+		Set("status", entity.DeliveryDelivered).
+		Set("response_status", responseStatus).
+		Set("error", nil).
+		Set("locked_at", nil).
+		Set("locked_by", nil).
+		Set("updated_at", currentTime).
+		Set("delivered_at", currentTime))
+}
+
+// RetryDelivery оставляет доставку в очереди, следующая попытка будет не раньше nextAttemptAt
+func (r *WebhookRepository) RetryDelivery(ctx context.Context, id int64, worker string, nextAttemptAt time.Time, responseStatus *int32, deliveryErr error) error {
+	return r.updateLocked(ctx, id, worker, r.db.Builder.
+		Update("webhook_deliveries").
+		Set("response_status", responseStatus).
+		Set("error", deliveryErr.Error()).
+		Set("next_attempt_at", nextAttemptAt).
+		Set("locked_at", nil).
+		Set("locked_by", nil).
+		Set("updated_at", time.Now().UTC()))
+}
+
+// DeadDelivery убирает доставку из очереди после последней неудачной попытки
+func (r *WebhookRepository) DeadDelivery(ctx context.Context, id int64, worker string, responseStatus *int32, deliveryErr error) error {
+	return r.updateLocked(ctx, id, worker, r.db.Builder.
+		Update("webhook_deliveries").
+		Set("status", entity.DeliveryDead).
+		Set("response_status", responseStatus).
+		Set("error", deliveryErr.Error()).
+		Set("locked_at", nil).
+		Set("locked_by", nil).
+		Set("updated_at", time.Now().UTC()))
+}
+
+// updateLocked применяет изменение, только пока доставка отмечена за воркером worker
+func (r *WebhookRepository) updateLocked(ctx context.Context, id int64, worker string, updateBuilder squirrel.UpdateBuilder) error {
+	sql, args, err := updateBuilder.
+		Where("id = ?", id).
+		Where("status = ?", entity.DeliveryPending).
+		Where("locked_by = ?", worker).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDeliveryLost
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/pkg/jsondiff"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// auditColumns - колонки CSV аудита; diff записывается в виде JSON
+var auditColumns = []string{"id", "event_id", "banner_id", "action", "actor", "client_ip", "request_id", "diff", "created_at"}
+
+// AuditService читает аудит изменений баннеров
+type AuditService struct {
+	auditRepository *repository.AuditRepository
+}
+
+func NewAuditService(auditRepository *repository.AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepository: auditRepository,
+	}
+}
+
+func (s *AuditService) List(ctx context.Context, query *entity.AuditQuery) ([]*entity.AuditEntry, error) {
+	entries, err := s.auditRepository.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		entry.Diff = auditDiff(entry)
+	}
+	return entries, nil
+}
+
+// Export построчно пишет в w записи аудита в CSV
+func (s *AuditService) Export(ctx context.Context, w io.Writer, query *entity.AuditQuery) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(auditColumns); err != nil {
+		return err
+	}
+	err := s.auditRepository.StreamAudit(ctx, query, func(entry *entity.AuditEntry) error {
+		diff, err := json.Marshal(auditDiff(entry))
+		if err != nil {
+			return err
+		}
+		return writer.Write([]string{
+			strconv.FormatInt(entry.ID, 10),
+			strconv.FormatInt(entry.EventID, 10),
+			strconv.Itoa(int(entry.BannerID)),
+			string(entry.Action),
+			entry.Actor,
+			entry.ClientIP,
+			entry.RequestID,
+			string(diff),
+			entry.CreatedAt.Format(time.RFC3339Nano),
+		})
+	})
+	writer.Flush()
+	if err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// auditDiff сравнивает снимки баннера до и после изменения так же, как GET /banner/:id/diff сравнивает
+// содержимое: JSON Patch по всему баннеру, кроме id и version. Отсутствующий снимок - пустой баннер,
+// поэтому у созданного баннера содержимое раскладывается по ключам
+func auditDiff(entry *entity.AuditEntry) []entity.AuditChange {
+	changes := jsondiff.Changes(auditDocument(entry.Before), auditDocument(entry.After))
+	diff := make([]entity.AuditChange, len(changes))
+	for i, change := range changes {
+		diff[i] = entity.AuditChange{Path: change.Path, From: change.From, To: change.To}
+	}
+	return diff
+}
+
+func auditDocument(snapshot map[string]interface{}) map[string]interface{} {
+	document := map[string]interface{}{"content": map[string]interface{}{}}
+	for key, value := range snapshot {
+		// null и отсутствующее значение не различаются: у баннера не в корзине deleted_at - null
+		if key != "id" && key != "version" && value != nil {
+			document[key] = value
+		}
+	}
+	return document
+}
@@ -5,43 +5,152 @@ import (
 	"banner/internal/repository"
 	"banner/pkg/cache"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"time"
+
+	"github.com/go-playground/validator/v10"
 )
 
 type BannerService struct {
 	bannerRepository *repository.BannerRepository
+	schemas          *SchemaService
 	cache            *cache.MemoryCache
 	cacheTTL         time.Duration
+	validate         *validator.Validate
 }
 
-func NewBannerService(bannerRepository *repository.BannerRepository, memoryCache *cache.MemoryCache, cacheTTL time.Duration) *BannerService {
-	return &BannerService{bannerRepository: bannerRepository, cache: memoryCache, cacheTTL: cacheTTL}
+func NewBannerService(bannerRepository *repository.BannerRepository, schemas *SchemaService, memoryCache *cache.MemoryCache, cacheTTL time.Duration) *BannerService {
+	return &BannerService{bannerRepository: bannerRepository, schemas: schemas, cache: memoryCache, cacheTTL: cacheTTL, validate: entity.NewValidator()}
 }
 func (s *BannerService) Save(ctx context.Context, banner *entity.Banner) (int32, error) {
+	if err := s.schemas.ValidateContent(ctx, banner.FeatureID, banner.Content); err != nil {
+		return -1, err
+	}
 	return s.bannerRepository.Save(ctx, banner)
 }
-func (s *BannerService) GetForUser(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (map[string]interface{}, error) {
+
+// GetForUser отдает баннер из кэша или, если кэш пуст или нужна последняя ревизия, из бд.
+// ETag - хэш содержимого, он хранится в кэше вместе с баннером
+func (s *BannerService) GetForUser(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (*entity.UserBanner, error) {
 	if !lastRevision {
-		if value, err := s.cache.Get(tagID, featureID); err == nil {
-			return value, nil
+		if item, err := s.cache.GetItem(tagID, featureID); err == nil {
+			return &entity.UserBanner{Content: item.Value, ETag: item.Tag, MaxAge: time.Until(item.Expiry)}, nil
 		}
 	}
 	content, err := s.bannerRepository.GetBannerByTagsAndFeatureIDForUser(ctx, tagID, featureID, isActiveParam)
 	if err != nil {
-		return content, err
+		return nil, err
+	}
+	etag, err := contentETag(content)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.SetTagged(tagID, featureID, content, etag, s.cacheTTL)
+	banner := &entity.UserBanner{Content: content, ETag: etag, MaxAge: s.cacheTTL}
+	if lastRevision {
+		banner.MaxAge = 0
 	}
-	s.cache.Set(tagID, featureID, content, s.cacheTTL)
-	return content, err
+	return banner, nil
 }
-func (s *BannerService) GetBanners(ctx context.Context, featureID, tagID, limit *int32, offset int32) ([]*entity.FilteredBanner, error) {
-	return s.bannerRepository.GetBannersWithOptionalFilters(ctx, featureID, tagID, limit, offset)
+
+// contentETag - стабильный хэш содержимого: encoding/json сериализует ключи объектов по порядку
+func contentETag(content map[string]interface{}) (string, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
 }
-func (s *BannerService) Delete(ctx context.Context, id int32) error {
-	return s.bannerRepository.DeleteByID(ctx, id)
+
+// defaultPageSize - размер страницы в режиме курсора, если limit не задан
+const defaultPageSize = 100
+
+// GetBanners отдает страницу баннеров. В режиме курсора запрашивается на один баннер больше,
+// чтобы узнать, есть ли следующая страница, и считается общее число баннеров под фильтрами
+func (s *BannerService) GetBanners(ctx context.Context, query *entity.BannersQuery) (*entity.BannersPage, error) {
+	if !query.Keyset {
+		banners, err := s.bannerRepository.GetBannersWithOptionalFilters(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return &entity.BannersPage{Banners: banners}, nil
+	}
+	limit := int32(defaultPageSize)
+	if query.Limit != nil && *query.Limit > 0 {
+		limit = *query.Limit
+	}
+	fetch := *query
+	fetchLimit := limit + 1
+	fetch.Limit = &fetchLimit
+	banners, err := s.bannerRepository.GetBannersWithOptionalFilters(ctx, &fetch)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.bannerRepository.CountBanners(ctx, &query.BannerFilter)
+	if err != nil {
+		return nil, err
+	}
+	page := &entity.BannersPage{Banners: banners, Total: total}
+	if len(banners) > int(limit) {
+		page.Banners = banners[:limit]
+		page.NextCursor = entity.CursorAfter(page.Banners[limit-1], query.SortBy, query.Order).Encode()
+	}
+	if page.Banners == nil {
+		page.Banners = []*entity.FilteredBanner{}
+	}
+	return page, nil
+}
+func (s *BannerService) Get(ctx context.Context, id int32) (*entity.FilteredBanner, error) {
+	return s.bannerRepository.GetByID(ctx, id)
+}
+func (s *BannerService) Delete(ctx context.Context, id int32, version *int32) error {
+	return s.bannerRepository.DeleteByID(ctx, id, version)
 }
 func (s *BannerService) Update(ctx context.Context, banner *entity.BannerUpdate) error {
+	if banner.Content != nil || banner.FeatureID != nil {
+		if err := s.validateUpdate(ctx, banner); err != nil {
+			return err
+		}
+	}
 	return s.bannerRepository.UpdateBanner(ctx, banner)
 }
+
+// validateUpdate проверяет содержимое, которое получится после частичного обновления:
+// недостающие фича или содержимое берутся из текущей версии баннера
+func (s *BannerService) validateUpdate(ctx context.Context, banner *entity.BannerUpdate) error {
+	if banner.Content != nil && banner.FeatureID != nil {
+		return s.schemas.ValidateContent(ctx, *banner.FeatureID, *banner.Content)
+	}
+	current, err := s.bannerRepository.GetByID(ctx, *banner.ID)
+	if err != nil {
+		return err
+	}
+	featureID, content := current.FeatureID, current.Content
+	if banner.FeatureID != nil {
+		featureID = *banner.FeatureID
+	}
+	if banner.Content != nil {
+		content = *banner.Content
+	}
+	return s.schemas.ValidateContent(ctx, featureID, content)
+}
+
+// Patch применяет JSON Patch или JSON Merge Patch к баннеру. Результат проверяется теми же правилами,
+// что и баннер при создании, и схемой содержимого фичи
+func (s *BannerService) Patch(ctx context.Context, patch *entity.BannerPatch) error {
+	return s.bannerRepository.PatchBanner(ctx, patch, func(banner *entity.Banner) error {
+		if err := s.validate.Struct(banner); err != nil {
+			return err
+		}
+		return s.schemas.ValidateContent(ctx, banner.FeatureID, banner.Content)
+	})
+}
+func (s *BannerService) Rollback(ctx context.Context, id, version int32, expectedVersion *int32) error {
+	return s.bannerRepository.RollbackBanner(ctx, id, version, expectedVersion)
+}
 func (s *BannerService) GetBannersHistoryByID(ctx context.Context, id int32) ([]*entity.BannerHistoryItem, error) {
 	banners, err := s.bannerRepository.GetBannersHistoryByID(ctx, id)
 	if err != nil {
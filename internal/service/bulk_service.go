@@ -0,0 +1,122 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/pkg/cache"
+	"context"
+	"errors"
+)
+
+// errBulkAborted прерывает транзакцию атомарного пакета после первой неудачной операции
+var errBulkAborted = errors.New("bulk operation aborted")
+
+// Bulk выполняет пакет операций. В атомарном режиме все операции выполняются в одной транзакции
+// и отменяются при первой ошибке, иначе каждая операция выполняется независимо.
+// Кэш затронутых пар тег-фича сбрасывается один раз после выполнения пакета
+func (s *BannerService) Bulk(ctx context.Context, request *entity.BulkRequest) ([]*entity.BulkResult, error) {
+	results := make([]*entity.BulkResult, len(request.Operations))
+	for i, op := range request.Operations {
+		results[i] = &entity.BulkResult{Index: i, Action: op.Action, ID: op.ID, Status: entity.BulkSkipped}
+	}
+	affected := make(map[cache.Key]struct{})
+
+	if request.Mode == entity.BulkBestEffort {
+		for i := range request.Operations {
+			_ = s.applyBulk(ctx, &request.Operations[i], results[i], affected)
+		}
+		s.invalidate(affected)
+		return results, nil
+	}
+
+	err := s.bannerRepository.InTx(ctx, func(repo *repository.BannerRepository) error {
+		tx := *s
+		tx.bannerRepository = repo
+		for i := range request.Operations {
+			if err := tx.applyBulk(ctx, &request.Operations[i], results[i], affected); err != nil {
+				return errBulkAborted
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errBulkAborted) {
+		for _, result := range results {
+			if result.Status == entity.BulkOK {
+				result.Status = entity.BulkRolledBack
+			}
+		}
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(affected)
+	return results, nil
+}
+
+// applyBulk выполняет операцию и записывает ее итог и затронутые ключи кэша
+func (s *BannerService) applyBulk(ctx context.Context, op *entity.BulkOperation, result *entity.BulkResult, affected map[cache.Key]struct{}) error {
+	id, touched, err := s.applyOperation(ctx, op)
+	if err != nil {
+		result.Status = entity.BulkFailed
+		result.Err = err
+		return err
+	}
+	result.Status = entity.BulkOK
+	result.ID = &id
+	for _, banner := range touched {
+		for _, tagID := range banner.TagIDs {
+			affected[cache.Key{Part1: tagID, Part2: banner.FeatureID}] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// applyOperation возвращает id баннера и его состояния до и после операции - по ним сбрасывается кэш
+func (s *BannerService) applyOperation(ctx context.Context, op *entity.BulkOperation) (int32, []*entity.Banner, error) {
+	if op.Action == entity.BulkCreate {
+		id, err := s.Save(ctx, op.Banner)
+		return id, []*entity.Banner{op.Banner}, err
+	}
+	before, err := s.bannerRepository.GetByID(ctx, *op.ID)
+	if err != nil {
+		return *op.ID, nil, err
+	}
+	touched := []*entity.Banner{{TagIDs: before.TagIDs, FeatureID: before.FeatureID}}
+	if op.Action == entity.BulkDelete {
+		return *op.ID, touched, s.Delete(ctx, *op.ID, op.Version)
+	}
+
+	update := &entity.BannerUpdate{}
+	switch op.Action {
+	case entity.BulkUpdate:
+		*update = *op.Update
+	case entity.BulkActivate, entity.BulkDeactivate:
+		isActive := op.Action == entity.BulkActivate
+		update.IsActive = &isActive
+	}
+	update.ID = op.ID
+	update.Version = op.Version
+	if err := s.Update(ctx, update); err != nil {
+		return *op.ID, nil, err
+	}
+	after := &entity.Banner{TagIDs: before.TagIDs, FeatureID: before.FeatureID}
+	if update.TagIDs != nil {
+		after.TagIDs = *update.TagIDs
+	}
+	if update.FeatureID != nil {
+		after.FeatureID = *update.FeatureID
+	}
+	return *op.ID, append(touched, after), nil
+}
+
+func (s *BannerService) invalidate(keys map[cache.Key]struct{}) {
+	if len(keys) == 0 {
+		return
+	}
+	list := make([]cache.Key, 0, len(keys))
+	for key := range keys {
+		list = append(list, key)
+	}
+	s.cache.Delete(list...)
+}
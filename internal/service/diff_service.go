@@ -0,0 +1,86 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/pkg/jsondiff"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Diff сравнивает версии from и to баннера id, to = nil - текущая версия. Прошлые версии восстанавливаются
+// из снимков журнала событий, поэтому сравнить можно любую версию, а не только оставшиеся в истории.
+// При unified в ответ добавляется построчный diff версий
+func (s *BannerService) Diff(ctx context.Context, id, from int32, to *int32, unified bool) (*entity.BannerDiff, error) {
+	current, err := s.bannerRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	version := func(v int32) (*entity.FilteredBanner, error) {
+		if v == current.Version {
+			return current, nil
+		}
+		return s.bannerRepository.GetVersion(ctx, id, v)
+	}
+	fromBanner, err := version(from)
+	if err != nil {
+		return nil, err
+	}
+	toBanner := current
+	if to != nil {
+		if toBanner, err = version(*to); err != nil {
+			return nil, err
+		}
+	}
+	diff := diffBanners(fromBanner, toBanner)
+	if !unified {
+		return diff, nil
+	}
+	diff.Unified, err = jsondiff.Unified(
+		fmt.Sprintf("banner %d version %d", id, fromBanner.Version),
+		fmt.Sprintf("banner %d version %d", id, toBanner.Version),
+		diffView(fromBanner), diffView(toBanner))
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+func diffBanners(from, to *entity.FilteredBanner) *entity.BannerDiff {
+	diff := &entity.BannerDiff{
+		ID:      to.ID,
+		From:    from.Version,
+		To:      to.Version,
+		TagIDs:  entity.TagIDsDiff{Added: missingTags(to.TagIDs, from.TagIDs), Removed: missingTags(from.TagIDs, to.TagIDs)},
+		Content: jsondiff.Patch(from.Content, to.Content),
+	}
+	if from.FeatureID != to.FeatureID {
+		diff.FeatureID = &entity.FeatureIDChange{From: from.FeatureID, To: to.FeatureID}
+	}
+	if from.IsActive != to.IsActive {
+		diff.IsActive = &entity.IsActiveChange{From: from.IsActive, To: to.IsActive}
+	}
+	return diff
+}
+
+// missingTags - теги из tagIDs, которых нет в other, по возрастанию
+func missingTags(tagIDs, other []int32) []int32 {
+	missing := []int32{}
+	for _, tagID := range tagIDs {
+		if !slices.Contains(other, tagID) && !slices.Contains(missing, tagID) {
+			missing = append(missing, tagID)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// diffView - поля версии, которые сравнивает построчный diff; version и даты отличаются всегда
+func diffView(banner *entity.FilteredBanner) map[string]interface{} {
+	return map[string]interface{}{
+		"tag_ids":    banner.TagIDs,
+		"feature_id": banner.FeatureID,
+		"is_active":  banner.IsActive,
+		"content":    banner.Content,
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/pkg/jsondiff"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrUnknownActor - черновики требуют известного автора и ревьюера, иначе правило ревью не проверить
+	ErrUnknownActor = errors.New("draft workflow requires a known actor")
+	// ErrNotDraftAuthor - менять, отправлять на ревью и отменять черновик может только автор
+	ErrNotDraftAuthor = errors.New("only the author can change the draft")
+	ErrSelfApproval   = errors.New("author cannot approve own draft")
+	// ErrDraftState - действие недоступно на текущем этапе ревью черновика
+	ErrDraftState = errors.New("action is not allowed in current draft status")
+	// ErrDraftOutdated - баннер изменился после того, как от него сделали черновик
+	ErrDraftOutdated = errors.New("banner has changed since the draft was created")
+)
+
+// CreateDraft делает черновик от текущей версии баннера: незаданные в changes поля берутся из баннера.
+// Автор черновика - автор изменения из ctx
+func (s *BannerService) CreateDraft(ctx context.Context, bannerID int32, changes *entity.BannerUpdate) (*entity.BannerDraft, error) {
+	author := entity.ActorFromContext(ctx)
+	if author == "" {
+		return nil, ErrUnknownActor
+	}
+	current, err := s.bannerRepository.GetByID(ctx, bannerID)
+	if err != nil {
+		return nil, err
+	}
+	draft := &entity.BannerDraft{
+		BannerID:    bannerID,
+		BaseVersion: current.Version,
+		TagIDs:      current.TagIDs,
+		FeatureID:   current.FeatureID,
+		Content:     current.Content,
+		IsActive:    current.IsActive,
+		Author:      author,
+	}
+	draft.Apply(changes)
+	if err := s.validateDraft(ctx, draft); err != nil {
+		return nil, err
+	}
+	return s.bannerRepository.CreateDraft(ctx, draft)
+}
+
+func (s *BannerService) GetDraft(ctx context.Context, id int64) (*entity.BannerDraft, error) {
+	return s.bannerRepository.GetDraft(ctx, id)
+}
+
+func (s *BannerService) Drafts(ctx context.Context, query *entity.DraftsQuery) ([]*entity.BannerDraft, error) {
+	return s.bannerRepository.Drafts(ctx, query)
+}
+
+// UpdateDraft меняет черновик автора. Отклоненный черновик после правки снова становится черновиком
+func (s *BannerService) UpdateDraft(ctx context.Context, id int64, changes *entity.BannerUpdate) (*entity.BannerDraft, error) {
+	return s.changeDraft(ctx, id, func(repo *repository.BannerRepository, draft *entity.BannerDraft) error {
+		if err := checkAuthor(ctx, draft); err != nil {
+			return err
+		}
+		if draft.Status != entity.DraftOpen && draft.Status != entity.DraftRejected {
+			return ErrDraftState
+		}
+		draft.Apply(changes)
+		draft.Status = entity.DraftOpen
+		return s.validateDraft(ctx, draft)
+	})
+}
+
+// SubmitDraft отправляет черновик автора на ревью, до решения черновик не меняется
+func (s *BannerService) SubmitDraft(ctx context.Context, id int64) (*entity.BannerDraft, error) {
+	return s.changeDraft(ctx, id, func(repo *repository.BannerRepository, draft *entity.BannerDraft) error {
+		if err := checkAuthor(ctx, draft); err != nil {
+			return err
+		}
+		if draft.Status != entity.DraftOpen && draft.Status != entity.DraftRejected {
+			return ErrDraftState
+		}
+		now := time.Now().UTC()
+		draft.Status = entity.DraftPending
+		draft.SubmittedAt = &now
+		return nil
+	})
+}
+
+// ApproveDraft одобряет черновик на ревью и в той же транзакции публикует его новой версией баннера.
+// Ревьюер - автор изменения из ctx, он не может совпадать с автором черновика. Если баннер изменился
+// после создания черновика, публикация отменяется с ErrDraftOutdated: черновик нужно сделать заново
+func (s *BannerService) ApproveDraft(ctx context.Context, id int64, comment string) (*entity.BannerDraft, error) {
+	reviewer := entity.ActorFromContext(ctx)
+	if reviewer == "" {
+		return nil, ErrUnknownActor
+	}
+	return s.changeDraft(ctx, id, func(repo *repository.BannerRepository, draft *entity.BannerDraft) error {
+		if draft.Status != entity.DraftPending {
+			return ErrDraftState
+		}
+		if draft.Author == reviewer {
+			return ErrSelfApproval
+		}
+		// схема фичи могла измениться, пока черновик ждал ревью
+		if err := s.validateDraft(ctx, draft); err != nil {
+			return err
+		}
+		err := repo.UpdateBanner(ctx, &entity.BannerUpdate{
+			ID:        &draft.BannerID,
+			TagIDs:    &draft.TagIDs,
+			FeatureID: &draft.FeatureID,
+			Content:   &draft.Content,
+			IsActive:  &draft.IsActive,
+			Version:   &draft.BaseVersion,
+		})
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return ErrDraftOutdated
+		}
+		if err != nil {
+			return err
+		}
+		published := draft.BaseVersion + 1
+		review(draft, entity.DraftApproved, reviewer, comment)
+		draft.PublishedVersion = &published
+		return nil
+	})
+}
+
+// RejectDraft возвращает черновик автору с комментарием ревьюера
+func (s *BannerService) RejectDraft(ctx context.Context, id int64, comment string) (*entity.BannerDraft, error) {
+	reviewer := entity.ActorFromContext(ctx)
+	if reviewer == "" {
+		return nil, ErrUnknownActor
+	}
+	return s.changeDraft(ctx, id, func(repo *repository.BannerRepository, draft *entity.BannerDraft) error {
+		if draft.Status != entity.DraftPending {
+			return ErrDraftState
+		}
+		review(draft, entity.DraftRejected, reviewer, comment)
+		return nil
+	})
+}
+
+// DiscardDraft отменяет неопубликованный черновик автора, после этого от баннера можно сделать новый
+func (s *BannerService) DiscardDraft(ctx context.Context, id int64) (*entity.BannerDraft, error) {
+	return s.changeDraft(ctx, id, func(repo *repository.BannerRepository, draft *entity.BannerDraft) error {
+		if err := checkAuthor(ctx, draft); err != nil {
+			return err
+		}
+		if draft.Status == entity.DraftApproved || draft.Status == entity.DraftDiscarded {
+			return ErrDraftState
+		}
+		draft.Status = entity.DraftDiscarded
+		return nil
+	})
+}
+
+// DraftDiff сравнивает опубликованную версию баннера с черновиком: To - версия, которой станет черновик
+// после публикации
+func (s *BannerService) DraftDiff(ctx context.Context, id int64, unified bool) (*entity.BannerDiff, error) {
+	draft, err := s.bannerRepository.GetDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.bannerRepository.GetByID(ctx, draft.BannerID)
+	if err != nil {
+		return nil, err
+	}
+	proposed := &entity.FilteredBanner{
+		ID:        draft.BannerID,
+		TagIDs:    draft.TagIDs,
+		FeatureID: draft.FeatureID,
+		Content:   draft.Content,
+		IsActive:  draft.IsActive,
+		Version:   current.Version + 1,
+	}
+	diff := diffBanners(current, proposed)
+	if !unified {
+		return diff, nil
+	}
+	diff.Unified, err = jsondiff.Unified(
+		fmt.Sprintf("banner %d version %d", draft.BannerID, current.Version),
+		fmt.Sprintf("banner %d draft %d", draft.BannerID, draft.ID),
+		diffView(current), diffView(proposed))
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// changeDraft блокирует черновик, меняет его через fn и сохраняет в одной транзакции
+func (s *BannerService) changeDraft(ctx context.Context, id int64, fn func(repo *repository.BannerRepository, draft *entity.BannerDraft) error) (*entity.BannerDraft, error) {
+	var changed *entity.BannerDraft
+	err := s.bannerRepository.InTx(ctx, func(repo *repository.BannerRepository) error {
+		draft, err := repo.LockDraft(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := fn(repo, draft); err != nil {
+			return err
+		}
+		changed, err = repo.SaveDraft(ctx, draft)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// validateDraft проверяет черновик теми же правилами, что и баннер при создании, и схемой содержимого фичи
+func (s *BannerService) validateDraft(ctx context.Context, draft *entity.BannerDraft) error {
+	if err := s.validate.Struct(draft.Banner()); err != nil {
+		return err
+	}
+	return s.schemas.ValidateContent(ctx, draft.FeatureID, draft.Content)
+}
+
+// review записывает решение ревьюера
+func review(draft *entity.BannerDraft, status entity.DraftStatus, reviewer, comment string) {
+	now := time.Now().UTC()
+	draft.Status = status
+	draft.Reviewer = reviewer
+	draft.Comment = comment
+	draft.ReviewedAt = &now
+}
+
+func checkAuthor(ctx context.Context, draft *entity.BannerDraft) error {
+	if entity.ActorFromContext(ctx) != draft.Author {
+		return ErrNotDraftAuthor
+	}
+	return nil
+}
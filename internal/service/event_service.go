@@ -0,0 +1,29 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"context"
+)
+
+// EventService читает журнал изменений баннеров
+type EventService struct {
+	eventRepository *repository.EventRepository
+}
+
+func NewEventService(eventRepository *repository.EventRepository) *EventService {
+	return &EventService{
+		eventRepository: eventRepository,
+	}
+}
+
+// ReadEvents читает журнал по порядку после query.AfterID. Потребитель внутри сервиса, например репликация,
+// запоминает id последнего обработанного события и продолжает с него
+func (s *EventService) ReadEvents(ctx context.Context, query *entity.BannerEventsQuery) ([]*entity.BannerEvent, error) {
+	return s.eventRepository.ReadEvents(ctx, query)
+}
+
+// LastEventID - id последнего события, с него начинает поток подписчик без Last-Event-ID
+func (s *EventService) LastEventID(ctx context.Context) (int64, error) {
+	return s.eventRepository.LastEventID(ctx)
+}
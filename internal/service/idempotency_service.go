@@ -0,0 +1,64 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key reused with different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with idempotency key is in progress")
+)
+
+type IdempotencyService struct {
+	idempotencyRepository *repository.IdempotencyRepository
+	ttl                   time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepository *repository.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{idempotencyRepository: idempotencyRepository, ttl: ttl}
+}
+
+// Begin занимает ключ за запросом. Ключи действуют в пределах автора изменения из ctx, поэтому
+// одинаковые ключи разных клиентов не пересекаются. Если ключ уже использовался с тем же запросом,
+// возвращает сохраненный ответ, с другим запросом - ErrIdempotencyKeyReused. (nil, nil) - запрос нужно выполнить
+func (s *IdempotencyService) Begin(ctx context.Context, key, fingerprint string) (*entity.IdempotentResponse, error) {
+	actor := entity.ActorFromContext(ctx)
+	reserved, err := s.idempotencyRepository.Reserve(ctx, actor, key, fingerprint, time.Now().UTC().Add(s.ttl))
+	if err != nil || reserved {
+		return nil, err
+	}
+	existing, err := s.idempotencyRepository.Get(ctx, actor, key)
+	if errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+		// ключ освободили между попытками, пусть клиент повторит запрос
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+	if existing.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if existing.Response == nil {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return existing.Response, nil
+}
+
+// Complete сохраняет ответ для повторов запроса с тем же ключом
+func (s *IdempotencyService) Complete(ctx context.Context, key string, response *entity.IdempotentResponse) error {
+	return s.idempotencyRepository.Complete(ctx, entity.ActorFromContext(ctx), key, response)
+}
+
+// Abort освобождает ключ, если запрос не удалось выполнить и его можно повторить
+func (s *IdempotencyService) Abort(ctx context.Context, key string) error {
+	return s.idempotencyRepository.Delete(ctx, entity.ActorFromContext(ctx), key)
+}
+
+// Sweep удаляет ключи, окно хранения которых истекло. Вызывается периодически, а не на каждый запрос
+func (s *IdempotencyService) Sweep(ctx context.Context) (int64, error) {
+	return s.idempotencyRepository.DeleteExpired(ctx, time.Now().UTC())
+}
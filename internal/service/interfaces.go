@@ -3,13 +3,74 @@ package service
 import (
 	"banner/internal/entity"
 	"context"
+	"io"
 )
 
 type Service interface {
 	Save(ctx context.Context, banner *entity.Banner) (int32, error)
-	GetForUser(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (map[string]interface{}, error)
-	GetBanners(ctx context.Context, featureID, tagID, limit *int32, offset int32) ([]*entity.FilteredBanner, error)
-	Delete(ctx context.Context, id int32) error
+	GetForUser(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (*entity.UserBanner, error)
+	GetBanners(ctx context.Context, query *entity.BannersQuery) (*entity.BannersPage, error)
+	Get(ctx context.Context, id int32) (*entity.FilteredBanner, error)
+	Delete(ctx context.Context, id int32, version *int32) error
 	Update(ctx context.Context, banner *entity.BannerUpdate) error
+	Patch(ctx context.Context, patch *entity.BannerPatch) error
 	GetBannersHistoryByID(ctx context.Context, i int32) ([]*entity.BannerHistoryItem, error)
+	Rollback(ctx context.Context, id, version int32, expectedVersion *int32) error
+	Diff(ctx context.Context, id, from int32, to *int32, unified bool) (*entity.BannerDiff, error)
+	Trash(ctx context.Context, limit *int32, offset int32) ([]*entity.DeletedBanner, error)
+	Restore(ctx context.Context, id int32, expectedVersion *int32) error
+	Bulk(ctx context.Context, request *entity.BulkRequest) ([]*entity.BulkResult, error)
+	Export(ctx context.Context, w io.Writer, format entity.TransferFormat, filter *entity.BannerFilter) error
+	Import(ctx context.Context, r io.Reader, options *entity.ImportOptions) (*entity.ImportReport, error)
+}
+
+type SchemaRegistry interface {
+	Save(ctx context.Context, schema *entity.FeatureSchema) error
+	Get(ctx context.Context, featureID int32) (*entity.FeatureSchema, error)
+	Delete(ctx context.Context, featureID int32) error
+	Report(ctx context.Context, featureID int32) ([]*entity.SchemaReportItem, error)
+}
+
+type IdempotencyStore interface {
+	Begin(ctx context.Context, key, fingerprint string) (*entity.IdempotentResponse, error)
+	Complete(ctx context.Context, key string, response *entity.IdempotentResponse) error
+	Abort(ctx context.Context, key string) error
+}
+
+type JobQueue interface {
+	EnqueueDeleteBanners(ctx context.Context, params *entity.DeleteBannersParams) (*entity.Job, error)
+	Get(ctx context.Context, id int64) (*entity.Job, error)
+	List(ctx context.Context, query *entity.JobsQuery) ([]*entity.Job, error)
+}
+
+type EventLog interface {
+	ReadEvents(ctx context.Context, query *entity.BannerEventsQuery) ([]*entity.BannerEvent, error)
+	LastEventID(ctx context.Context) (int64, error)
+}
+
+type DraftWorkflow interface {
+	CreateDraft(ctx context.Context, bannerID int32, changes *entity.BannerUpdate) (*entity.BannerDraft, error)
+	GetDraft(ctx context.Context, id int64) (*entity.BannerDraft, error)
+	Drafts(ctx context.Context, query *entity.DraftsQuery) ([]*entity.BannerDraft, error)
+	UpdateDraft(ctx context.Context, id int64, changes *entity.BannerUpdate) (*entity.BannerDraft, error)
+	SubmitDraft(ctx context.Context, id int64) (*entity.BannerDraft, error)
+	ApproveDraft(ctx context.Context, id int64, comment string) (*entity.BannerDraft, error)
+	RejectDraft(ctx context.Context, id int64, comment string) (*entity.BannerDraft, error)
+	DiscardDraft(ctx context.Context, id int64) (*entity.BannerDraft, error)
+	DraftDiff(ctx context.Context, id int64, unified bool) (*entity.BannerDiff, error)
+}
+
+type AuditLog interface {
+	List(ctx context.Context, query *entity.AuditQuery) ([]*entity.AuditEntry, error)
+	Export(ctx context.Context, w io.Writer, query *entity.AuditQuery) error
+}
+
+type WebhookRegistry interface {
+	Create(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error)
+	Get(ctx context.Context, id int32) (*entity.Webhook, error)
+	List(ctx context.Context) ([]*entity.Webhook, error)
+	Update(ctx context.Context, update *entity.WebhookUpdate) (*entity.Webhook, error)
+	Delete(ctx context.Context, id int32) error
+	Deliveries(ctx context.Context, query *entity.WebhookDeliveriesQuery) ([]*entity.WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID int32, id int64) (*entity.WebhookDelivery, error)
 }
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/pkg/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// deleteBatchSize - сколько баннеров массовое удаление переносит в корзину за одну транзакцию
+const deleteBatchSize = 100
+
+var (
+	// ErrJobPermanent - ошибка, которую не исправит повторная попытка, например некорректные параметры
+	ErrJobPermanent   = errors.New("job cannot be completed")
+	ErrUnknownJobKind = errors.New("unknown job kind")
+)
+
+// JobHandler выполняет задачу. progress увеличивает число обработанных элементов и сообщает очереди,
+// что воркер жив. Задача может выполняться повторно, поэтому обработчик должен быть идемпотентным.
+// ctx отменяется, если задача не успела завершиться при остановке сервиса
+type JobHandler func(ctx context.Context, job *entity.Job, progress func(processed int64) error) error
+
+// JobOptions - параметры воркеров очереди задач
+type JobOptions struct {
+	// Workers - сколько задач выполняется одновременно
+	Workers int
+	// PollInterval - как часто свободный воркер проверяет пустую очередь
+	PollInterval time.Duration
+	MaxAttempts  int32
+	// RetryBackoff - задержка перед второй попыткой, дальше она удваивается до MaxBackoff
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	// LockTimeout - через сколько без сигнала от воркера задача считается брошенной и выдается снова
+	LockTimeout time.Duration
+	// DrainTimeout - сколько при остановке ждать завершения выполняющихся задач
+	DrainTimeout time.Duration
+}
+
+type JobService struct {
+	jobRepository *repository.JobRepository
+	banners       *BannerService
+	l             logger.Logger
+	options       JobOptions
+	handlers      map[entity.JobKind]JobHandler
+	worker        string
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+func NewJobService(jobRepository *repository.JobRepository, banners *BannerService, l logger.Logger, options JobOptions) *JobService {
+	hostname, _ := os.Hostname()
+	s := &JobService{
+		jobRepository: jobRepository,
+		banners:       banners,
+		l:             l,
+		options:       options,
+		handlers:      make(map[entity.JobKind]JobHandler),
+		worker:        hostname + ":" + strconv.Itoa(os.Getpid()),
+	}
+	s.Register(entity.JobDeleteBanners, s.deleteBanners)
+	return s
+}
+
+// Register задает обработчик задач вида kind. Регистрировать обработчики нужно до Start
+func (s *JobService) Register(kind entity.JobKind, handler JobHandler) {
+	s.handlers[kind] = handler
+}
+
+// Enqueue ставит задачу в очередь, ее выполнит первый свободный воркер любой реплики
+func (s *JobService) Enqueue(ctx context.Context, kind entity.JobKind, params interface{}, total int64) (*entity.Job, error) {
+	if _, ok := s.handlers[kind]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJobKind, kind)
+	}
+	return s.jobRepository.Enqueue(ctx, kind, params, total, s.options.MaxAttempts)
+}
+
+// EnqueueDeleteBanners ставит в очередь массовое удаление баннеров по фиче и/или тегу
+func (s *JobService) EnqueueDeleteBanners(ctx context.Context, params *entity.DeleteBannersParams) (*entity.Job, error) {
+	total, err := s.banners.bannerRepository.CountBanners(ctx, deleteBannersFilter(params))
+	if err != nil {
+		return nil, err
+	}
+	return s.Enqueue(ctx, entity.JobDeleteBanners, params, total)
+}
+
+func (s *JobService) Get(ctx context.Context, id int64) (*entity.Job, error) {
+	return s.jobRepository.Get(ctx, id)
+}
+
+func (s *JobService) List(ctx context.Context, query *entity.JobsQuery) ([]*entity.Job, error) {
+	jobs, err := s.jobRepository.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []*entity.Job{}
+	}
+	return jobs, nil
+}
+
+// Start запускает воркеры очереди
+func (s *JobService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.stop = make(chan struct{})
+	for i := 0; i < s.options.Workers; i++ {
+		s.running.Add(1)
+		go s.work(ctx, s.stop)
+	}
+}
+
+// Stop перестает брать новые задачи и ждет выполняющиеся не дольше DrainTimeout. Незавершенные
+// задачи отменяются и возвращаются в очередь, их продолжит другая реплика или следующий запуск
+func (s *JobService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	drained := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(s.options.DrainTimeout):
+		s.l.Warn("Job queue drain timed out, cancelling running jobs")
+		s.cancel()
+		<-drained
+	}
+	s.cancel()
+	s.stop = nil
+}
+
+// work забирает задачи, пока очередь не остановят. Пустую очередь воркер проверяет раз в PollInterval
+func (s *JobService) work(ctx context.Context, stop <-chan struct{}) {
+	defer s.running.Done()
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		job, err := s.jobRepository.Claim(ctx, s.worker, time.Now().UTC().Add(-s.options.LockTimeout))
+		if err == nil {
+			s.execute(ctx, job)
+			continue
+		}
+		if !errors.Is(err, repository.ErrJobNotFound) {
+			s.l.Error("service - JobService - work - Claim: %v", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(s.options.PollInterval):
+		}
+	}
+}
+
+// execute выполняет задачу и записывает результат: успех, повтор с задержкой или окончательную ошибку
+func (s *JobService) execute(ctx context.Context, job *entity.Job) {
+	// результат пишется даже после отмены ctx, иначе задача останется за остановленным воркером
+	store := context.WithoutCancel(ctx)
+	err := s.handle(ctx, job)
+	switch {
+	case err == nil:
+		err = s.jobRepository.Complete(store, job.ID, s.worker)
+	case errors.Is(err, repository.ErrJobLost):
+		s.l.Warn("Job %d was taken over by another worker", job.ID)
+		return
+	case ctx.Err() != nil:
+		s.l.Info("Job %d interrupted, returning it to the queue", job.ID)
+		err = s.jobRepository.Retry(store, job.ID, s.worker, time.Now().UTC(), err)
+	case errors.Is(err, ErrJobPermanent) || job.Attempts >= job.MaxAttempts:
+		s.l.Error("Job %d failed: %v", job.ID, err)
+		err = s.jobRepository.Fail(store, job.ID, s.worker, err)
+	default:
+		delay := backoff(job.Attempts, s.options.RetryBackoff, s.options.MaxBackoff)
+		s.l.Warn("Job %d attempt %d failed, retrying in %s: %v", job.ID, job.Attempts, delay, err)
+		err = s.jobRepository.Retry(store, job.ID, s.worker, time.Now().UTC().Add(delay), err)
+	}
+	if err != nil {
+		s.l.Error("service - JobService - execute: %v", err)
+	}
+}
+
+func (s *JobService) handle(ctx context.Context, job *entity.Job) error {
+	if job.Attempts > job.MaxAttempts {
+		// воркер пропал на последней попытке
+		return fmt.Errorf("%w: attempts exhausted", ErrJobPermanent)
+	}
+	handler, ok := s.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("%w: %w %s", ErrJobPermanent, ErrUnknownJobKind, job.Kind)
+	}
+	// изменения задачи записываются в журнал от ее имени
+	ctx = entity.WithActor(ctx, "job:"+strconv.FormatInt(job.ID, 10))
+	return handler(ctx, job, func(processed int64) error {
+		return s.jobRepository.AddProgress(ctx, job.ID, s.worker, processed)
+	})
+}
+
+// backoff - задержка после attempt-й неудачной попытки: base, затем вдвое больше, но не больше maxDelay
+func backoff(attempt int32, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := int32(1); i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+func deleteBannersFilter(params *entity.DeleteBannersParams) *entity.BannerFilter {
+	return &entity.BannerFilter{FeatureID: params.FeatureID, TagID: params.TagID}
+}
+
+// deleteBanners удаляет баннеры пачками, после каждой пачки обновляет прогресс задачи. Повторная попытка
+// продолжает с того места, где остановилась предыдущая: удаленные баннеры под фильтр уже не попадают.
+// Пустая пачка еще не значит, что удалять нечего: DeleteBatch пропускает строки, заблокированные другими
+// транзакциями, поэтому задача завершается, только когда под фильтр не попадает ни одного баннера
+func (s *JobService) deleteBanners(ctx context.Context, job *entity.Job, progress func(processed int64) error) error {
+	var params entity.DeleteBannersParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return fmt.Errorf("%w: %w", ErrJobPermanent, err)
+	}
+	filter := deleteBannersFilter(&params)
+	for {
+		deleted, err := s.banners.DeleteBatch(ctx, filter, deleteBatchSize)
+		if err != nil {
+			return fmt.Errorf("delete batch: %w", err)
+		}
+		if deleted == 0 {
+			remaining, err := s.banners.bannerRepository.CountBanners(ctx, filter)
+			if err != nil {
+				return fmt.Errorf("count banners: %w", err)
+			}
+			if remaining == 0 {
+				return nil
+			}
+			// оставшиеся баннеры заблокированы, ждем, пока их отпустят
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.options.PollInterval):
+			}
+			continue
+		}
+		if err := progress(int64(deleted)); err != nil {
+			return fmt.Errorf("update progress: %w", err)
+		}
+	}
+}
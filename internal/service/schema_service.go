@@ -0,0 +1,186 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var ErrInvalidSchema = errors.New("invalid json schema")
+
+type SchemaService struct {
+	schemaRepository *repository.SchemaRepository
+	bannerRepository *repository.BannerRepository
+
+	mu       sync.RWMutex
+	compiled map[int32]*compiledSchema
+}
+
+// compiledSchema - скомпилированная схема фичи и версия схемы, из которой она получена.
+// Версия - время последнего сохранения схемы: если схему заменили на другой реплике, версия в бд
+// не совпадет с кэшированной и схема скомпилируется заново
+type compiledSchema struct {
+	version time.Time
+	schema  *jsonschema.Schema
+}
+
+func NewSchemaService(schemaRepository *repository.SchemaRepository, bannerRepository *repository.BannerRepository) *SchemaService {
+	return &SchemaService{
+		schemaRepository: schemaRepository,
+		bannerRepository: bannerRepository,
+		compiled:         make(map[int32]*compiledSchema),
+	}
+}
+
+// Save сохраняет схему фичи, предварительно проверив, что она компилируется
+func (s *SchemaService) Save(ctx context.Context, schema *entity.FeatureSchema) error {
+	if _, err := compileSchema(schema); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	defer s.invalidate(schema.FeatureID)
+	return s.schemaRepository.Save(ctx, schema)
+}
+func (s *SchemaService) Get(ctx context.Context, featureID int32) (*entity.FeatureSchema, error) {
+	return s.schemaRepository.GetByFeatureID(ctx, featureID)
+}
+func (s *SchemaService) Delete(ctx context.Context, featureID int32) error {
+	defer s.invalidate(featureID)
+	return s.schemaRepository.DeleteByFeatureID(ctx, featureID)
+}
+
+// ValidateContent проверяет содержимое баннера по схеме фичи. Если схема для фичи
+// не зарегистрирована, допускается любое содержимое
+func (s *SchemaService) ValidateContent(ctx context.Context, featureID int32, content map[string]interface{}) error {
+	schema, err := s.schemaRepository.GetByFeatureID(ctx, featureID)
+	if errors.Is(err, repository.ErrSchemaNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	compiled, err := s.compile(schema)
+	if err != nil {
+		return err
+	}
+	return validateContent(compiled, featureID, content)
+}
+
+// Report проверяет все существующие баннеры фичи по ее текущей схеме, ничего не изменяя
+func (s *SchemaService) Report(ctx context.Context, featureID int32) ([]*entity.SchemaReportItem, error) {
+	schema, err := s.schemaRepository.GetByFeatureID(ctx, featureID)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := s.compile(schema)
+	if err != nil {
+		return nil, err
+	}
+	banners, err := s.bannerRepository.GetBannersWithOptionalFilters(ctx, &entity.BannersQuery{BannerFilter: entity.BannerFilter{FeatureID: &featureID}})
+	if err != nil {
+		return nil, err
+	}
+	report := make([]*entity.SchemaReportItem, 0, len(banners))
+	for _, banner := range banners {
+		item := &entity.SchemaReportItem{BannerID: banner.ID, FeatureID: banner.FeatureID, Valid: true}
+		var validationErr *entity.ContentValidationError
+		if err := validateContent(compiled, featureID, banner.Content); errors.As(err, &validationErr) {
+			item.Valid = false
+			item.Violations = validationErr.Violations
+		} else if err != nil {
+			return nil, err
+		}
+		report = append(report, item)
+	}
+	return report, nil
+}
+
+// compile отдает скомпилированную схему из кэша, если она получена из той же версии схемы
+func (s *SchemaService) compile(schema *entity.FeatureSchema) (*jsonschema.Schema, error) {
+	s.mu.RLock()
+	cached, ok := s.compiled[schema.FeatureID]
+	s.mu.RUnlock()
+	if ok && cached.version.Equal(schema.UpdatedAt) {
+		return cached.schema, nil
+	}
+	compiled, err := compileSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.compiled[schema.FeatureID] = &compiledSchema{version: schema.UpdatedAt, schema: compiled}
+	s.mu.Unlock()
+	return compiled, nil
+}
+
+func (s *SchemaService) invalidate(featureID int32) {
+	s.mu.Lock()
+	delete(s.compiled, featureID)
+	s.mu.Unlock()
+}
+
+func compileSchema(schema *entity.FeatureSchema) (*jsonschema.Schema, error) {
+	raw, err := json.Marshal(schema.Schema)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("mem://feature/%d/schema.json", schema.FeatureID)
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+	compiler.LoadURL = rejectSchemaURL
+	if err := compiler.AddResource(url, bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(url)
+}
+
+// rejectSchemaURL не дает схеме ссылаться на внешние документы: загрузчик по умолчанию читает локальные
+// файлы и ходит по сети. Схема фичи добавлена в компилятор целиком, ссылки внутри нее загрузчик не вызывают
+func rejectSchemaURL(url string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("external reference %s is not allowed", url)
+}
+
+func validateContent(schema *jsonschema.Schema, featureID int32, content map[string]interface{}) error {
+	// схема проверяет значения в том виде, в каком их возвращает encoding/json,
+	// поэтому содержимое, полученное из бд, прогоняем через сериализацию
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+	err = schema.Validate(value)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	violations := collectViolations(validationErr, nil)
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Pointer < violations[j].Pointer })
+	return &entity.ContentValidationError{FeatureID: featureID, Violations: violations}
+}
+
+// collectViolations собирает листовые ошибки валидации - именно они указывают на конкретные значения
+func collectViolations(err *jsonschema.ValidationError, violations []entity.ContentViolation) []entity.ContentViolation {
+	if len(err.Causes) == 0 {
+		pointer := err.InstanceLocation
+		if pointer == "" {
+			pointer = "/"
+		}
+		return append(violations, entity.ContentViolation{Pointer: pointer, Message: err.Message})
+	}
+	for _, cause := range err.Causes {
+		violations = collectViolations(cause, violations)
+	}
+	return violations
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/pkg/cache"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrUnknownFormat  = errors.New("unknown transfer format")
+	ErrImportConflict = errors.New("imported banner conflicts with existing one")
+	ErrInvalidImport  = errors.New("invalid import file")
+	// errDryRun отменяет транзакцию пробной загрузки
+	errDryRun = errors.New("dry run")
+)
+
+// exportColumns - колонки CSV; tag_ids и content записываются в виде JSON
+var exportColumns = []string{"id", "tag_ids", "feature_id", "content", "is_active", "created_at", "updated_at", "version"}
+
+// Export построчно пишет в w баннеры, подходящие под фильтры
+func (s *BannerService) Export(ctx context.Context, w io.Writer, format entity.TransferFormat, filter *entity.BannerFilter) error {
+	switch format {
+	case entity.FormatJSONL:
+		encoder := json.NewEncoder(w)
+		return s.bannerRepository.StreamBanners(ctx, filter, func(banner *entity.FilteredBanner) error {
+			return encoder.Encode(banner)
+		})
+	case entity.FormatCSV:
+		writer := csv.NewWriter(w)
+		// заголовок пишется вместе с первой строкой: если запрос не выполнился, в w ничего не попадет
+		// и вызывающий еще сможет ответить ошибкой
+		headerWritten := false
+		writeHeader := func() error {
+			if headerWritten {
+				return nil
+			}
+			headerWritten = true
+			return writer.Write(exportColumns)
+		}
+		err := s.bannerRepository.StreamBanners(ctx, filter, func(banner *entity.FilteredBanner) error {
+			if err := writeHeader(); err != nil {
+				return err
+			}
+			record, err := csvRecord(banner)
+			if err != nil {
+				return err
+			}
+			return writer.Write(record)
+		})
+		if err == nil {
+			err = writeHeader()
+		}
+		writer.Flush()
+		if err != nil {
+			return err
+		}
+		return writer.Error()
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+func csvRecord(banner *entity.FilteredBanner) ([]string, error) {
+	tagIDs, err := json.Marshal(banner.TagIDs)
+	if err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(banner.Content)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		strconv.Itoa(int(banner.ID)),
+		string(tagIDs),
+		strconv.Itoa(int(banner.FeatureID)),
+		string(content),
+		strconv.FormatBool(banner.IsActive),
+		banner.CreatedAt.Format(time.RFC3339Nano),
+		banner.UpdatedAt.Format(time.RFC3339Nano),
+		strconv.Itoa(int(banner.Version)),
+	}, nil
+}
+
+// importRow - строка загрузки: баннер или ошибка его разбора
+type importRow struct {
+	line   int
+	banner *entity.Banner
+	err    error
+}
+
+// Import загружает баннеры в одной транзакции. Некорректные строки пропускаются и попадают в отчет,
+// конфликты обрабатываются по options.Conflict; при ConflictFail первый конфликт отменяет всю загрузку.
+// Баннер, уже загруженный из другой строки файла, второй раз не меняется: история версий не различает
+// два изменения баннера в одной транзакции. При DryRun транзакция всегда отменяется
+func (s *BannerService) Import(ctx context.Context, r io.Reader, options *entity.ImportOptions) (*entity.ImportReport, error) {
+	next, err := newImportReader(r, options.Format)
+	if err != nil {
+		return nil, err
+	}
+	report := &entity.ImportReport{DryRun: options.DryRun, Rejected: []entity.ImportRejection{}}
+	affected := make(map[cache.Key]struct{})
+	// imported - строка файла, из которой загружен баннер с этим id
+	imported := make(map[int32]int)
+	err = s.bannerRepository.InTx(ctx, func(repo *repository.BannerRepository) error {
+		tx := *s
+		tx.bannerRepository = repo
+		// строки читаются по одной, загрузка не держит файл в памяти целиком
+		for {
+			row, err := next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			report.Total++
+			if err := tx.importRow(ctx, row, options, report, affected, imported); err != nil {
+				return err
+			}
+		}
+		if options.PreserveIDs {
+			if err := repo.SyncIDSequence(ctx); err != nil {
+				return err
+			}
+		}
+		if options.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		return report, nil
+	}
+	if err != nil {
+		return report, err
+	}
+	s.invalidate(affected)
+	return report, nil
+}
+
+func (s *BannerService) importRow(ctx context.Context, row importRow, options *entity.ImportOptions, report *entity.ImportReport, affected map[cache.Key]struct{}, imported map[int32]int) error {
+	reject := func(reason string, err error) {
+		rejection := entity.ImportRejection{Line: row.line, Reason: reason, Error: err.Error()}
+		if row.banner != nil {
+			rejection.ID = row.banner.ID
+		}
+		report.Rejected = append(report.Rejected, rejection)
+	}
+	banner := row.banner
+	if row.err != nil {
+		reject(entity.RejectInvalid, row.err)
+		return nil
+	}
+	if err := s.validate.Struct(banner); err != nil {
+		reject(entity.RejectInvalid, err)
+		return nil
+	}
+	if options.PreserveIDs && banner.ID <= 0 {
+		reject(entity.RejectInvalid, errors.New("id is required to preserve ids"))
+		return nil
+	}
+	if line, ok := imported[banner.ID]; ok && options.PreserveIDs {
+		reject(entity.RejectDuplicate, fmt.Errorf("banner %d is already imported from line %d", banner.ID, line))
+		return nil
+	}
+	if err := s.schemas.ValidateContent(ctx, banner.FeatureID, banner.Content); err != nil {
+		var validationErr *entity.ContentValidationError
+		if !errors.As(err, &validationErr) {
+			return err
+		}
+		reject(entity.RejectInvalid, err)
+		return nil
+	}
+
+	target, conflicts, err := s.importTarget(ctx, banner, options)
+	if err != nil {
+		return err
+	}
+	if target == nil && len(conflicts) == 0 {
+		id, err := s.bannerRepository.Insert(ctx, banner, options.PreserveIDs)
+		if err != nil {
+			return err
+		}
+		imported[id] = row.line
+		report.Created++
+		addCacheKeys(affected, banner.TagIDs, banner.FeatureID)
+		return nil
+	}
+
+	conflictErr := fmt.Errorf("%w: line %d conflicts with banners %v", ErrImportConflict, row.line, append(conflicts, targetIDs(target)...))
+	switch {
+	case options.Conflict == entity.ConflictFail:
+		return conflictErr
+	case options.Conflict == entity.ConflictSkip, len(conflicts) > 0:
+		// перезаписать можно только один баннер, пересечение с остальными оставляем как есть
+		report.Skipped++
+		reject(entity.RejectConflict, conflictErr)
+		return nil
+	}
+	if line, ok := imported[*target]; ok {
+		reject(entity.RejectDuplicate, fmt.Errorf("banner %d is already imported from line %d", *target, line))
+		return nil
+	}
+	before, err := s.bannerRepository.GetByID(ctx, *target)
+	if err != nil {
+		return err
+	}
+	update := &entity.BannerUpdate{ID: target, TagIDs: &banner.TagIDs, FeatureID: &banner.FeatureID, Content: &banner.Content, IsActive: &banner.IsActive}
+	if err := s.bannerRepository.UpdateBanner(ctx, update); err != nil {
+		return err
+	}
+	imported[*target] = row.line
+	report.Updated++
+	addCacheKeys(affected, before.TagIDs, before.FeatureID)
+	addCacheKeys(affected, banner.TagIDs, banner.FeatureID)
+	return nil
+}
+
+// importTarget находит баннер, который загружаемый может перезаписать, и остальные баннеры, с которыми он конфликтует.
+// С сохранением id перезаписывается баннер с тем же id, иначе - единственный баннер с той же фичей и тегами
+func (s *BannerService) importTarget(ctx context.Context, banner *entity.Banner, options *entity.ImportOptions) (*int32, []int32, error) {
+	var (
+		target    *int32
+		conflicts []int32
+	)
+	if options.PreserveIDs {
+		exists, deleted, err := s.bannerRepository.IDState(ctx, banner.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case exists && deleted:
+			// id занят баннером в корзине, перезаписать его нельзя
+			conflicts = append(conflicts, banner.ID)
+		case exists:
+			target = &banner.ID
+		}
+	}
+	ids, err := s.bannerRepository.FindConflicting(ctx, banner.FeatureID, banner.TagIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, id := range ids {
+		if target == nil || id != *target {
+			conflicts = append(conflicts, id)
+		}
+	}
+	if !options.PreserveIDs && len(conflicts) == 1 {
+		return &conflicts[0], nil, nil
+	}
+	return target, conflicts, nil
+}
+
+func targetIDs(target *int32) []int32 {
+	if target == nil {
+		return nil
+	}
+	return []int32{*target}
+}
+
+func addCacheKeys(affected map[cache.Key]struct{}, tagIDs []int32, featureID int32) {
+	for _, tagID := range tagIDs {
+		affected[cache.Key{Part1: tagID, Part2: featureID}] = struct{}{}
+	}
+}
+
+// newImportReader возвращает функцию, которая читает следующую строку загрузки и io.EOF в конце файла.
+// Ошибки разбора отдельных строк сохраняются в самих строках, ошибка чтения прерывает загрузку
+func newImportReader(r io.Reader, format entity.TransferFormat) (func() (importRow, error), error) {
+	switch format {
+	case entity.FormatJSONL:
+		return newJSONLReader(r), nil
+	case entity.FormatCSV:
+		return newCSVReader(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func newJSONLReader(r io.Reader) func() (importRow, error) {
+	reader := bufio.NewReader(r)
+	line := 0
+	return func() (importRow, error) {
+		for {
+			data, err := reader.ReadBytes('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				return importRow{}, err
+			}
+			line++
+			if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 {
+				var banner entity.Banner
+				decodeErr := json.Unmarshal(trimmed, &banner)
+				return importRow{line: line, banner: &banner, err: decodeErr}, nil
+			}
+			if err != nil {
+				return importRow{}, err
+			}
+		}
+	}
+}
+
+func newCSVReader(r io.Reader) (func() (importRow, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return func() (importRow, error) { return importRow{}, io.EOF }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: csv header: %v", ErrInvalidImport, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{"tag_ids", "feature_id", "content", "is_active"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: csv: missing column %q", ErrInvalidImport, name)
+		}
+	}
+	line := 1
+	return func() (importRow, error) {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return importRow{}, io.EOF
+		}
+		line++
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return importRow{line: line, err: err}, nil
+		}
+		if err != nil {
+			return importRow{}, err
+		}
+		banner, err := bannerFromCSV(record, columns)
+		return importRow{line: line, banner: banner, err: err}, nil
+	}, nil
+}
+
+func bannerFromCSV(record []string, columns map[string]int) (*entity.Banner, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	var banner entity.Banner
+	if raw := field("id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("id: %w", err)
+		}
+		banner.ID = int32(id)
+	}
+	if err := json.Unmarshal([]byte(field("tag_ids")), &banner.TagIDs); err != nil {
+		return &banner, fmt.Errorf("tag_ids: %w", err)
+	}
+	featureID, err := strconv.ParseInt(field("feature_id"), 10, 32)
+	if err != nil {
+		return &banner, fmt.Errorf("feature_id: %w", err)
+	}
+	banner.FeatureID = int32(featureID)
+	if err := json.Unmarshal([]byte(field("content")), &banner.Content); err != nil {
+		return &banner, fmt.Errorf("content: %w", err)
+	}
+	banner.IsActive, err = strconv.ParseBool(field("is_active"))
+	if err != nil {
+		return &banner, fmt.Errorf("is_active: %w", err)
+	}
+	return &banner, nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/pkg/cache"
+	"context"
+	"time"
+)
+
+// purgeBatchSize - сколько баннеров удаляется из корзины за одну транзакцию
+const purgeBatchSize = 500
+
+// Trash возвращает баннеры из корзины, недавно удаленные первыми
+func (s *BannerService) Trash(ctx context.Context, limit *int32, offset int32) ([]*entity.DeletedBanner, error) {
+	banners, err := s.bannerRepository.GetDeletedBanners(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if banners == nil {
+		banners = []*entity.DeletedBanner{}
+	}
+	return banners, nil
+}
+
+// Restore возвращает баннер из корзины, если его фичу и теги не занял другой баннер
+func (s *BannerService) Restore(ctx context.Context, id int32, expectedVersion *int32) error {
+	return s.bannerRepository.RestoreBanner(ctx, id, expectedVersion)
+}
+
+// PurgeDeleted окончательно удаляет баннеры, пролежавшие в корзине дольше retention.
+// Удаление идет пачками, чтобы не держать долгих блокировок
+func (s *BannerService) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	before := time.Now().UTC().Add(-retention)
+	var total int64
+	for {
+		purged, err := s.bannerRepository.PurgeDeleted(ctx, before, purgeBatchSize)
+		total += purged
+		if err != nil || purged < purgeBatchSize {
+			return total, err
+		}
+	}
+}
+
+// DeleteBatch переносит в корзину очередную пачку баннеров под filter и сбрасывает кэш их пар тег-фича.
+// Возвращает размер пачки. 0 - свободных баннеров не осталось, но заблокированные другими транзакциями
+// могут остаться
+func (s *BannerService) DeleteBatch(ctx context.Context, filter *entity.BannerFilter, batchSize int) (int, error) {
+	banners, err := s.bannerRepository.DeleteBatch(ctx, filter, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	affected := make(map[cache.Key]struct{})
+	for _, banner := range banners {
+		addCacheKeys(affected, banner.TagIDs, banner.FeatureID)
+	}
+	s.invalidate(affected)
+	return len(banners), nil
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/pkg/logger"
+	"banner/pkg/webhook"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrWebhookStatus - подписчик ответил не 2xx
+var ErrWebhookStatus = errors.New("unexpected webhook response status")
+
+// WebhookOptions - параметры воркеров доставки вебхуков
+type WebhookOptions struct {
+	// Workers - сколько доставок отправляется одновременно
+	Workers int
+	// PollInterval - как часто свободный воркер проверяет пустую очередь
+	PollInterval time.Duration
+	// MaxAttempts - после стольких неудачных попыток доставка становится dead
+	MaxAttempts int32
+	// RetryBackoff - задержка перед второй попыткой, дальше она удваивается до MaxBackoff
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	// Timeout - сколько ждать ответа подписчика
+	Timeout time.Duration
+	// LockTimeout - через сколько взятая доставка считается брошенной и выдается снова, должен быть больше Timeout
+	LockTimeout time.Duration
+	// DrainTimeout - сколько при остановке ждать отправляющиеся доставки
+	DrainTimeout time.Duration
+}
+
+// WebhookService управляет подписками и отправляет доставки из исходящего ящика webhook_deliveries
+type WebhookService struct {
+	webhookRepository *repository.WebhookRepository
+	l                 logger.Logger
+	options           WebhookOptions
+	httpClient        *http.Client
+	worker            string
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+func NewWebhookService(webhookRepository *repository.WebhookRepository, l logger.Logger, options WebhookOptions) *WebhookService {
+	hostname, _ := os.Hostname()
+	return &WebhookService{
+		webhookRepository: webhookRepository,
+		l:                 l,
+		options:           options,
+		httpClient:        &http.Client{Timeout: options.Timeout},
+		worker:            hostname + ":" + strconv.Itoa(os.Getpid()),
+	}
+}
+
+// Create сохраняет подписку. Без ключа подписи он генерируется, подписка возвращается вместе с ключом
+func (s *WebhookService) Create(ctx context.Context, hook *entity.Webhook) (*entity.Webhook, error) {
+	if hook.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			return nil, err
+		}
+		hook.Secret = secret
+	}
+	return s.webhookRepository.Create(ctx, hook)
+}
+
+func (s *WebhookService) Get(ctx context.Context, id int32) (*entity.Webhook, error) {
+	hook, err := s.webhookRepository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	hook.Secret = ""
+	return hook, nil
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]*entity.Webhook, error) {
+	hooks, err := s.webhookRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		hook.Secret = ""
+	}
+	if hooks == nil {
+		hooks = []*entity.Webhook{}
+	}
+	return hooks, nil
+}
+
+func (s *WebhookService) Update(ctx context.Context, update *entity.WebhookUpdate) (*entity.Webhook, error) {
+	hook, err := s.webhookRepository.Update(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+	hook.Secret = ""
+	return hook, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id int32) error {
+	return s.webhookRepository.Delete(ctx, id)
+}
+
+// Deliveries - журнал доставок подписки, новые первыми
+func (s *WebhookService) Deliveries(ctx context.Context, query *entity.WebhookDeliveriesQuery) ([]*entity.WebhookDelivery, error) {
+	if _, err := s.webhookRepository.Get(ctx, query.WebhookID); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.webhookRepository.Deliveries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []*entity.WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+// Redeliver ставит доставку в очередь заново, например после исправления подписчика
+func (s *WebhookService) Redeliver(ctx context.Context, webhookID int32, id int64) (*entity.WebhookDelivery, error) {
+	return s.webhookRepository.Redeliver(ctx, webhookID, id)
+}
+
+// Start запускает воркеры доставки
+func (s *WebhookService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.stop = make(chan struct{})
+	for i := 0; i < s.options.Workers; i++ {
+		s.running.Add(1)
+		go s.work(ctx, s.stop)
+	}
+}
+
+// Stop перестает брать новые доставки и ждет отправляющиеся не дольше DrainTimeout.
+// Прерванные доставки остаются в очереди
+func (s *WebhookService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	drained := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(s.options.DrainTimeout):
+		s.l.Warn("Webhook deliveries drain timed out, cancelling running deliveries")
+		s.cancel()
+		<-drained
+	}
+	s.cancel()
+	s.stop = nil
+}
+
+// work забирает доставки, пока воркеры не остановят. Пустую очередь воркер проверяет раз в PollInterval
+func (s *WebhookService) work(ctx context.Context, stop <-chan struct{}) {
+	defer s.running.Done()
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		delivery, err := s.webhookRepository.ClaimDelivery(ctx, s.worker, time.Now().UTC().Add(-s.options.LockTimeout))
+		if err == nil {
+			s.deliver(ctx, delivery)
+			continue
+		}
+		if !errors.Is(err, repository.ErrDeliveryNotFound) {
+			s.l.Error("service - WebhookService - work - ClaimDelivery: %v", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(s.options.PollInterval):
+		}
+	}
+}
+
+// deliver отправляет доставку и записывает результат: успех, повтор с задержкой или dead
+func (s *WebhookService) deliver(ctx context.Context, delivery *entity.WebhookDelivery) {
+	// результат пишется даже после отмены ctx, иначе доставка останется за остановленным воркером
+	store := context.WithoutCancel(ctx)
+	responseStatus, err := s.send(ctx, delivery)
+	switch {
+	case err == nil:
+		err = s.webhookRepository.CompleteDelivery(store, delivery.ID, s.worker, responseStatus)
+	case ctx.Err() != nil:
+		s.l.Info("Webhook delivery %d interrupted, returning it to the queue", delivery.ID)
+		err = s.webhookRepository.RetryDelivery(store, delivery.ID, s.worker, time.Now().UTC(), responseStatus, err)
+	case delivery.Attempts >= s.options.MaxAttempts:
+		s.l.Error("Webhook delivery %d failed after %d attempts: %v", delivery.ID, delivery.Attempts, err)
+		err = s.webhookRepository.DeadDelivery(store, delivery.ID, s.worker, responseStatus, err)
+	default:
+		delay := backoff(delivery.Attempts, s.options.RetryBackoff, s.options.MaxBackoff)
+		s.l.Warn("Webhook delivery %d attempt %d failed, retrying in %s: %v", delivery.ID, delivery.Attempts, delay, err)
+		err = s.webhookRepository.RetryDelivery(store, delivery.ID, s.worker, time.Now().UTC().Add(delay), responseStatus, err)
+	}
+	if errors.Is(err, repository.ErrDeliveryLost) {
+		s.l.Warn("Webhook delivery %d was taken over by another worker or deleted", delivery.ID)
+		return
+	}
+	if err != nil {
+		s.l.Error("service - WebhookService - deliver: %v", err)
+	}
+}
+
+// send отправляет событие доставки подписчику и возвращает код ответа, если ответ был
+func (s *WebhookService) send(ctx context.Context, delivery *entity.WebhookDelivery) (*int32, error) {
+	hook, err := s.webhookRepository.Get(ctx, delivery.WebhookID)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook: %w", err)
+	}
+	event, err := s.webhookRepository.Event(ctx, delivery.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("get event: %w", err)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.EventHeader, string(event.Type))
+	req.Header.Set(webhook.DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(hook.Secret, time.Now(), body))
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	status := int32(resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &status, fmt.Errorf("%w: %d", ErrWebhookStatus, resp.StatusCode)
+	}
+	return &status, nil
+}
@@ -0,0 +1 @@
+DROP TABLE feature_schemas;
\ No newline at end of file
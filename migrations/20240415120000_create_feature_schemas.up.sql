@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS feature_schemas (
+                         feature_id integer PRIMARY KEY,
+                         schema jsonb NOT NULL,
+                         created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                         updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,6 @@
+DROP FUNCTION jsonb_patch(jsonb, jsonb);
+DROP FUNCTION jsonb_patch_remove(jsonb, text[]);
+DROP FUNCTION jsonb_patch_add(jsonb, text[], jsonb);
+DROP FUNCTION jsonb_patch_set(jsonb, text[], jsonb);
+DROP FUNCTION jsonb_pointer_path(text);
+DROP FUNCTION jsonb_merge_patch(jsonb, jsonb);
@@ -0,0 +1,162 @@
+-- RFC 7396: объекты сливаются рекурсивно, null удаляет ключ, остальные значения заменяются целиком
+CREATE OR REPLACE FUNCTION jsonb_merge_patch(target jsonb, patch jsonb)
+    RETURNS jsonb AS $$
+DECLARE
+    result jsonb;
+    k      text;
+    v      jsonb;
+BEGIN
+    IF patch IS NULL OR jsonb_typeof(patch) <> 'object' THEN
+        RETURN patch;
+    END IF;
+    IF target IS NULL OR jsonb_typeof(target) <> 'object' THEN
+        result := '{}'::jsonb;
+    ELSE
+        result := target;
+    END IF;
+    FOR k, v IN SELECT * FROM jsonb_each(patch) LOOP
+        IF jsonb_typeof(v) = 'null' THEN
+            result := result - k;
+        ELSE
+            result := jsonb_set(result, ARRAY[k], jsonb_merge_patch(result -> k, v));
+        END IF;
+    END LOOP;
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- JSON Pointer (RFC 6901) -> путь для операторов #>, #- и jsonb_set
+CREATE OR REPLACE FUNCTION jsonb_pointer_path(pointer text)
+    RETURNS text[] AS $$
+BEGIN
+    IF pointer = '' THEN
+        RETURN ARRAY[]::text[];
+    END IF;
+    IF left(pointer, 1) <> '/' THEN
+        RAISE EXCEPTION 'json patch: invalid pointer "%"', pointer USING ERRCODE = '22023';
+    END IF;
+    RETURN ARRAY(
+        SELECT replace(replace(token, '~1', '/'), '~0', '~')
+        FROM unnest(string_to_array(substr(pointer, 2), '/')) WITH ORDINALITY AS t(token, n)
+        ORDER BY n
+    );
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION jsonb_patch_set(doc jsonb, path text[], new_value jsonb)
+    RETURNS jsonb AS $$
+BEGIN
+    IF cardinality(path) = 0 THEN
+        RETURN new_value;
+    END IF;
+    RETURN jsonb_set(doc, path, new_value, true);
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION jsonb_patch_add(doc jsonb, path text[], new_value jsonb)
+    RETURNS jsonb AS $$
+DECLARE
+    parent_path text[];
+    parent      jsonb;
+    token_      text;
+    idx         integer;
+    arr_len     integer;
+BEGIN
+    IF cardinality(path) = 0 THEN
+        RETURN new_value;
+    END IF;
+    parent_path := path[1:cardinality(path) - 1];
+    parent := doc #> parent_path;
+    token_ := path[cardinality(path)];
+    IF parent IS NULL THEN
+        RAISE EXCEPTION 'json patch: path not found "%"', array_to_string(parent_path, '/') USING ERRCODE = '22023';
+    END IF;
+    IF jsonb_typeof(parent) = 'object' THEN
+        RETURN jsonb_patch_set(doc, path, new_value);
+    END IF;
+    IF jsonb_typeof(parent) <> 'array' THEN
+        RAISE EXCEPTION 'json patch: cannot add to scalar at "%"', array_to_string(parent_path, '/') USING ERRCODE = '22023';
+    END IF;
+    arr_len := jsonb_array_length(parent);
+    IF token_ = '-' THEN
+        idx := arr_len;
+    ELSIF token_ ~ '^(0|[1-9][0-9]*)$' THEN
+        idx := token_::integer;
+    ELSE
+        RAISE EXCEPTION 'json patch: invalid array index "%"', token_ USING ERRCODE = '22023';
+    END IF;
+    IF idx > arr_len THEN
+        RAISE EXCEPTION 'json patch: array index out of range "%"', token_ USING ERRCODE = '22023';
+    END IF;
+    parent := COALESCE((SELECT jsonb_agg(e ORDER BY n) FROM jsonb_array_elements(parent) WITH ORDINALITY AS t(e, n) WHERE n <= idx), '[]'::jsonb)
+        || jsonb_build_array(new_value)
+        || COALESCE((SELECT jsonb_agg(e ORDER BY n) FROM jsonb_array_elements(parent) WITH ORDINALITY AS t(e, n) WHERE n > idx), '[]'::jsonb);
+    RETURN jsonb_patch_set(doc, parent_path, parent);
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION jsonb_patch_remove(doc jsonb, path text[])
+    RETURNS jsonb AS $$
+BEGIN
+    IF cardinality(path) = 0 OR doc #> path IS NULL THEN
+        RAISE EXCEPTION 'json patch: path not found "%"', array_to_string(path, '/') USING ERRCODE = '22023';
+    END IF;
+    RETURN doc #- path;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- RFC 6902: операции применяются по порядку, любая ошибка отменяет весь патч
+CREATE OR REPLACE FUNCTION jsonb_patch(doc jsonb, patch jsonb)
+    RETURNS jsonb AS $$
+DECLARE
+    op    jsonb;
+    path  text[];
+    new_value jsonb;
+BEGIN
+    IF jsonb_typeof(patch) <> 'array' THEN
+        RAISE EXCEPTION 'json patch: document must be an array' USING ERRCODE = '22023';
+    END IF;
+    FOR op IN SELECT * FROM jsonb_array_elements(patch) LOOP
+        IF NOT op ? 'path' THEN
+            RAISE EXCEPTION 'json patch: operation without path' USING ERRCODE = '22023';
+        END IF;
+        path := jsonb_pointer_path(op ->> 'path');
+        CASE op ->> 'op'
+            WHEN 'add' THEN
+                IF NOT op ? 'value' THEN
+                    RAISE EXCEPTION 'json patch: add without value' USING ERRCODE = '22023';
+                END IF;
+                doc := jsonb_patch_add(doc, path, op -> 'value');
+            WHEN 'remove' THEN
+                doc := jsonb_patch_remove(doc, path);
+            WHEN 'replace' THEN
+                IF NOT op ? 'value' THEN
+                    RAISE EXCEPTION 'json patch: replace without value' USING ERRCODE = '22023';
+                END IF;
+                IF doc #> path IS NULL THEN
+                    RAISE EXCEPTION 'json patch: path not found "%"', op ->> 'path' USING ERRCODE = '22023';
+                END IF;
+                doc := jsonb_patch_set(doc, path, op -> 'value');
+            WHEN 'move' THEN
+                new_value := doc #> jsonb_pointer_path(op ->> 'from');
+                IF new_value IS NULL THEN
+                    RAISE EXCEPTION 'json patch: path not found "%"', op ->> 'from' USING ERRCODE = '22023';
+                END IF;
+                doc := jsonb_patch_add(jsonb_patch_remove(doc, jsonb_pointer_path(op ->> 'from')), path, new_value);
+            WHEN 'copy' THEN
+                new_value := doc #> jsonb_pointer_path(op ->> 'from');
+                IF new_value IS NULL THEN
+                    RAISE EXCEPTION 'json patch: path not found "%"', op ->> 'from' USING ERRCODE = '22023';
+                END IF;
+                doc := jsonb_patch_add(doc, path, new_value);
+            WHEN 'test' THEN
+                IF doc #> path IS DISTINCT FROM op -> 'value' THEN
+                    RAISE EXCEPTION 'json patch: test failed at "%"', op ->> 'path' USING ERRCODE = '22023';
+                END IF;
+            ELSE
+                RAISE EXCEPTION 'json patch: unsupported operation "%"', op ->> 'op' USING ERRCODE = '22023';
+        END CASE;
+    END LOOP;
+    RETURN doc;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
@@ -0,0 +1,20 @@
+CREATE OR REPLACE FUNCTION save_banner_history()
+    RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO banners_history (id, tag_ids, feature_id, content, is_active, created_at)
+    VALUES (OLD.id, OLD.tag_ids, OLD.feature_id, OLD.content, OLD.is_active, OLD.created_at);
+
+    DELETE FROM banners_history
+    WHERE (id, updated_at) NOT IN (
+        SELECT id, updated_at
+        FROM banners_history
+        ORDER BY updated_at DESC
+        LIMIT 3
+    );
+
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE banners_history DROP COLUMN version;
+ALTER TABLE banners DROP COLUMN version;
@@ -0,0 +1,22 @@
+ALTER TABLE banners ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+ALTER TABLE banners_history ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION save_banner_history()
+    RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO banners_history (id, tag_ids, feature_id, content, is_active, created_at, version)
+    VALUES (OLD.id, OLD.tag_ids, OLD.feature_id, OLD.content, OLD.is_active, OLD.created_at, OLD.version);
+
+    -- последние 3 версии хранятся для каждого баннера, иначе правки одних баннеров вытесняли бы историю других
+    DELETE FROM banners_history
+    WHERE id = OLD.id AND version NOT IN (
+        SELECT version
+        FROM banners_history
+        WHERE id = OLD.id
+        ORDER BY version DESC
+        LIMIT 3
+    );
+
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
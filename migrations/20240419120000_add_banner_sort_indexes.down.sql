@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_banners_feature_id_id;
+DROP INDEX IF EXISTS idx_banners_updated_at_id;
+DROP INDEX IF EXISTS idx_banners_created_at_id;
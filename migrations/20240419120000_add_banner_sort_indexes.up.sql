@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_banners_created_at_id ON banners (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_banners_updated_at_id ON banners (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_banners_feature_id_id ON banners (feature_id, id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_banners_active_feature_id;
+DROP INDEX IF EXISTS idx_banners_content_fts;
+DROP INDEX IF EXISTS idx_banners_content_path;
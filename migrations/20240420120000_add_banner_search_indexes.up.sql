@@ -0,0 +1,4 @@
+-- индексы для фильтров content_path (@?) и q (полнотекстовый поиск по строкам content)
+CREATE INDEX IF NOT EXISTS idx_banners_content_path ON banners USING GIN (content jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_banners_content_fts ON banners USING GIN (to_tsvector('simple', content));
+CREATE INDEX IF NOT EXISTS idx_banners_active_feature_id ON banners (is_active, feature_id);
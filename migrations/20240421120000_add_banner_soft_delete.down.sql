@@ -0,0 +1,3 @@
+DELETE FROM banners WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_banners_deleted_at;
+ALTER TABLE banners DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+-- удаленные баннеры остаются в таблице до очистки, deleted_at - время переноса в корзину
+ALTER TABLE banners ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_banners_deleted_at ON banners (deleted_at) WHERE deleted_at IS NOT NULL;
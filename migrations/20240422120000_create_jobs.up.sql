@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS jobs (
+                         id BIGSERIAL PRIMARY KEY,
+                         kind text NOT NULL,
+                         status text NOT NULL DEFAULT 'queued',
+                         params jsonb NOT NULL DEFAULT '{}',
+                         total bigint NOT NULL DEFAULT 0,
+                         processed bigint NOT NULL DEFAULT 0,
+                         error text,
+                         created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                         updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                         finished_at TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_jobs_created_at;
+DROP INDEX IF EXISTS idx_jobs_running;
+DROP INDEX IF EXISTS idx_jobs_queued;
+ALTER TABLE jobs DROP COLUMN IF EXISTS locked_by;
+ALTER TABLE jobs DROP COLUMN IF EXISTS locked_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS run_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS max_attempts;
+ALTER TABLE jobs DROP COLUMN IF EXISTS attempts;
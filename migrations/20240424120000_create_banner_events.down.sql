@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS banner_events_trigger ON banners;
+DROP FUNCTION IF EXISTS log_banner_event();
+DROP TABLE IF EXISTS banner_events;
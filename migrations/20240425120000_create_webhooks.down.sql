@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS webhook_deliveries_trigger ON banner_events;
+DROP FUNCTION IF EXISTS enqueue_webhook_deliveries();
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,52 @@
+-- подписки на изменения баннеров. Пустые event_types и feature_ids - все события и все фичи
+CREATE TABLE IF NOT EXISTS webhooks (
+                         id SERIAL PRIMARY KEY,
+                         url text NOT NULL,
+                         secret text NOT NULL,
+                         event_types text[] NOT NULL DEFAULT '{}',
+                         feature_ids integer[] NOT NULL DEFAULT '{}',
+                         is_active boolean NOT NULL DEFAULT true,
+                         created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                         updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- исходящий ящик доставок: запись добавляет триггер на banner_events, то есть в той же транзакции,
+-- что и изменение баннера. Воркеры забирают доставки через SELECT ... FOR UPDATE SKIP LOCKED,
+-- next_attempt_at - время следующей попытки, locked_at - время, когда воркер взял доставку
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+                         id BIGSERIAL PRIMARY KEY,
+                         webhook_id integer NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+                         event_id bigint NOT NULL REFERENCES banner_events (id) ON DELETE CASCADE,
+                         event_type text NOT NULL,
+                         status text NOT NULL DEFAULT 'pending',
+                         attempts integer NOT NULL DEFAULT 0,
+                         next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                         response_status integer,
+                         error text,
+                         locked_at TIMESTAMP WITH TIME ZONE,
+                         locked_by text,
+                         created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                         updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                         delivered_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, id DESC);
+
+CREATE OR REPLACE FUNCTION enqueue_webhook_deliveries()
+    RETURNS TRIGGER AS $$
+BEGIN
+    -- фильтр по фиче учитывает и фичу до изменения, чтобы подписчик узнал, что баннер ушел из фичи
+    INSERT INTO webhook_deliveries (webhook_id, event_id, event_type)
+    SELECT id, NEW.id, NEW.type
+    FROM webhooks
+    WHERE is_active
+      AND (event_types = '{}' OR NEW.type = ANY (event_types))
+      AND (feature_ids = '{}' OR NEW.feature_id = ANY (feature_ids) OR NEW.previous_feature_id = ANY (feature_ids));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS webhook_deliveries_trigger ON banner_events;
+CREATE TRIGGER webhook_deliveries_trigger
+    AFTER INSERT ON banner_events
+    FOR EACH ROW EXECUTE FUNCTION enqueue_webhook_deliveries();
@@ -0,0 +1,55 @@
+DROP INDEX IF EXISTS idx_banner_events_banner;
+DROP TRIGGER IF EXISTS banner_events_append_only ON banner_events;
+DROP FUNCTION IF EXISTS forbid_banner_event_change();
+
+CREATE OR REPLACE FUNCTION log_banner_event()
+    RETURNS TRIGGER AS $$
+DECLARE
+    event_type text;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        event_type := 'created';
+    ELSIF TG_OP = 'DELETE' THEN
+        -- очистка корзины: удаление уже записано при переносе в корзину
+        IF OLD.deleted_at IS NOT NULL THEN
+            RETURN OLD;
+        END IF;
+        event_type := 'deleted';
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        event_type := 'deleted';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        event_type := 'restored';
+    ELSIF NEW.deleted_at IS NOT NULL THEN
+        RETURN NEW;
+    ELSIF OLD.is_active IS DISTINCT FROM NEW.is_active AND OLD.tag_ids = NEW.tag_ids
+        AND OLD.feature_id = NEW.feature_id AND OLD.content = NEW.content THEN
+        event_type := CASE WHEN NEW.is_active THEN 'activated' ELSE 'deactivated' END;
+    ELSE
+        event_type := 'updated';
+    END IF;
+
+    -- запись событий сериализуется до конца транзакции, поэтому id растут в порядке фиксации и читатель,
+    -- продолжающий с последнего id, не пропустит событие транзакции, которая зафиксировалась позже
+    PERFORM pg_advisory_xact_lock(hashtext('banner_events'));
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO banner_events (type, banner_id, version, feature_id, tag_ids, is_active)
+        VALUES (event_type, OLD.id, OLD.version, OLD.feature_id, OLD.tag_ids, OLD.is_active);
+        RETURN OLD;
+    END IF;
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO banner_events (type, banner_id, version, feature_id, tag_ids, content, is_active)
+        VALUES (event_type, NEW.id, NEW.version, NEW.feature_id, NEW.tag_ids, NEW.content, NEW.is_active);
+        RETURN NEW;
+    END IF;
+    INSERT INTO banner_events (type, banner_id, version, feature_id, tag_ids, previous_feature_id, previous_tag_ids, content, is_active)
+    VALUES (event_type, NEW.id, NEW.version, NEW.feature_id, NEW.tag_ids, OLD.feature_id, OLD.tag_ids,
+            CASE WHEN event_type = 'deleted' THEN NULL ELSE NEW.content END, NEW.is_active);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS banner_snapshot(banners);
+ALTER TABLE banner_events DROP COLUMN IF EXISTS after;
+ALTER TABLE banner_events DROP COLUMN IF EXISTS before;
+ALTER TABLE banner_events DROP COLUMN IF EXISTS actor;
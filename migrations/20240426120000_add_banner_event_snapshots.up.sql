@@ -0,0 +1,77 @@
+-- журнал изменений становится исходящим ящиком для аудита и репликации: кто сделал изменение и состояние
+-- баннера до и после него. Автора передает приложение через set_config('banner.actor', ..., true) в той же
+-- транзакции, что и изменение. id - монотонная последовательность: записи добавляются в порядке фиксации
+ALTER TABLE banner_events ADD COLUMN IF NOT EXISTS actor text;
+ALTER TABLE banner_events ADD COLUMN IF NOT EXISTS before jsonb;
+ALTER TABLE banner_events ADD COLUMN IF NOT EXISTS after jsonb;
+-- по снимкам восстанавливаются прошлые версии баннера для сравнения
+CREATE INDEX IF NOT EXISTS idx_banner_events_banner ON banner_events (banner_id, version);
+
+CREATE OR REPLACE FUNCTION banner_snapshot(banner banners)
+    RETURNS jsonb AS $$
+    SELECT jsonb_build_object('id', banner.id, 'tag_ids', banner.tag_ids, 'feature_id', banner.feature_id,
+                              'content', banner.content, 'is_active', banner.is_active, 'version', banner.version,
+                              'deleted_at', banner.deleted_at);
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION log_banner_event()
+    RETURNS TRIGGER AS $$
+DECLARE
+    event_type text;
+    event_actor text := NULLIF(current_setting('banner.actor', true), '');
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        event_type := 'created';
+    ELSIF TG_OP = 'DELETE' THEN
+        -- очистка корзины: удаление уже записано при переносе в корзину
+        IF OLD.deleted_at IS NOT NULL THEN
+            RETURN OLD;
+        END IF;
+        event_type := 'deleted';
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        event_type := 'deleted';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        event_type := 'restored';
+    ELSIF NEW.deleted_at IS NOT NULL THEN
+        RETURN NEW;
+    ELSIF OLD.is_active IS DISTINCT FROM NEW.is_active AND OLD.tag_ids = NEW.tag_ids
+        AND OLD.feature_id = NEW.feature_id AND OLD.content = NEW.content THEN
+        event_type := CASE WHEN NEW.is_active THEN 'activated' ELSE 'deactivated' END;
+    ELSE
+        event_type := 'updated';
+    END IF;
+
+    -- запись событий сериализуется до конца транзакции, поэтому id растут в порядке фиксации и читатель,
+    -- продолжающий с последнего id, не пропустит событие транзакции, которая зафиксировалась позже
+    PERFORM pg_advisory_xact_lock(hashtext('banner_events'));
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO banner_events (type, banner_id, version, feature_id, tag_ids, is_active, actor, before)
+        VALUES (event_type, OLD.id, OLD.version, OLD.feature_id, OLD.tag_ids, OLD.is_active, event_actor, banner_snapshot(OLD));
+        RETURN OLD;
+    END IF;
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO banner_events (type, banner_id, version, feature_id, tag_ids, content, is_active, actor, after)
+        VALUES (event_type, NEW.id, NEW.version, NEW.feature_id, NEW.tag_ids, NEW.content, NEW.is_active, event_actor, banner_snapshot(NEW));
+        RETURN NEW;
+    END IF;
+    INSERT INTO banner_events (type, banner_id, version, feature_id, tag_ids, previous_feature_id, previous_tag_ids, content, is_active, actor, before, after)
+    VALUES (event_type, NEW.id, NEW.version, NEW.feature_id, NEW.tag_ids, OLD.feature_id, OLD.tag_ids,
+            CASE WHEN event_type = 'deleted' THEN NULL ELSE NEW.content END, NEW.is_active,
+            event_actor, banner_snapshot(OLD), banner_snapshot(NEW));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- журнал только дополняется, исправление прошлого сломало бы потребителей, которые его уже прочитали
+CREATE OR REPLACE FUNCTION forbid_banner_event_change()
+    RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'banner_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS banner_events_append_only ON banner_events;
+CREATE TRIGGER banner_events_append_only
+    BEFORE UPDATE OR DELETE ON banner_events
+    FOR EACH ROW EXECUTE FUNCTION forbid_banner_event_change();
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS audit_log_trigger ON banner_events;
+DROP FUNCTION IF EXISTS record_banner_audit();
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS forbid_audit_change();
@@ -0,0 +1,54 @@
+-- аудит изменений баннеров: кто, откуда и что изменил. Запись добавляет триггер на banner_events в той же
+-- транзакции, что и изменение; адрес клиента и идентификатор запроса приложение передает через
+-- set_config('banner.client_ip' и 'banner.request_id', ..., true), как и автора. Что именно изменилось, приложение
+-- строит при чтении из снимков события журнала тем же сравнением, что и GET /banner/:id/diff
+CREATE TABLE IF NOT EXISTS audit_log (
+                         id BIGSERIAL PRIMARY KEY,
+                         event_id bigint NOT NULL UNIQUE REFERENCES banner_events (id),
+                         banner_id integer NOT NULL,
+                         action text NOT NULL,
+                         actor text,
+                         client_ip text,
+                         request_id text,
+                         created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_banner ON audit_log (banner_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+
+CREATE OR REPLACE FUNCTION record_banner_audit()
+    RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO audit_log (event_id, banner_id, action, actor, client_ip, request_id, created_at)
+    VALUES (NEW.id, NEW.banner_id, NEW.type, NEW.actor,
+            NULLIF(current_setting('banner.client_ip', true), ''),
+            NULLIF(current_setting('banner.request_id', true), ''),
+            NEW.created_at);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_trigger ON banner_events;
+CREATE TRIGGER audit_log_trigger
+    AFTER INSERT ON banner_events
+    FOR EACH ROW EXECUTE FUNCTION record_banner_audit();
+
+-- аудит, как и журнал изменений, только дополняется
+CREATE OR REPLACE FUNCTION forbid_audit_change()
+    RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION forbid_audit_change();
+
+-- события, записанные до появления аудита, переносятся без адреса клиента и идентификатора запроса
+INSERT INTO audit_log (event_id, banner_id, action, actor, created_at)
+SELECT id, banner_id, type, actor, created_at
+FROM banner_events
+ORDER BY id
+ON CONFLICT (event_id) DO NOTHING;
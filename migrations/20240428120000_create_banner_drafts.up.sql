@@ -0,0 +1,24 @@
+-- черновики изменений баннера, которые проходят ревью перед публикацией. Черновик хранит предлагаемое
+-- состояние баннера целиком и версию, от которой он сделан: опубликовать его можно, только пока баннер
+-- остается в этой версии. Открытым (draft, pending, rejected) у баннера может быть один черновик
+CREATE TABLE IF NOT EXISTS banner_drafts (
+                         id BIGSERIAL PRIMARY KEY,
+                         banner_id integer NOT NULL,
+                         base_version integer NOT NULL,
+                         tag_ids integer[] NOT NULL,
+                         feature_id integer NOT NULL,
+                         content jsonb NOT NULL,
+                         is_active boolean NOT NULL,
+                         status text NOT NULL DEFAULT 'draft',
+                         author text NOT NULL,
+                         reviewer text,
+                         comment text,
+                         published_version integer,
+                         created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                         updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                         submitted_at TIMESTAMP WITH TIME ZONE,
+                         reviewed_at TIMESTAMP WITH TIME ZONE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_banner_drafts_open ON banner_drafts (banner_id) WHERE status IN ('draft', 'pending', 'rejected');
+CREATE INDEX IF NOT EXISTS idx_banner_drafts_status ON banner_drafts (status, id DESC);
+CREATE INDEX IF NOT EXISTS idx_banner_drafts_banner ON banner_drafts (banner_id, id DESC);
@@ -0,0 +1,5 @@
+-- ключи разных авторов могут совпадать, а сохраненные ответы нужны только на время окна повторов
+DELETE FROM idempotency_keys;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS actor;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
@@ -0,0 +1,4 @@
+-- ключ идемпотентности действует в пределах автора запроса: одинаковые ключи разных клиентов не пересекаются
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS actor text NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (actor, key);
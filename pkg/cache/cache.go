@@ -28,10 +28,24 @@ type MemoryCache struct {
 type cacheEntry struct {
 	key      compositeKey
 	value    map[string]interface{}
+	tag      string
 	freqNode *list.Element
 	expiry   time.Time
 }
 
+// Item - значение из кэша вместе с его тегом (например, хэшем содержимого) и временем истечения
+type Item struct {
+	Value  map[string]interface{}
+	Tag    string
+	Expiry time.Time
+}
+
+// Key - пара ключей записи, например тег и фича баннера
+type Key struct {
+	Part1 int32
+	Part2 int32
+}
+
 type listEntry struct {
 	entries map[*cacheEntry]byte
 	freq    int
@@ -72,21 +86,36 @@ func (c *MemoryCache) Get(key1, key2 int32) (map[string]interface{}, error) {
 	}
 	return nil, errors.New("cache miss")
 }
+func (c *MemoryCache) GetItem(key1, key2 int32) (*Item, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := compositeKey{part1: key1, part2: key2}
+	if e, ok := c.values[key]; ok {
+		c.increment(e)
+		return &Item{Value: e.value, Tag: e.tag, Expiry: e.expiry}, nil
+	}
+	return nil, errors.New("cache miss")
+}
 func (e *cacheEntry) isExpired() bool {
 	return time.Now().After(e.expiry)
 }
 func (c *MemoryCache) Set(key1, key2 int32, value map[string]interface{}, ttl time.Duration) {
+	c.SetTagged(key1, key2, value, "", ttl)
+}
+func (c *MemoryCache) SetTagged(key1, key2 int32, value map[string]interface{}, tag string, ttl time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	key := compositeKey{part1: key1, part2: key2}
 	if e, exists := c.values[key]; exists {
 		e.value = value
+		e.tag = tag
 		e.expiry = time.Now().Add(ttl)
 		c.increment(e)
 	} else {
 		e := &cacheEntry{
 			key:    key,
 			value:  value,
+			tag:    tag,
 			expiry: time.Now().Add(ttl),
 		}
 		c.values[key] = e
@@ -98,6 +127,20 @@ func (c *MemoryCache) Set(key1, key2 int32, value map[string]interface{}, ttl ti
 	}
 }
 
+// Delete удаляет записи по ключам за одну блокировку кэша
+func (c *MemoryCache) Delete(keys ...Key) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range keys {
+		key := compositeKey{part1: k.Part1, part2: k.Part2}
+		if e, ok := c.values[key]; ok {
+			delete(c.values, key)
+			c.removeEntry(e.freqNode, e)
+			c.len--
+		}
+	}
+}
+
 func (c *MemoryCache) evict(count int) int {
 	var evicted int
 	for i := 0; i < count; {
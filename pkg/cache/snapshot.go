@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// SnapshotEntry - запись кэша в снимке. Снимок - JSON Lines, по записи в строке
+type SnapshotEntry struct {
+	Part1  int32                  `json:"part1"`
+	Part2  int32                  `json:"part2"`
+	Value  map[string]interface{} `json:"value"`
+	Tag    string                 `json:"tag,omitempty"`
+	Expiry time.Time              `json:"expiry"`
+	Freq   int                    `json:"freq"`
+}
+
+// Snapshot записывает в w все неистекшие записи кэша и возвращает их число
+func (c *MemoryCache) Snapshot(w io.Writer) (int, error) {
+	c.mu.Lock()
+	entries := make([]SnapshotEntry, 0, len(c.values))
+	for key, e := range c.values {
+		if e.isExpired() {
+			continue
+		}
+		entries = append(entries, SnapshotEntry{
+			Part1:  key.part1,
+			Part2:  key.part2,
+			Value:  e.value,
+			Tag:    e.tag,
+			Expiry: e.expiry,
+			Freq:   e.freqNode.Value.(*listEntry).freq,
+		})
+	}
+	c.mu.Unlock()
+
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	for i := range entries {
+		if err := encoder.Encode(&entries[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(entries), buffered.Flush()
+}
+
+// Restore загружает записи из снимка, пропуская истекшие, и возвращает число загруженных.
+// Частота обращений восстанавливается, поэтому вытеснение после перезапуска идет в том же порядке
+func (c *MemoryCache) Restore(r io.Reader) (int, error) {
+	var restored int
+	err := ReadSnapshot(r, func(entry SnapshotEntry) error {
+		ttl := time.Until(entry.Expiry)
+		if ttl <= 0 {
+			return nil
+		}
+		c.SetTagged(entry.Part1, entry.Part2, entry.Value, entry.Tag, ttl)
+		c.mu.Lock()
+		if e, ok := c.values[compositeKey{part1: entry.Part1, part2: entry.Part2}]; ok {
+			for freq := 1; freq < entry.Freq; freq++ {
+				c.increment(e)
+			}
+		}
+		c.mu.Unlock()
+		restored++
+		return nil
+	})
+	return restored, err
+}
+
+// ReadSnapshot вызывает fn для каждой записи снимка
+func ReadSnapshot(r io.Reader, fn func(entry SnapshotEntry) error) error {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var entry SnapshotEntry
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package client
+
+import "net/http"
+
+// Authenticator добавляет к запросу данные для аутентификации. Вызывается перед каждой попыткой,
+// поэтому может обновлять истекающие токены
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc - функция, реализующая Authenticator
+type AuthenticatorFunc func(req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// StaticToken передает токен в заголовке token: статический admin_token/user_token или выпущенный token issue
+type StaticToken string
+
+func (t StaticToken) Authenticate(req *http.Request) error {
+	req.Header.Set("token", string(t))
+	return nil
+}
+
+// WithAuth задает способ аутентификации запросов
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// WithToken передает token в заголовке token каждого запроса
+func WithToken(token string) Option {
+	return WithAuth(StaticToken(token))
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshInterval - период обновления кэша, если CacheOptions.RefreshInterval не задан
+const DefaultRefreshInterval = 30 * time.Second
+
+// BannerKey - пара тег-фича баннера пользователя
+type BannerKey struct {
+	TagID     int32
+	FeatureID int32
+}
+
+// CacheOptions - параметры локального кэша баннеров пользователя
+type CacheOptions struct {
+	// Keys - баннеры, которые кэш держит локально и обновляет в фоне
+	Keys []BannerKey
+	// RefreshInterval - как часто кэш перепроверяет баннеры по ETag, по умолчанию DefaultRefreshInterval
+	RefreshInterval time.Duration
+	// UseLastRevision - обновлять в обход кэша сервиса, изменения видны сразу, но нагрузка на бд выше
+	UseLastRevision bool
+	// OnStale вызывается, когда обновить баннер не удалось и кэш продолжает отдавать копию возраста age.
+	// age - время с последнего успешного обновления, 0 - баннер еще ни разу не загружен
+	OnStale func(key BannerKey, age time.Duration, err error)
+}
+
+// BannerCache хранит локальные копии баннеров пользователя и обновляет их в фоне, как SDK фича-флагов:
+// чтение не ходит в сеть, а пока сервис недоступен, отдается последняя полученная версия
+type BannerCache struct {
+	client  *Client
+	options CacheOptions
+
+	mu      sync.RWMutex
+	entries map[BannerKey]*cachedBanner
+
+	lifecycle sync.Mutex
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// cachedBanner - последний ответ сервиса по баннеру. err - ErrNotFound, если баннера нет или он выключен
+type cachedBanner struct {
+	content     map[string]interface{}
+	etag        string
+	err         error
+	refreshedAt time.Time
+}
+
+func NewBannerCache(client *Client, options CacheOptions) *BannerCache {
+	if options.RefreshInterval <= 0 {
+		options.RefreshInterval = DefaultRefreshInterval
+	}
+	return &BannerCache{
+		client:  client,
+		options: options,
+		entries: make(map[BannerKey]*cachedBanner, len(options.Keys)),
+	}
+}
+
+// Start загружает баннеры и запускает фоновое обновление. Баннеры, которые не удалось загрузить,
+// сообщаются через OnStale, Get для них обращается к сервису, пока фоновое обновление их не загрузит
+func (bc *BannerCache) Start(ctx context.Context) {
+	bc.lifecycle.Lock()
+	defer bc.lifecycle.Unlock()
+	if bc.cancel != nil {
+		return
+	}
+	bc.refresh(ctx)
+	var runCtx context.Context
+	runCtx, bc.cancel = context.WithCancel(context.Background())
+	bc.done = make(chan struct{})
+	go bc.run(runCtx, bc.done)
+}
+
+// Stop останавливает фоновое обновление, кэш продолжает отдавать загруженные баннеры
+func (bc *BannerCache) Stop() {
+	bc.lifecycle.Lock()
+	defer bc.lifecycle.Unlock()
+	if bc.cancel == nil {
+		return
+	}
+	bc.cancel()
+	<-bc.done
+	bc.cancel = nil
+}
+
+// Get отдает содержимое баннера из кэша. Баннеры не из Keys и еще не загруженные запрашиваются у сервиса.
+// Возвращаемое содержимое общее для всех вызовов, изменять его нельзя
+func (bc *BannerCache) Get(ctx context.Context, tagID, featureID int32) (map[string]interface{}, error) {
+	bc.mu.RLock()
+	entry, ok := bc.entries[BannerKey{TagID: tagID, FeatureID: featureID}]
+	bc.mu.RUnlock()
+	if ok {
+		return entry.content, entry.err
+	}
+	return bc.client.GetForUser(ctx, tagID, featureID, bc.options.UseLastRevision)
+}
+
+func (bc *BannerCache) run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(bc.options.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			bc.refresh(ctx)
+		}
+	}
+}
+
+// refresh перепроверяет все баннеры. Ответ 304 только продлевает копию, ошибки сервиса и сети
+// оставляют прежнюю копию и сообщаются через OnStale
+func (bc *BannerCache) refresh(ctx context.Context) {
+	for _, key := range bc.options.Keys {
+		if ctx.Err() != nil {
+			return
+		}
+		bc.mu.RLock()
+		previous := bc.entries[key]
+		bc.mu.RUnlock()
+		var etag string
+		if previous != nil {
+			etag = previous.etag
+		}
+
+		content, newETag, err := bc.client.getForUser(ctx, key.TagID, key.FeatureID, bc.options.UseLastRevision, etag)
+		now := time.Now()
+		var next *cachedBanner
+		switch {
+		case err == nil && content == nil && previous != nil:
+			next = &cachedBanner{content: previous.content, etag: previous.etag, err: previous.err, refreshedAt: now}
+		case err == nil:
+			next = &cachedBanner{content: content, etag: newETag, refreshedAt: now}
+		case errors.Is(err, ErrNotFound):
+			next = &cachedBanner{err: err, refreshedAt: now}
+		default:
+			bc.reportStale(key, previous, now, err)
+			continue
+		}
+		bc.mu.Lock()
+		bc.entries[key] = next
+		bc.mu.Unlock()
+	}
+}
+
+func (bc *BannerCache) reportStale(key BannerKey, previous *cachedBanner, now time.Time, err error) {
+	if bc.options.OnStale == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	var age time.Duration
+	if previous != nil {
+		age = now.Sub(previous.refreshedAt)
+	}
+	bc.options.OnStale(key, age, err)
+}
@@ -0,0 +1,300 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetForUser отдает содержимое баннера пользователя по тегу и фиче
+func (c *Client) GetForUser(ctx context.Context, tagID, featureID int32, useLastRevision bool) (map[string]interface{}, error) {
+	content, _, err := c.getForUser(ctx, tagID, featureID, useLastRevision, "")
+	return content, err
+}
+
+// getForUser - условный запрос баннера пользователя. Если содержимое не изменилось с etag,
+// возвращается nil и тот же etag
+func (c *Client) getForUser(ctx context.Context, tagID, featureID int32, useLastRevision bool, etag string) (map[string]interface{}, string, error) {
+	query := url.Values{}
+	query.Set("tag_id", strconv.Itoa(int(tagID)))
+	query.Set("feature_id", strconv.Itoa(int(featureID)))
+	if useLastRevision {
+		query.Set("use_last_revision", "true")
+	}
+	var header http.Header
+	if etag != "" {
+		header = http.Header{"If-None-Match": {etag}}
+	}
+	var content map[string]interface{}
+	resp, err := c.do(ctx, &request{method: http.MethodGet, path: "/user_banner", query: query, header: header}, &content)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, nil
+	}
+	return content, resp.Header.Get("ETag"), nil
+}
+
+// GetBanners отдает баннеры под фильтрами query
+func (c *Client) GetBanners(ctx context.Context, query *BannersQuery) ([]*Banner, error) {
+	var banners []*Banner
+	_, err := c.do(ctx, &request{method: http.MethodGet, path: "/banner", query: query.values()}, &banners)
+	return banners, err
+}
+
+// GetBannersPage отдает страницу баннеров после cursor, пустой cursor - первая страница
+func (c *Client) GetBannersPage(ctx context.Context, query *BannersQuery, cursor string) (*BannersPage, error) {
+	values := query.values()
+	values.Set("cursor", cursor)
+	var page BannersPage
+	_, err := c.do(ctx, &request{method: http.MethodGet, path: "/banner", query: values}, &page)
+	return &page, err
+}
+
+func (c *Client) Get(ctx context.Context, id int32) (*Banner, error) {
+	var banner Banner
+	_, err := c.do(ctx, &request{method: http.MethodGet, path: bannerPath(id)}, &banner)
+	if err != nil {
+		return nil, err
+	}
+	return &banner, nil
+}
+
+// Save создает баннер и возвращает его id. Запрос передается с Idempotency-Key, поэтому повтор после
+// сетевой ошибки не создаст второй баннер
+func (c *Client) Save(ctx context.Context, banner *NewBanner) (int32, error) {
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return 0, err
+	}
+	var created struct {
+		BannerID int32 `json:"banner_id"`
+	}
+	header := http.Header{idempotencyKeyHeader: {key}}
+	_, err = c.do(ctx, &request{method: http.MethodPost, path: "/banner", header: header, body: banner}, &created)
+	return created.BannerID, err
+}
+
+// Update меняет заданные поля баннера. Если задана update.Version, баннер обновится, только пока
+// его версия не изменилась
+func (c *Client) Update(ctx context.Context, id int32, update *BannerUpdate) error {
+	_, err := c.do(ctx, &request{method: http.MethodPatch, path: bannerPath(id), header: ifMatch(update.Version), body: update}, nil)
+	return err
+}
+
+// Delete переносит баннер в корзину, version - ожидаемая версия или nil
+func (c *Client) Delete(ctx context.Context, id int32, version *int32) error {
+	_, err := c.do(ctx, &request{method: http.MethodDelete, path: bannerPath(id), header: ifMatch(version)}, nil)
+	return err
+}
+
+// GetBannersHistoryByID отдает предыдущие версии баннера, новые первыми
+func (c *Client) GetBannersHistoryByID(ctx context.Context, id int32) ([]*HistoryItem, error) {
+	var history []*HistoryItem
+	_, err := c.do(ctx, &request{method: http.MethodGet, path: "/banner/history/" + strconv.Itoa(int(id))}, &history)
+	return history, err
+}
+
+// Rollback возвращает баннер к версии version из истории, expectedVersion - ожидаемая текущая версия или nil
+func (c *Client) Rollback(ctx context.Context, id, version int32, expectedVersion *int32) error {
+	body := map[string]int32{"version": version}
+	_, err := c.do(ctx, &request{method: http.MethodPost, path: bannerPath(id) + "/rollback", header: ifMatch(expectedVersion), body: body}, nil)
+	return err
+}
+
+// Diff сравнивает версию from баннера с версией to или, если to = nil, с текущей. С unified ответ
+// содержит и построчный diff версий
+func (c *Client) Diff(ctx context.Context, id, from int32, to *int32, unified bool) (*BannerDiff, error) {
+	query := url.Values{}
+	query.Set("from", strconv.Itoa(int(from)))
+	setInt32(query, "to", to)
+	if unified {
+		query.Set("unified", "true")
+	}
+	var diff BannerDiff
+	_, err := c.do(ctx, &request{method: http.MethodGet, path: bannerPath(id) + "/diff", query: query}, &diff)
+	if err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// Bulk выполняет пакет операций. Ошибки отдельных операций возвращаются в результатах, а не ошибкой.
+// Если атомарный пакет отменен, возвращаются и результаты, и ошибка ErrConflict
+func (c *Client) Bulk(ctx context.Context, bulk *BulkRequest) (*BulkResponse, error) {
+	var response BulkResponse
+	_, err := c.do(ctx, &request{method: http.MethodPost, path: "/banner/bulk", body: bulk, errorOut: &response}, &response)
+	if errors.Is(err, ErrConflict) && response.Results != nil {
+		return &response, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// importContentTypes - Content-Type файла загрузки в каждом формате
+var importContentTypes = map[string]string{
+	"jsonl": "application/x-ndjson",
+	"csv":   "text/csv",
+}
+
+// Import загружает баннеры из r в формате JSON Lines или CSV, как выгрузка GET /banner/export.
+// Некорректный файл возвращает ErrBadRequest, причина - в APIError.Details
+func (c *Client) Import(ctx context.Context, r io.Reader, options *ImportOptions) (*ImportReport, error) {
+	if options == nil {
+		options = &ImportOptions{}
+	}
+	query := url.Values{}
+	if options.Format != "" {
+		query.Set("format", options.Format)
+	}
+	if options.Conflict != "" {
+		query.Set("conflict", options.Conflict)
+	}
+	if options.DryRun {
+		query.Set("dry_run", "true")
+	}
+	if options.PreserveIDs {
+		query.Set("preserve_ids", "true")
+	}
+	contentType := importContentTypes[options.Format]
+	if contentType == "" {
+		contentType = importContentTypes["jsonl"]
+	}
+	var report ImportReport
+	_, err := c.do(ctx, &request{method: http.MethodPost, path: "/banner/import", query: query, body: r, contentType: contentType}, &report)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Trash отдает баннеры из корзины, удаленные последними первыми
+func (c *Client) Trash(ctx context.Context, limit, offset *int32) ([]*DeletedBanner, error) {
+	query := url.Values{}
+	setInt32(query, "limit", limit)
+	setInt32(query, "offset", offset)
+	var banners []*DeletedBanner
+	_, err := c.do(ctx, &request{method: http.MethodGet, path: "/banner/trash", query: query}, &banners)
+	return banners, err
+}
+
+// Restore возвращает баннер из корзины, version - ожидаемая версия удаленного баннера или nil
+func (c *Client) Restore(ctx context.Context, id int32, version *int32) error {
+	_, err := c.do(ctx, &request{method: http.MethodPost, path: bannerPath(id) + "/restore", header: ifMatch(version)}, nil)
+	return err
+}
+
+// DeleteBanners ставит в очередь удаление баннеров по фиче и/или тегу
+func (c *Client) DeleteBanners(ctx context.Context, featureID, tagID *int32) (*Job, error) {
+	query := url.Values{}
+	setInt32(query, "feature_id", featureID)
+	setInt32(query, "tag_id", tagID)
+	var job Job
+	_, err := c.do(ctx, &request{method: http.MethodDelete, path: "/banner", query: query}, &job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (c *Client) GetJob(ctx context.Context, id int64) (*Job, error) {
+	var job Job
+	_, err := c.do(ctx, &request{method: http.MethodGet, path: "/jobs/" + strconv.FormatInt(id, 10)}, &job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Events читает журнал изменений после события after, featureID и limit необязательны.
+// Чтобы продолжить чтение, передайте Next из ответа
+func (c *Client) Events(ctx context.Context, after int64, featureID, limit *int32) (*EventsPage, error) {
+	query := url.Values{}
+	query.Set("after", strconv.FormatInt(after, 10))
+	setInt32(query, "feature_id", featureID)
+	setInt32(query, "limit", limit)
+	var page EventsPage
+	_, err := c.do(ctx, &request{method: http.MethodGet, path: "/banner/events", query: query}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func newIdempotencyKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("client: generate idempotency key: %w", err)
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func bannerPath(id int32) string {
+	return "/banner/" + strconv.Itoa(int(id))
+}
+
+func (q *BannersQuery) values() url.Values {
+	values := url.Values{}
+	if q == nil {
+		return values
+	}
+	setInt32(values, "feature_id", q.FeatureID)
+	setInt32(values, "tag_id", q.TagID)
+	setInt32List(values, "feature_ids", q.FeatureIDs)
+	setInt32List(values, "tag_ids", q.TagIDs)
+	setString(values, "tag_match", q.TagMatch)
+	if q.IsActive != nil {
+		values.Set("is_active", strconv.FormatBool(*q.IsActive))
+	}
+	setTime(values, "created_from", q.CreatedFrom)
+	setTime(values, "created_to", q.CreatedTo)
+	setTime(values, "updated_from", q.UpdatedFrom)
+	setTime(values, "updated_to", q.UpdatedTo)
+	setString(values, "q", q.Search)
+	setString(values, "content_path", q.ContentPath)
+	setString(values, "sort_by", q.SortBy)
+	setString(values, "order", q.Order)
+	setInt32(values, "limit", q.Limit)
+	setInt32(values, "offset", q.Offset)
+	return values
+}
+
+func setInt32(values url.Values, name string, value *int32) {
+	if value != nil {
+		values.Set(name, strconv.Itoa(int(*value)))
+	}
+}
+
+func setInt32List(values url.Values, name string, list []int32) {
+	if len(list) == 0 {
+		return
+	}
+	parts := make([]string, len(list))
+	for i, value := range list {
+		parts[i] = strconv.Itoa(int(value))
+	}
+	values.Set(name, strings.Join(parts, ","))
+}
+
+func setString(values url.Values, name, value string) {
+	if value != "" {
+		values.Set(name, value)
+	}
+}
+
+func setTime(values url.Values, name string, value *time.Time) {
+	if value != nil {
+		values.Set(name, value.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,163 @@
+// Package client - Go-клиент API сервиса баннеров. Методы повторяют интерфейс сервиса, ответы
+// с ошибкой возвращаются как *APIError, который сравнивается через errors.Is с ErrNotFound и другими
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout       = 10 * time.Second
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	auth       Authenticator
+	retry      RetryPolicy
+}
+
+type Option func(*Client)
+
+// WithHTTPClient заменяет http.Client по умолчанию (таймаут 10 секунд)
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New создает клиент сервиса, доступного по baseURL, например http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base URL: %w", err)
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("client: base URL %q must be absolute", baseURL)
+	}
+	c := &Client{
+		baseURL:    parsed,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// request - запрос к API. Body кодируется в JSON, если это не io.Reader
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        interface{}
+	contentType string
+	// errorOut - куда дополнительно разобрать тело ответа с ошибкой, если оно несет результат
+	errorOut interface{}
+}
+
+// do выполняет запрос с повторами и разбирает JSON-ответ в out. Ответ с кодом 4xx или 5xx возвращается как *APIError
+func (c *Client) do(ctx context.Context, req *request, out interface{}) (*http.Response, error) {
+	var body []byte
+	contentType := req.contentType
+	switch value := req.body.(type) {
+	case nil:
+	case io.Reader:
+		// тело читается целиком, чтобы повторная попытка отправила его снова
+		var err error
+		if body, err = io.ReadAll(value); err != nil {
+			return nil, fmt.Errorf("client: read %s %s request body: %w", req.method, req.path, err)
+		}
+	default:
+		var err error
+		if body, err = json.Marshal(value); err != nil {
+			return nil, fmt.Errorf("client: encode %s %s request: %w", req.method, req.path, err)
+		}
+		if contentType == "" {
+			contentType = "application/json"
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, req, body, contentType)
+		if !c.retry.shouldRetry(ctx, req, attempt, resp, err) {
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			return resp, decodeResponse(req, resp, out)
+		}
+		delay := c.retry.delay(attempt, resp)
+		if resp != nil {
+			// тело дочитывается, чтобы соединение вернулось в пул
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func decodeResponse(req *request, resp *http.Response, out interface{}) error {
+	if resp.StatusCode >= http.StatusBadRequest {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if req.errorOut != nil {
+			_ = json.Unmarshal(raw, req.errorOut)
+		}
+		return newAPIError(resp.StatusCode, raw)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("client: decode %s %s response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// send отправляет одну попытку запроса и возвращает ответ с непрочитанным телом
+func (c *Client) send(ctx context.Context, req *request, body []byte, contentType string) (*http.Response, error) {
+	target := *c.baseURL
+	target.Path += req.path
+	target.RawQuery = req.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if c.auth != nil {
+		if err := c.auth.Authenticate(httpReq); err != nil {
+			return nil, fmt.Errorf("client: authenticate: %w", err)
+		}
+	}
+	return c.httpClient.Do(httpReq)
+}
+
+// ifMatch - заголовок If-Match для ожидаемой версии баннера
+func ifMatch(version *int32) http.Header {
+	if version == nil {
+		return nil
+	}
+	return http.Header{"If-Match": {fmt.Sprintf("%q", fmt.Sprint(*version))}}
+}
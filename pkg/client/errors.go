@@ -0,0 +1,100 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Ошибки, соответствующие кодам ответа сервиса. Проверяются через errors.Is на *APIError
+var (
+	// ErrBadRequest - некорректные параметры или тело запроса, подробности в APIError.Fields
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden - у токена нет прав на операцию, например токен пользователя для ручки администратора
+	ErrForbidden = errors.New("forbidden")
+	ErrNotFound  = errors.New("not found")
+	// ErrConflict - операция конфликтует с состоянием баннера, например восстановление занятой пары тег-фича
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed - версия баннера изменилась с момента чтения
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrUnprocessable - патч или содержимое баннера не прошли проверку
+	ErrUnprocessable = errors.New("unprocessable entity")
+	ErrRateLimited   = errors.New("rate limited")
+	ErrServer        = errors.New("server error")
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:           ErrBadRequest,
+	http.StatusUnauthorized:         ErrUnauthorized,
+	http.StatusForbidden:            ErrForbidden,
+	http.StatusNotFound:             ErrNotFound,
+	http.StatusConflict:             ErrConflict,
+	http.StatusPreconditionFailed:   ErrPreconditionFailed,
+	http.StatusUnsupportedMediaType: ErrBadRequest,
+	http.StatusUnprocessableEntity:  ErrUnprocessable,
+	http.StatusTooManyRequests:      ErrRateLimited,
+}
+
+// APIError - ответ сервиса с кодом ошибки
+type APIError struct {
+	StatusCode int
+	// Message - описание ошибки от сервиса на языке из Accept-Language
+	Message    string
+	Fields     map[string]string
+	Violations []ContentViolation
+	// Details - подробности от сервиса, например почему не применился патч или не загрузился файл
+	Details string
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	if e.Details != "" {
+		message += ": " + e.Details
+	}
+	for field, reason := range e.Fields {
+		message += fmt.Sprintf("; %s: %s", field, reason)
+	}
+	return fmt.Sprintf("banner API: %d %s", e.StatusCode, message)
+}
+
+// Is сопоставляет код ответа с ошибками ErrNotFound, ErrPreconditionFailed и другими
+func (e *APIError) Is(target error) bool {
+	if e.StatusCode >= http.StatusInternalServerError {
+		return target == ErrServer
+	}
+	return statusErrors[e.StatusCode] == target
+}
+
+// newAPIError разбирает тело ответа с ошибкой. Сервис отдает описание в поле error, а для
+// некорректных параметров /user_banner - в поле message
+func newAPIError(statusCode int, raw []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+	var body struct {
+		Error      string             `json:"error"`
+		Message    string             `json:"message"`
+		Fields     map[string]string  `json:"fields"`
+		Violations []ContentViolation `json:"violations"`
+		Details    string             `json:"details"`
+		Detail     string             `json:"detail"`
+	}
+	if json.Unmarshal(raw, &body) != nil {
+		return apiErr
+	}
+	apiErr.Message = body.Error
+	if apiErr.Message == "" {
+		apiErr.Message = body.Message
+	}
+	apiErr.Fields = body.Fields
+	apiErr.Violations = body.Violations
+	apiErr.Details = body.Details
+	if apiErr.Details == "" {
+		// ошибки загрузки отдают подробности в поле detail
+		apiErr.Details = body.Detail
+	}
+	return apiErr
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy - повтор запросов после сетевых ошибок и ответов 429, 502, 503, 504. Повторяются только
+// идемпотентные запросы: GET, HEAD, PUT, DELETE одного баннера и запросы с Idempotency-Key
+type RetryPolicy struct {
+	// MaxAttempts - число попыток вместе с первой, 1 отключает повторы
+	MaxAttempts int
+	// BaseDelay - верхняя граница задержки перед второй попыткой, дальше она удваивается до MaxDelay.
+	// Сама задержка выбирается случайно от нуля до границы, чтобы клиенты не повторяли запросы одновременно
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetryPolicy - политика повторов по умолчанию
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 2 * time.Second}
+
+// WithRetry заменяет политику повторов по умолчанию
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// retryableStatuses - коды временной недоступности сервиса
+var retryableStatuses = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// idempotent сообщает, безопасно ли отправить запрос повторно. DELETE с фильтром в query ставит
+// в очередь задачу массового удаления, повтор поставил бы вторую
+func idempotent(req *request) bool {
+	switch req.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut:
+		return true
+	case http.MethodDelete:
+		return len(req.query) == 0
+	}
+	return req.header.Get(idempotencyKeyHeader) != ""
+}
+
+// shouldRetry решает, повторять ли попытку attempt, завершившуюся ответом resp или ошибкой err
+func (p RetryPolicy) shouldRetry(ctx context.Context, req *request, attempt int, resp *http.Response, err error) bool {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil || !idempotent(req) {
+		return false
+	}
+	if err != nil {
+		// повторяются только ошибки транспорта, ошибки Authenticator повтор не исправит
+		var transportErr *url.Error
+		return errors.As(err, &transportErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return retryableStatuses[resp.StatusCode]
+}
+
+// delay - задержка после attempt-й неудачной попытки. Retry-After сервиса соблюдается, но не дольше MaxDelay
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, p.MaxDelay)
+		}
+	}
+	ceiling := p.BaseDelay
+	for i := 1; i < attempt && ceiling < p.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, p.MaxDelay)
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,216 @@
+package client
+
+import (
+	"banner/pkg/jsondiff"
+	"encoding/json"
+	"time"
+)
+
+// Banner - баннер в том виде, в котором его отдает API администратора
+type Banner struct {
+	ID        int32                  `json:"id"`
+	TagIDs    []int32                `json:"tag_ids"`
+	FeatureID int32                  `json:"feature_id"`
+	Content   map[string]interface{} `json:"content"`
+	IsActive  bool                   `json:"is_active"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	Version   int32                  `json:"version"`
+}
+
+// NewBanner - баннер для создания
+type NewBanner struct {
+	TagIDs    []int32                `json:"tag_ids" yaml:"tag_ids"`
+	FeatureID int32                  `json:"feature_id" yaml:"feature_id"`
+	Content   map[string]interface{} `json:"content" yaml:"content"`
+	IsActive  bool                   `json:"is_active" yaml:"is_active"`
+}
+
+// BannerUpdate - частичное обновление, nil-поля не меняются
+type BannerUpdate struct {
+	TagIDs    *[]int32                `json:"tag_ids,omitempty" yaml:"tag_ids,omitempty"`
+	FeatureID *int32                  `json:"feature_id,omitempty" yaml:"feature_id,omitempty"`
+	Content   *map[string]interface{} `json:"content,omitempty" yaml:"content,omitempty"`
+	IsActive  *bool                   `json:"is_active,omitempty" yaml:"is_active,omitempty"`
+	// Version - ожидаемая версия баннера, передается в If-Match
+	Version *int32 `json:"-" yaml:"-"`
+}
+
+// HistoryItem - версия баннера из истории, Index - номер в выдаче начиная с 1
+type HistoryItem struct {
+	Index  int
+	Banner *Banner
+}
+
+// BannersQuery - фильтры, сортировка и пагинация списка баннеров
+type BannersQuery struct {
+	FeatureID   *int32
+	TagID       *int32
+	FeatureIDs  []int32
+	TagIDs      []int32
+	TagMatch    string
+	IsActive    *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	Search      string
+	ContentPath string
+	SortBy      string
+	Order       string
+	Limit       *int32
+	Offset      *int32
+}
+
+// BannersPage - страница выдачи по курсору
+type BannersPage struct {
+	Banners    []*Banner `json:"banners"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Total      int64     `json:"total"`
+}
+
+// DeletedBanner - баннер в корзине
+type DeletedBanner struct {
+	Banner
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type BulkRequest struct {
+	Mode       string          `json:"mode,omitempty"`
+	Operations []BulkOperation `json:"operations"`
+}
+
+// BulkOperation - операция пакета: create, update, delete, activate или deactivate
+type BulkOperation struct {
+	Action  string        `json:"action"`
+	ID      *int32        `json:"id,omitempty"`
+	Banner  *NewBanner    `json:"banner,omitempty"`
+	Update  *BannerUpdate `json:"update,omitempty"`
+	Version *int32        `json:"version,omitempty"`
+}
+
+type BulkResult struct {
+	Index      int                `json:"index"`
+	Action     string             `json:"action"`
+	ID         *int32             `json:"id,omitempty"`
+	Status     string             `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	Violations []ContentViolation `json:"violations,omitempty"`
+}
+
+type BulkResponse struct {
+	Mode    string        `json:"mode"`
+	Failed  int           `json:"failed"`
+	Results []*BulkResult `json:"results"`
+}
+
+// ContentViolation - значение содержимого, не прошедшее проверку схемой фичи
+type ContentViolation struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// ImportOptions - параметры загрузки: Format - jsonl или csv, Conflict - skip, overwrite или fail.
+// Пустые поля оставляют значения сервиса по умолчанию
+type ImportOptions struct {
+	Format      string
+	Conflict    string
+	DryRun      bool
+	PreserveIDs bool
+}
+
+// ImportReport - итог загрузки, Rejected - строки, которые не загружены
+type ImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Total    int               `json:"total"`
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"`
+	Skipped  int               `json:"skipped"`
+	Rejected []ImportRejection `json:"rejected"`
+}
+
+type ImportRejection struct {
+	Line   int    `json:"line"`
+	ID     int32  `json:"id,omitempty"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+}
+
+// Job - фоновая задача сервиса
+type Job struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Status      string          `json:"status"`
+	Params      json.RawMessage `json:"params"`
+	Total       int64           `json:"total"`
+	Processed   int64           `json:"processed"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"max_attempts"`
+	Error       string          `json:"error,omitempty"`
+	RunAt       time.Time       `json:"run_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+// TagFeature - пара тег-фича, по которой пользователи получают баннер
+type TagFeature struct {
+	TagID     int32 `json:"tag_id"`
+	FeatureID int32 `json:"feature_id"`
+}
+
+// BannerSnapshot - состояние баннера до или после изменения
+type BannerSnapshot struct {
+	ID        int32                  `json:"id"`
+	TagIDs    []int32                `json:"tag_ids"`
+	FeatureID int32                  `json:"feature_id"`
+	Content   map[string]interface{} `json:"content"`
+	IsActive  bool                   `json:"is_active"`
+	Version   int32                  `json:"version"`
+	DeletedAt *time.Time             `json:"deleted_at,omitempty"`
+}
+
+// BannerEvent - запись журнала изменений, ID - порядковый номер события
+type BannerEvent struct {
+	ID        int64                  `json:"id"`
+	Type      string                 `json:"type"`
+	BannerID  int32                  `json:"banner_id"`
+	Version   int32                  `json:"version"`
+	FeatureID int32                  `json:"feature_id"`
+	TagIDs    []int32                `json:"tag_ids"`
+	Content   map[string]interface{} `json:"content,omitempty"`
+	IsActive  bool                   `json:"is_active"`
+	Affected  []TagFeature           `json:"affected"`
+	Actor     string                 `json:"actor,omitempty"`
+	Before    *BannerSnapshot        `json:"before,omitempty"`
+	After     *BannerSnapshot        `json:"after,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// EventsPage - события журнала по порядку, Next - номер, с которого читать дальше
+type EventsPage struct {
+	Events []*BannerEvent `json:"events"`
+	Next   int64          `json:"next"`
+}
+
+// BannerDiff - разница между версиями From и To баннера. FeatureID и IsActive заданы, только если изменились,
+// Content - JSON Patch от содержимого версии From к содержимому версии To
+type BannerDiff struct {
+	ID     int32 `json:"id"`
+	From   int32 `json:"from"`
+	To     int32 `json:"to"`
+	TagIDs struct {
+		Added   []int32 `json:"added"`
+		Removed []int32 `json:"removed"`
+	} `json:"tag_ids"`
+	FeatureID *struct {
+		From int32 `json:"from"`
+		To   int32 `json:"to"`
+	} `json:"feature_id,omitempty"`
+	IsActive *struct {
+		From bool `json:"from"`
+		To   bool `json:"to"`
+	} `json:"is_active,omitempty"`
+	Content []jsondiff.Operation `json:"content"`
+	Unified string               `json:"unified,omitempty"`
+}
@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"golang.org/x/text/language"
+)
+
+type Messages map[string]string
+
+type Catalog struct {
+	messages map[language.Tag]Messages
+	tags     []language.Tag
+	matcher  language.Matcher
+	fallback language.Tag
+}
+
+// New создает каталог сообщений. Язык fallback используется, если ни один из языков
+// Accept-Language не поддерживается или в нужном языке нет перевода ключа.
+func New(fallback string, messages map[string]Messages) (*Catalog, error) {
+	fallbackTag, err := language.Parse(fallback)
+	if err != nil {
+		return nil, err
+	}
+	c := &Catalog{
+		messages: make(map[language.Tag]Messages, len(messages)),
+		fallback: fallbackTag,
+	}
+	// язык по умолчанию должен идти первым, тогда matcher вернет его при отсутствии совпадений
+	tags := []language.Tag{fallbackTag}
+	for lang, m := range messages {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, err
+		}
+		c.messages[tag] = m
+		if tag != fallbackTag {
+			tags = append(tags, tag)
+		}
+	}
+	c.tags = tags
+	c.matcher = language.NewMatcher(tags)
+	return c, nil
+}
+
+// Negotiate выбирает наиболее подходящий язык по значению заголовка Accept-Language
+func (c *Catalog) Negotiate(acceptLanguage string) language.Tag {
+	if acceptLanguage == "" {
+		return c.fallback
+	}
+	desired, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(desired) == 0 {
+		return c.fallback
+	}
+	_, index, confidence := c.matcher.Match(desired...)
+	if confidence == language.No {
+		return c.fallback
+	}
+	return c.tags[index]
+}
+
+// Translate возвращает сообщение по ключу на языке lang. Если перевод не найден,
+// возвращается сообщение на языке по умолчанию, а в крайнем случае сам ключ
+func (c *Catalog) Translate(lang language.Tag, key string) string {
+	if msg, ok := c.messages[lang][key]; ok {
+		return msg
+	}
+	if msg, ok := c.messages[c.fallback][key]; ok {
+		return msg
+	}
+	return key
+}
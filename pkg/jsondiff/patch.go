@@ -0,0 +1,167 @@
+// Package jsondiff сравнивает JSON-документы: строит JSON Patch (RFC 6902) от одного документа к другому
+// и построчный diff в формате unified для чтения человеком
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// операции JSON Patch, которые строит Patch
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Operation - операция JSON Patch. Value не сериализуется у remove, у add и replace null - допустимое значение
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == OpRemove {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	return json.Marshal(struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}{o.Op, o.Path, o.Value})
+}
+
+// Patch строит JSON Patch, который превращает from в to. Документы - любые значения, которые сериализуются
+// в JSON. Ключи объектов обходятся по алфавиту, поэтому результат детерминирован.
+// Массивы сравниваются по индексам: общая часть - поэлементно, хвост удаляется с конца или добавляется
+func Patch(from, to interface{}) []Operation {
+	ops := []Operation{}
+	return diff(ops, "", normalize(from), normalize(to))
+}
+
+func diff(ops []Operation, path string, from, to interface{}) []Operation {
+	switch fromValue := from.(type) {
+	case map[string]interface{}:
+		toValue, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range sortedKeys(fromValue) {
+			if _, ok := toValue[key]; !ok {
+				ops = append(ops, Operation{Op: OpRemove, Path: path + "/" + EscapePointer(key)})
+			}
+		}
+		for _, key := range sortedKeys(toValue) {
+			child := path + "/" + EscapePointer(key)
+			if old, ok := fromValue[key]; ok {
+				ops = diff(ops, child, old, toValue[key])
+			} else {
+				ops = append(ops, Operation{Op: OpAdd, Path: child, Value: toValue[key]})
+			}
+		}
+		return ops
+	case []interface{}:
+		toValue, ok := to.([]interface{})
+		if !ok {
+			break
+		}
+		common := min(len(fromValue), len(toValue))
+		for i := 0; i < common; i++ {
+			ops = diff(ops, path+"/"+strconv.Itoa(i), fromValue[i], toValue[i])
+		}
+		// удаление с конца не сдвигает индексы еще не удаленных элементов
+		for i := len(fromValue) - 1; i >= common; i-- {
+			ops = append(ops, Operation{Op: OpRemove, Path: path + "/" + strconv.Itoa(i)})
+		}
+		for i := common; i < len(toValue); i++ {
+			ops = append(ops, Operation{Op: OpAdd, Path: path + "/" + strconv.Itoa(i), Value: toValue[i]})
+		}
+		return ops
+	}
+	if !reflect.DeepEqual(from, to) {
+		ops = append(ops, Operation{Op: OpReplace, Path: path, Value: to})
+	}
+	return ops
+}
+
+// EscapePointer экранирует ключ для JSON Pointer (RFC 6901)
+func EscapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// normalize приводит документ к виду json.Unmarshal в interface{}, чтобы сравнение не зависело
+// от того, как документ получен: []int32 и []interface{} с теми же числами равны
+func normalize(value interface{}) interface{} {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var result interface{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return value
+	}
+	return result
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Change - изменение значения по JSON Pointer: From - значение в исходном документе, To - в новом.
+// У добавленного значения From, а у удаленного To - nil
+type Change struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Changes - те же изменения, что и у Patch, но вместе с прежними значениями, для журналов вроде аудита
+func Changes(from, to interface{}) []Change {
+	document := normalize(from)
+	ops := Patch(document, to)
+	changes := make([]Change, 0, len(ops))
+	for _, op := range ops {
+		change := Change{Path: op.Path, To: op.Value}
+		if op.Op != OpAdd {
+			change.From = lookup(document, op.Path)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// lookup находит значение по JSON Pointer в документе, приведенном normalize
+func lookup(document interface{}, pointer string) interface{} {
+	if pointer == "" {
+		return document
+	}
+	value := document
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			value = typed[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(typed) {
+				return nil
+			}
+			value = typed[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
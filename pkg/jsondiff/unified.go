@@ -0,0 +1,167 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// contextLines - сколько неизмененных строк показывается вокруг изменений, как у diff -u
+	contextLines = 3
+	// maxLCSCells - предельный размер таблицы общей подпоследовательности. Если измененная середина
+	// больше, она выводится целиком как удаленная и добавленная: diff длиннее, но память не растет
+	// как произведение длин документов
+	maxLCSCells = 1 << 20
+)
+
+// Unified печатает from и to как JSON с отступами и возвращает их построчный diff в формате unified
+// с заголовками fromName и toName. Пустая строка - документы совпадают
+func Unified(fromName, toName string, from, to interface{}) (string, error) {
+	a, err := jsonLines(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := jsonLines(to)
+	if err != nil {
+		return "", err
+	}
+	edits := lineEdits(a, b)
+	var out strings.Builder
+	for start := 0; start < len(edits); {
+		// ищем следующее изменение и собираем вокруг него блок вместе с близкими изменениями
+		first := start
+		for first < len(edits) && edits[first].kind == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		begin := max(first-contextLines, start)
+		end := first
+		for i := first; i < len(edits) && i <= end+2*contextLines; i++ {
+			if edits[i].kind != ' ' {
+				end = i
+			}
+		}
+		end = min(end+contextLines+1, len(edits))
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		writeHunk(&out, edits[begin:end])
+		start = end
+	}
+	return out.String(), nil
+}
+
+// edit - строка diff: ' ' - общая, '-' - только в from, '+' - только в to. aLine и bLine - номера
+// строки в from и to с единицы, у строки, которой нет в документе, - номер предыдущей
+type edit struct {
+	kind         byte
+	line         string
+	aLine, bLine int
+}
+
+func writeHunk(out *strings.Builder, hunk []edit) {
+	var aCount, bCount int
+	for _, e := range hunk {
+		if e.kind != '+' {
+			aCount++
+		}
+		if e.kind != '-' {
+			bCount++
+		}
+	}
+	aStart, bStart := hunk[0].aLine, hunk[0].bLine
+	if hunk[0].kind == '+' {
+		aStart++
+	}
+	if hunk[0].kind == '-' {
+		bStart++
+	}
+	// у пустого диапазона diff -u указывает строку перед ним
+	if aCount == 0 {
+		aStart--
+	}
+	if bCount == 0 {
+		bStart--
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+	for _, e := range hunk {
+		out.WriteByte(e.kind)
+		out.WriteString(e.line)
+		out.WriteByte('\n')
+	}
+}
+
+// lineEdits сравнивает строки через наибольшую общую подпоследовательность. Общие начало и конец
+// отбрасываются заранее, поэтому таблица строится только для измененной середины и только до maxLCSCells
+func lineEdits(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	// lcs[i][j] - длина общей подпоследовательности midA[i:] и midB[j:], nil - середина слишком большая
+	var lcs [][]int
+	if (len(midA)+1)*(len(midB)+1) <= maxLCSCells {
+		lcs = make([][]int, len(midA)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(midB)+1)
+		}
+		for i := len(midA) - 1; i >= 0; i-- {
+			for j := len(midB) - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	aLine, bLine := 0, 0
+	add := func(kind byte, line string) {
+		if kind != '+' {
+			aLine++
+		}
+		if kind != '-' {
+			bLine++
+		}
+		edits = append(edits, edit{kind: kind, line: line, aLine: aLine, bLine: bLine})
+	}
+	for _, line := range a[:prefix] {
+		add(' ', line)
+	}
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case lcs != nil && i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			add(' ', midA[i])
+			i, j = i+1, j+1
+		case j == len(midB) || (i < len(midA) && (lcs == nil || lcs[i+1][j] >= lcs[i][j+1])):
+			add('-', midA[i])
+			i++
+		default:
+			add('+', midB[j])
+			j++
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		add(' ', line)
+	}
+	return edits
+}
+
+func jsonLines(value interface{}) ([]string, error) {
+	raw, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(raw), "\n"), nil
+}
@@ -0,0 +1,83 @@
+// Package token выпускает и проверяет токены доступа, подписанные HMAC-SHA256.
+// Токен - base64url(JSON с ролью и сроком действия) и base64url(подпись) через точку
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+	RoleUser  Role = "user"
+)
+
+var (
+	ErrMalformed = errors.New("malformed token")
+	ErrSignature = errors.New("invalid token signature")
+	ErrExpired   = errors.New("token expired")
+	ErrRole      = errors.New("unknown token role")
+)
+
+// Claims - содержимое токена
+type Claims struct {
+	Subject   string `json:"sub,omitempty"`
+	Role      Role   `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Issue выпускает токен для роли role, действующий ttl
+func Issue(secret []byte, subject string, role Role, ttl time.Duration) (string, error) {
+	if role != RoleAdmin && role != RoleUser {
+		return "", ErrRole
+	}
+	payload, err := json.Marshal(Claims{Subject: subject, Role: role, ExpiresAt: time.Now().Add(ttl).Unix()})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(secret, encoded)), nil
+}
+
+// Verify проверяет подпись и срок действия токена
+func Verify(secret []byte, token string) (*Claims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrMalformed
+	}
+	rawSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if !hmac.Equal(rawSignature, sign(secret, encoded)) {
+		return nil, ErrSignature
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrMalformed
+	}
+	if claims.Role != RoleAdmin && claims.Role != RoleUser {
+		return nil, ErrRole
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpired
+	}
+	return &claims, nil
+}
+
+func sign(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
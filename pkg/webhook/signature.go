@@ -0,0 +1,81 @@
+// Package webhook подписывает и проверяет доставки вебхуков. Подпись передается в заголовке
+// X-Banner-Signature: t=<unix-время отправки>,v1=<hex HMAC-SHA256 от "<t>.<тело запроса>">.
+// Время входит в подпись, поэтому перехваченную доставку нельзя повторить позже допустимого окна
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Заголовки доставки
+const (
+	SignatureHeader = "X-Banner-Signature"
+	EventHeader     = "X-Banner-Event"
+	DeliveryHeader  = "X-Banner-Delivery"
+)
+
+var (
+	ErrMalformed = errors.New("malformed webhook signature")
+	ErrSignature = errors.New("invalid webhook signature")
+	ErrExpired   = errors.New("webhook signature expired")
+)
+
+// NewSecret - случайный ключ подписи
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Sign - значение заголовка X-Banner-Signature для тела body, отправленного в момент timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(sign(secret, t, body))
+}
+
+// Verify проверяет подпись доставки и что она отправлена не раньше tolerance назад.
+// tolerance = 0 отключает проверку времени
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var t, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			signature = value
+		}
+	}
+	timestamp, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return ErrMalformed
+	}
+	rawSignature, err := hex.DecodeString(signature)
+	if err != nil || len(rawSignature) == 0 {
+		return ErrMalformed
+	}
+	if !hmac.Equal(rawSignature, sign(secret, t, body)) {
+		return ErrSignature
+	}
+	if tolerance > 0 && time.Since(time.Unix(timestamp, 0)) > tolerance {
+		return ErrExpired
+	}
+	return nil
+}
+
+func sign(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func (s *APITestSuite) auditRequest(router *gin.Engine, method, path, body, requestID string) *httptest.ResponseRecorder {
+	req := s.newRequest(method, path, "admin_token", body)
+	if requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	req.RemoteAddr = "203.0.113.7:40000"
+	// прокси не настроены, подмененный адрес не должен попасть в аудит
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	return s.serve(router, req)
+}
+
+func (s *APITestSuite) TestAudit_RecordsRequestAndDiff() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	r := s.Require()
+	r.NoError(router.SetTrustedProxies(nil))
+	v1.RegisterRoutes(router, s.handler, s.audit)
+
+	resp := s.auditRequest(router, "POST", "/banner", `{"tag_ids": [1, 2], "feature_id": 791, "content": {"title": "audit", "text": "old"}, "is_active": true}`, "create-791")
+	r.Equal(http.StatusCreated, resp.Code, resp.Body.String())
+	r.Equal("create-791", resp.Header().Get("X-Request-ID"))
+	var created struct {
+		BannerID int32 `json:"banner_id"`
+	}
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &created))
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", created.BannerID)
+	resp = s.auditRequest(router, "PATCH", fmt.Sprintf("/banner/%d", created.BannerID), `{"tag_ids": [2, 3], "content": {"title": "audit"}}`, "")
+	r.Equal(http.StatusNoContent, resp.Code, resp.Body.String())
+	generated := resp.Header().Get("X-Request-ID")
+	r.Len(generated, 32)
+
+	resp = s.auditRequest(router, "GET", fmt.Sprintf("/audit?banner_id=%d&actor=admin", created.BannerID), "", "")
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	var entries []*entity.AuditEntry
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &entries))
+	r.Len(entries, 2)
+	updated, inserted := entries[0], entries[1]
+	r.Equal(entity.EventUpdated, updated.Action)
+	r.Equal("admin", updated.Actor)
+	r.Equal("203.0.113.7", updated.ClientIP)
+	r.Equal(generated, updated.RequestID)
+	r.Equal([]entity.AuditChange{
+		{Path: "/content/text", From: "old", To: nil},
+		{Path: "/tag_ids/0", From: 1.0, To: 2.0},
+		{Path: "/tag_ids/1", From: 2.0, To: 3.0},
+	}, updated.Diff)
+	r.Equal(entity.EventCreated, inserted.Action)
+	r.Equal("create-791", inserted.RequestID)
+	r.Contains(inserted.Diff, entity.AuditChange{Path: "/content/title", From: nil, To: "audit"})
+	r.Contains(inserted.Diff, entity.AuditChange{Path: "/feature_id", From: nil, To: 791.0})
+
+	resp = s.auditRequest(router, "GET", fmt.Sprintf("/audit?banner_id=%d&action=created", created.BannerID), "", "")
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &entries))
+	r.Len(entries, 1)
+	r.Equal(inserted.ID, entries[0].ID)
+	resp = s.auditRequest(router, "GET", fmt.Sprintf("/audit?banner_id=%d&actor=nobody", created.BannerID), "", "")
+	r.Equal("[]", resp.Body.String())
+	resp = s.auditRequest(router, "GET", fmt.Sprintf("/audit?banner_id=%d&to=2000-01-01", created.BannerID), "", "")
+	r.Equal("[]", resp.Body.String())
+
+	resp = s.auditRequest(router, "GET", fmt.Sprintf("/audit/export?banner_id=%d", created.BannerID), "", "")
+	r.Equal(http.StatusOK, resp.Code)
+	r.Equal("text/csv", resp.Header().Get("Content-Type"))
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	r.NoError(err)
+	r.Len(records, 3)
+	r.Equal([]string{"id", "event_id", "banner_id", "action", "actor", "client_ip", "request_id", "diff", "created_at"}, records[0])
+	r.Equal([]string{"updated", "admin", "203.0.113.7", generated}, records[1][3:7])
+	r.Contains(records[1][7], `{"path":"/tag_ids/0","from":1,"to":2}`)
+}
+
+func (s *APITestSuite) TestAudit_InvalidRequest() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, v1.NewBannerController(&MockBannerService{}, s.logger, s.messages), v1.NewAuditController(nil, s.logger, s.messages))
+	r := s.Require()
+
+	for path, field := range map[string]string{
+		"/audit?banner_id=0":                              "banner_id",
+		"/audit?banner_id=abc":                            "banner_id",
+		"/audit?action=moved":                             "action",
+		"/audit?limit=1001":                               "limit",
+		"/audit?offset=-1":                                "offset",
+		"/audit?from=yesterday":                           "from",
+		"/audit?from=2024-05-02&to=2024-05-01":            "to",
+		"/audit/export?actor=" + strings.Repeat("a", 201): "actor",
+	} {
+		resp := s.auditRequest(router, "GET", path, "", "")
+		r.Equal(http.StatusBadRequest, resp.Code, path)
+		var result struct {
+			Fields map[string]string `json:"fields"`
+		}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &result))
+		r.Contains(result.Fields, field, path)
+	}
+
+	// идентификатор клиента с пробелами заменяется новым
+	req, _ := http.NewRequest("GET", "/audit", nil)
+	req.Header.Set("token", "user_token")
+	req.Header.Set("X-Request-ID", "bad id")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusForbidden, resp.Code)
+	r.Len(resp.Header().Get("X-Request-ID"), 32)
+}
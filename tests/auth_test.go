@@ -0,0 +1,49 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"banner/pkg/token"
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func (s *APITestSuite) TestAuth_SignedToken() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockService := &MockBannerService{
+		GetBannersFunc: func(ctx context.Context, query *entity.BannersQuery) (*entity.BannersPage, error) {
+			return &entity.BannersPage{Banners: []*entity.FilteredBanner{}}, nil
+		},
+	}
+	secret := "test_secret"
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages).WithTokenSecret(secret))
+	r := s.Require()
+
+	admin, err := token.Issue([]byte(secret), "ops", token.RoleAdmin, time.Hour)
+	s.NoError(err)
+	user, err := token.Issue([]byte(secret), "", token.RoleUser, time.Hour)
+	s.NoError(err)
+	expired, err := token.Issue([]byte(secret), "", token.RoleAdmin, -time.Minute)
+	s.NoError(err)
+	foreign, err := token.Issue([]byte("other_secret"), "", token.RoleAdmin, time.Hour)
+	s.NoError(err)
+
+	for value, status := range map[string]int{
+		admin:         http.StatusOK,
+		"admin_token": http.StatusOK,
+		user:          http.StatusForbidden,
+		expired:       http.StatusUnauthorized,
+		foreign:       http.StatusUnauthorized,
+		"garbage":     http.StatusUnauthorized,
+	} {
+		req, _ := http.NewRequest("GET", "/banner", nil)
+		req.Header.Set("token", value)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		r.Equal(status, resp.Code, value)
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"banner/internal/bannerctl"
+	v1 "banner/internal/controller/http/v1"
+	"banner/pkg/client"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+)
+
+func (s *APITestSuite) newBannerctl(format bannerctl.Format) (*bannerctl.Env, *bytes.Buffer, func()) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	server := httptest.NewServer(router)
+	c, err := client.New(server.URL, client.WithToken("admin_token"))
+	s.Require().NoError(err)
+	out := &bytes.Buffer{}
+	return &bannerctl.Env{Client: c, Out: out, Format: format}, out, server.Close
+}
+
+func (s *APITestSuite) TestBannerctl_DeactivateAndDiff() {
+	env, out, stop := s.newBannerctl(bannerctl.FormatJSON)
+	defer stop()
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	ctx := context.Background()
+
+	r.NoError(bannerctl.Commands["deactivate"].Run(ctx, env, []string{"1"}))
+	var banner client.Banner
+	r.NoError(json.Unmarshal(out.Bytes(), &banner))
+	r.False(banner.IsActive)
+
+	out.Reset()
+	r.NoError(bannerctl.Commands["diff"].Run(ctx, env, []string{"1"}))
+	var changes client.BannerDiff
+	r.NoError(json.Unmarshal(out.Bytes(), &changes))
+	r.Equal(int32(1), changes.From)
+	r.Equal(int32(2), changes.To)
+	r.NotNil(changes.IsActive)
+	r.True(changes.IsActive.From)
+	r.False(changes.IsActive.To)
+	r.Nil(changes.FeatureID)
+	r.Empty(changes.Content)
+
+	out.Reset()
+	env.Format = bannerctl.FormatTable
+	r.NoError(bannerctl.Commands["diff"].Run(ctx, env, []string{"1", "-version", "1"}))
+	r.Contains(out.String(), "--- banner 1 version 1\n+++ banner 1 version 2\n")
+	r.Contains(out.String(), "-  \"is_active\": true")
+	r.Contains(out.String(), "+  \"is_active\": false")
+}
+
+func (s *APITestSuite) TestBannerctl_Apply() {
+	env, out, stop := s.newBannerctl(bannerctl.FormatJSON)
+	defer stop()
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	dir := s.T().TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, "1-existing.yaml"), []byte(`
+id: 1
+tag_ids: [4, 5, 6]
+feature_id: 123
+content:
+  title: applied
+is_active: true
+`), 0o600))
+	r.NoError(os.WriteFile(filepath.Join(dir, "2-new.json"), []byte(`{"tag_ids":[90],"feature_id":900,"content":{"title":"new"},"is_active":true}`), 0o600))
+	r.NoError(os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o600))
+
+	r.NoError(bannerctl.Commands["apply"].Run(context.Background(), env, []string{"-d", dir}))
+	var response client.BulkResponse
+	r.NoError(json.Unmarshal(out.Bytes(), &response))
+	r.Equal(0, response.Failed)
+	r.Len(response.Results, 2)
+	r.Equal("update", response.Results[0].Action)
+	r.Equal("create", response.Results[1].Action)
+	r.NotNil(response.Results[1].ID)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", *response.Results[1].ID)
+
+	banner, err := env.Client.Get(context.Background(), 1)
+	r.NoError(err)
+	r.Equal(map[string]interface{}{"title": "applied"}, banner.Content)
+}
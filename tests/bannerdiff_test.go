@@ -0,0 +1,171 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/pkg/jsondiff"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func (s *APITestSuite) TestBannerDiff_Versions() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	ctx := context.Background()
+
+	id, err := s.service.Save(ctx, &entity.Banner{TagIDs: []int32{1, 2}, FeatureID: 801, Content: map[string]interface{}{"title": "old", "text": "body", "links": []interface{}{"a"}}, IsActive: true})
+	r.NoError(err)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", id)
+	content := map[string]interface{}{"title": "new", "links": []interface{}{"a", "b"}}
+	tagIDs := []int32{2, 3}
+	r.NoError(s.service.Update(ctx, &entity.BannerUpdate{ID: &id, TagIDs: &tagIDs, Content: &content}))
+	isActive := false
+	featureID := int32(802)
+	r.NoError(s.service.Update(ctx, &entity.BannerUpdate{ID: &id, IsActive: &isActive, FeatureID: &featureID}))
+
+	resp := s.request(router, "GET", fmt.Sprintf("/banner/%d/diff?from=1", id), "admin_token", "")
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	var diff entity.BannerDiff
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &diff))
+	r.Equal(int32(1), diff.From)
+	r.Equal(int32(3), diff.To)
+	r.Equal([]int32{3}, diff.TagIDs.Added)
+	r.Equal([]int32{1}, diff.TagIDs.Removed)
+	r.Equal(&entity.FeatureIDChange{From: 801, To: 802}, diff.FeatureID)
+	r.Equal(&entity.IsActiveChange{From: true, To: false}, diff.IsActive)
+	r.Equal([]jsondiff.Operation{
+		{Op: "add", Path: "/links/1", Value: "b"},
+		{Op: "remove", Path: "/text"},
+		{Op: "replace", Path: "/title", Value: "new"},
+	}, diff.Content)
+	r.Empty(diff.Unified)
+	r.Contains(resp.Body.String(), `{"op":"remove","path":"/text"}`)
+
+	resp = s.request(router, "GET", fmt.Sprintf("/banner/%d/diff?from=2&to=3&unified=true", id), "admin_token", "")
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	diff = entity.BannerDiff{}
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &diff))
+	r.Empty(diff.TagIDs.Added)
+	r.Empty(diff.Content)
+	r.NotNil(diff.FeatureID)
+	r.True(strings.HasPrefix(diff.Unified, fmt.Sprintf("--- banner %d version 2\n+++ banner %d version 3\n", id, id)))
+	r.Contains(diff.Unified, "\n-  \"feature_id\": 801,\n")
+	r.Contains(diff.Unified, "\n+  \"feature_id\": 802,\n")
+
+	resp = s.request(router, "GET", fmt.Sprintf("/banner/%d/diff?from=99", id), "admin_token", "")
+	r.Equal(http.StatusNotFound, resp.Code)
+}
+
+func (s *APITestSuite) TestBannerDiff_VersionsOutsideHistory() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	ctx := context.Background()
+
+	id, err := s.service.Save(ctx, &entity.Banner{TagIDs: []int32{1}, FeatureID: 803, Content: map[string]interface{}{"title": "v1"}, IsActive: true})
+	r.NoError(err)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners_history WHERE id = $1", id)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", id)
+	for i := 2; i <= 6; i++ {
+		content := map[string]interface{}{"title": fmt.Sprintf("v%d", i)}
+		r.NoError(s.service.Update(ctx, &entity.BannerUpdate{ID: &id, Content: &content}))
+	}
+	history, err := s.repo.GetBannersHistoryByID(ctx, id)
+	r.NoError(err)
+	r.Len(history, 3)
+
+	// версии 1 и 2 уже вытеснены из истории, но есть в журнале событий
+	resp := s.request(router, "GET", fmt.Sprintf("/banner/%d/diff?from=1&to=2", id), "admin_token", "")
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	var diff entity.BannerDiff
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &diff))
+	r.Equal([]jsondiff.Operation{{Op: "replace", Path: "/title", Value: "v2"}}, diff.Content)
+	r.Nil(diff.IsActive)
+	r.Empty(diff.TagIDs.Added)
+}
+
+func (s *APITestSuite) TestBannerDiff_InvalidRequest() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var calls int
+	v1.RegisterRoutes(router, v1.NewBannerController(&MockBannerService{
+		DiffFunc: func(ctx context.Context, id, from int32, to *int32, unified bool) (*entity.BannerDiff, error) {
+			calls++
+			switch id {
+			case 404:
+				return nil, repository.ErrBannerNotFound
+			case 500:
+				return nil, errors.New("db is down")
+			}
+			return nil, repository.ErrVersionNotFound
+		},
+	}, s.logger, s.messages))
+	r := s.Require()
+
+	for path, field := range map[string]string{
+		"/banner/1/diff":                     "from",
+		"/banner/1/diff?from=abc":            "from",
+		"/banner/1/diff?from=0":              "from",
+		"/banner/1/diff?from=1&to=-1":        "to",
+		"/banner/1/diff?from=1&unified=sure": "unified",
+	} {
+		resp := s.request(router, "GET", path, "admin_token", "")
+		r.Equal(http.StatusBadRequest, resp.Code, path)
+		var result struct {
+			Fields map[string]string `json:"fields"`
+		}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &result))
+		r.Contains(result.Fields, field, path)
+	}
+	r.Equal(http.StatusBadRequest, s.request(router, "GET", "/banner/abc/diff?from=1", "admin_token", "").Code)
+	r.Zero(calls)
+
+	for path, status := range map[string]int{
+		"/banner/404/diff?from=1": http.StatusNotFound,
+		"/banner/1/diff?from=7":   http.StatusNotFound,
+		"/banner/500/diff?from=1": http.StatusInternalServerError,
+	} {
+		r.Equal(status, s.request(router, "GET", path, "admin_token", "").Code, path)
+	}
+
+	req, _ := http.NewRequest("GET", "/banner/1/diff?from=1", nil)
+	req.Header.Set("token", "user_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusForbidden, resp.Code)
+}
+
+func (s *APITestSuite) TestBannerDiff_LargeUnified() {
+	from, to := make([]string, 2000), make([]string, 2000)
+	for i := range from {
+		from[i] = fmt.Sprintf("old %d", i)
+		to[i] = fmt.Sprintf("new %d", i)
+	}
+	// измененная середина больше предела таблицы: строки выводятся целиком как удаленные и добавленные
+	unified, err := jsondiff.Unified("from", "to", map[string]interface{}{"lines": from}, map[string]interface{}{"lines": to})
+	s.Require().NoError(err)
+	s.Contains(unified, "@@ -1,2004 +1,2004 @@\n")
+	s.Equal(2000, strings.Count(unified, "\n-    \"old "))
+	s.Equal(2000, strings.Count(unified, "\n+    \"new "))
+}
+
+func (s *APITestSuite) TestBannerDiff_Changes() {
+	from := map[string]interface{}{"content": map[string]interface{}{"a/b": "old", "title": "same"}, "tag_ids": []int32{1, 2}}
+	to := map[string]interface{}{"content": map[string]interface{}{"title": "same", "text": "new"}, "tag_ids": []int32{2}}
+	s.Equal([]jsondiff.Change{
+		{Path: "/content/a~1b", From: "old", To: nil},
+		{Path: "/content/text", From: nil, To: "new"},
+		{Path: "/tag_ids/0", From: 1.0, To: 2.0},
+		{Path: "/tag_ids/1", From: 2.0, To: nil},
+	}, jsondiff.Changes(from, to))
+}
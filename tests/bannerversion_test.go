@@ -0,0 +1,134 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func (s *APITestSuite) TestBannerVersion_IfMatch() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	req, _ := http.NewRequest("GET", "/banner/1", nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Result().StatusCode)
+	etag := resp.Header().Get("ETag")
+	r.Equal(`"1"`, etag)
+
+	updateReq, _ := http.NewRequest("PATCH", "/banner/1", strings.NewReader(`{"is_active": false}`))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateReq.Header.Set("If-Match", etag)
+	updateReq.Header.Set("token", "admin_token")
+	updateResp := httptest.NewRecorder()
+	router.ServeHTTP(updateResp, updateReq)
+	r.Equal(http.StatusNoContent, updateResp.Result().StatusCode)
+
+	// второй администратор отправляет изменение, сделанное по устаревшей версии
+	staleReq, _ := http.NewRequest("PATCH", "/banner/1", strings.NewReader(`{"is_active": true}`))
+	staleReq.Header.Set("Content-Type", "application/json")
+	staleReq.Header.Set("If-Match", etag)
+	staleReq.Header.Set("token", "admin_token")
+	staleResp := httptest.NewRecorder()
+	router.ServeHTTP(staleResp, staleReq)
+	r.Equal(http.StatusPreconditionFailed, staleResp.Result().StatusCode)
+
+	banner, err := s.repo.GetByID(context.Background(), 1)
+	s.NoError(err)
+	r.False(banner.IsActive)
+	r.Equal(int32(2), banner.Version)
+}
+func (s *APITestSuite) TestBannerVersion_DeletePreconditionFailed() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	req, _ := http.NewRequest("DELETE", "/banner/1", nil)
+	req.Header.Set("If-Match", `"7"`)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusPreconditionFailed, resp.Result().StatusCode)
+
+	_, err := s.repo.GetByID(context.Background(), 1)
+	s.NoError(err)
+}
+func (s *APITestSuite) TestBannerVersion_Rollback() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	updateReq, _ := http.NewRequest("PATCH", "/banner/1", strings.NewReader(`{"content": {"title": "new_title"}}`))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateReq.Header.Set("token", "admin_token")
+	updateResp := httptest.NewRecorder()
+	router.ServeHTTP(updateResp, updateReq)
+	r.Equal(http.StatusNoContent, updateResp.Result().StatusCode)
+
+	historyReq, _ := http.NewRequest("GET", "/banner/history/1", nil)
+	historyReq.Header.Set("token", "admin_token")
+	historyResp := httptest.NewRecorder()
+	router.ServeHTTP(historyResp, historyReq)
+	r.Equal(http.StatusOK, historyResp.Result().StatusCode)
+	r.Equal(`"2"`, historyResp.Header().Get("ETag"))
+
+	rollbackReq, _ := http.NewRequest("POST", "/banner/1/rollback", strings.NewReader(`{"version": 1}`))
+	rollbackReq.Header.Set("Content-Type", "application/json")
+	rollbackReq.Header.Set("If-Match", historyResp.Header().Get("ETag"))
+	rollbackReq.Header.Set("token", "admin_token")
+	rollbackResp := httptest.NewRecorder()
+	router.ServeHTTP(rollbackResp, rollbackReq)
+	r.Equal(http.StatusNoContent, rollbackResp.Result().StatusCode)
+
+	banner, err := s.repo.GetByID(context.Background(), 1)
+	s.NoError(err)
+	r.Equal("some_title", banner.Content["title"])
+	r.Equal(int32(3), banner.Version)
+}
+func (s *APITestSuite) TestBannerVersion_RollbackAfterOtherBannerChanges() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	ctx := context.Background()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	r.Equal(http.StatusNoContent, s.request(router, "PATCH", "/banner/1", "admin_token", `{"content": {"title": "new_title"}}`).Code)
+
+	// правки другого баннера не вытесняют историю первого
+	other, err := s.service.Save(ctx, &entity.Banner{TagIDs: []int32{7}, FeatureID: 124, Content: map[string]interface{}{"title": "other"}, IsActive: true})
+	r.NoError(err)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners_history WHERE id = $1", other)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", other)
+	for i := 0; i < 4; i++ {
+		resp := s.request(router, "PATCH", fmt.Sprintf("/banner/%d", other), "admin_token", fmt.Sprintf(`{"content": {"title": "other %d"}}`, i))
+		r.Equal(http.StatusNoContent, resp.Code, resp.Body.String())
+	}
+	history, err := s.repo.GetBannersHistoryByID(ctx, other)
+	r.NoError(err)
+	r.Len(history, 3)
+
+	resp := s.request(router, "POST", "/banner/1/rollback", "admin_token", `{"version": 1}`)
+	r.Equal(http.StatusNoContent, resp.Code, resp.Body.String())
+	banner, err := s.repo.GetByID(ctx, 1)
+	r.NoError(err)
+	r.Equal("some_title", banner.Content["title"])
+}
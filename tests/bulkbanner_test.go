@@ -0,0 +1,136 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+type bulkResponse struct {
+	Failed  int                  `json:"failed"`
+	Results []*entity.BulkResult `json:"results"`
+}
+
+func (s *APITestSuite) postBulk(router *gin.Engine, status int, body string) bulkResponse {
+	req, _ := http.NewRequest("POST", "/banner/bulk", strings.NewReader(body))
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	s.Require().Equal(status, resp.Code)
+	var result bulkResponse
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &result))
+	return result
+}
+
+func (s *APITestSuite) countBanners(featureID int32) int {
+	var count int
+	err := s.db.Pool.QueryRow(context.Background(), "SELECT count(*) FROM banners WHERE feature_id = $1 AND deleted_at IS NULL", featureID).Scan(&count)
+	s.NoError(err)
+	return count
+}
+
+func (s *APITestSuite) TestBulkBanners_AtomicRollback() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+
+	result := s.postBulk(router, http.StatusConflict, `{"operations": [
+		{"action": "create", "banner": {"tag_ids": [1], "feature_id": 401, "content": {"title": "a"}, "is_active": true}},
+		{"action": "deactivate", "id": 99999}
+	]}`)
+	r.Equal(1, result.Failed)
+	r.Equal(entity.BulkRolledBack, result.Results[0].Status)
+	r.Equal(entity.BulkFailed, result.Results[1].Status)
+	r.Equal(0, s.countBanners(401))
+}
+
+func (s *APITestSuite) TestBulkBanners_BestEffort() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	defer func() {
+		_, err := s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE feature_id = $1", 402)
+		s.NoError(err)
+	}()
+
+	result := s.postBulk(router, http.StatusOK, `{"mode": "best_effort", "operations": [
+		{"action": "create", "banner": {"tag_ids": [1], "feature_id": 402, "content": {"title": "a"}, "is_active": true}},
+		{"action": "delete", "id": 99999},
+		{"action": "create", "banner": {"tag_ids": [2], "feature_id": 402, "content": {"title": "b"}, "is_active": true}}
+	]}`)
+	r.Equal(1, result.Failed)
+	r.Equal(entity.BulkOK, result.Results[0].Status)
+	r.Equal(entity.BulkFailed, result.Results[1].Status)
+	r.Equal(entity.BulkOK, result.Results[2].Status)
+	r.Equal(2, s.countBanners(402))
+}
+
+func (s *APITestSuite) TestBulkBanners_InvalidatesCache() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	getBanner := func() string {
+		req, _ := http.NewRequest("GET", "/user_banner?tag_id=5&feature_id=123", nil)
+		req.Header.Set("token", "admin_token")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		r.Equal(http.StatusOK, resp.Code)
+		return resp.Body.String()
+	}
+	before := getBanner()
+
+	result := s.postBulk(router, http.StatusOK, `{"operations": [
+		{"action": "update", "id": 1, "update": {"content": {"title": "bulk_title"}}}
+	]}`)
+	r.Equal(0, result.Failed)
+	r.NotEqual(before, getBanner())
+	r.Contains(getBanner(), "bulk_title")
+}
+
+func (s *APITestSuite) TestBulkBanners_DuplicateIDs() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	r := s.Require()
+	var modes []entity.BulkMode
+	service := &MockBannerService{
+		BulkFunc: func(ctx context.Context, request *entity.BulkRequest) ([]*entity.BulkResult, error) {
+			modes = append(modes, request.Mode)
+			results := make([]*entity.BulkResult, len(request.Operations))
+			for i, operation := range request.Operations {
+				results[i] = &entity.BulkResult{Index: i, Action: operation.Action, ID: operation.ID, Status: entity.BulkOK}
+			}
+			return results, nil
+		},
+	}
+	v1.RegisterRoutes(router, v1.NewBannerController(service, s.logger, s.messages))
+
+	operations := `[
+		{"action": "update", "id": 1, "update": {"is_active": false}},
+		{"action": "create", "banner": {"tag_ids": [1], "feature_id": 403, "content": {}}},
+		{"action": "delete", "id": 1}
+	]`
+	resp := s.request(router, "POST", "/banner/bulk", "admin_token", `{"operations": `+operations+`}`)
+	r.Equal(http.StatusBadRequest, resp.Code)
+	var result struct {
+		Fields map[string]string `json:"fields"`
+	}
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &result))
+	r.Equal(map[string]string{"operations[2].id": "Баннер уже изменяет другая операция"}, result.Fields)
+	r.Empty(modes)
+
+	// независимые операции best_effort могут менять один баннер
+	s.postBulk(router, http.StatusOK, `{"mode": "best_effort", "operations": `+operations+`}`)
+	r.Equal([]entity.BulkMode{entity.BulkBestEffort}, modes)
+}
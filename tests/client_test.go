@@ -0,0 +1,302 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/internal/service"
+	"banner/pkg/client"
+	"banner/pkg/jsondiff"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// newClientServer поднимает сервис с mockService и клиент к нему. Первые failures запросов
+// получают 503, чтобы проверить повторы
+func (s *APITestSuite) newClientServer(mockService *MockBannerService, failures int32, opts ...client.Option) (*client.Client, *atomic.Int32, func()) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if requests.Add(1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		router.ServeHTTP(w, req)
+	}))
+	opts = append([]client.Option{
+		client.WithToken("admin_token"),
+		client.WithRetry(client.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}),
+	}, opts...)
+	c, err := client.New(server.URL, opts...)
+	s.Require().NoError(err)
+	return c, &requests, server.Close
+}
+
+func (s *APITestSuite) TestClient_GetForUser() {
+	mockService := &MockBannerService{
+		GetForUserFunc: func(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (*entity.UserBanner, error) {
+			s.Equal(int32(4), tagID)
+			s.Equal(int32(123), featureID)
+			s.True(lastRevision)
+			return &entity.UserBanner{Content: map[string]interface{}{"title": "some_title"}}, nil
+		},
+	}
+	c, _, stop := s.newClientServer(mockService, 0, client.WithToken("user_token"))
+	defer stop()
+
+	content, err := c.GetForUser(context.Background(), 4, 123, true)
+	s.Require().NoError(err)
+	s.Equal(map[string]interface{}{"title": "some_title"}, content)
+
+	_, err = c.GetBanners(context.Background(), nil)
+	s.ErrorIs(err, client.ErrForbidden)
+}
+
+func (s *APITestSuite) TestClient_SaveUpdateHistory() {
+	isActive := false
+	mockService := &MockBannerService{
+		SaveFunc: func(ctx context.Context, banner *entity.Banner) (int32, error) {
+			s.Equal(int32(123), banner.FeatureID)
+			s.Equal([]int32{4, 5}, banner.TagIDs)
+			return 7, nil
+		},
+		UpdateFunc: func(ctx context.Context, banner *entity.BannerUpdate) error {
+			s.Equal(int32(7), *banner.ID)
+			s.Equal(&isActive, banner.IsActive)
+			s.Nil(banner.FeatureID)
+			return nil
+		},
+		GetBannersHistoryByIDFunc: func(ctx context.Context, id int32) ([]*entity.BannerHistoryItem, error) {
+			return []*entity.BannerHistoryItem{{Index: 1, Banner: &entity.FilteredBanner{ID: id, Version: 1}}}, nil
+		},
+		GetFunc: func(ctx context.Context, id int32) (*entity.FilteredBanner, error) {
+			return &entity.FilteredBanner{ID: id, Version: 2}, nil
+		},
+		DiffFunc: func(ctx context.Context, id, from int32, to *int32, unified bool) (*entity.BannerDiff, error) {
+			s.Equal(int32(1), from)
+			s.Nil(to)
+			s.True(unified)
+			return &entity.BannerDiff{ID: id, From: from, To: 2, IsActive: &entity.IsActiveChange{From: true}, Content: []jsondiff.Operation{}}, nil
+		},
+		GetBannersFunc: func(ctx context.Context, query *entity.BannersQuery) (*entity.BannersPage, error) {
+			s.Equal(int32(123), *query.FeatureID)
+			return &entity.BannersPage{Banners: []*entity.FilteredBanner{{ID: 7, FeatureID: 123}}}, nil
+		},
+	}
+	c, _, stop := s.newClientServer(mockService, 0)
+	defer stop()
+	ctx := context.Background()
+	r := s.Require()
+
+	id, err := c.Save(ctx, &client.NewBanner{TagIDs: []int32{4, 5}, FeatureID: 123, Content: map[string]interface{}{"title": "t"}, IsActive: true})
+	r.NoError(err)
+	r.Equal(int32(7), id)
+
+	r.NoError(c.Update(ctx, id, &client.BannerUpdate{IsActive: &isActive}))
+
+	featureID := int32(123)
+	banners, err := c.GetBanners(ctx, &client.BannersQuery{FeatureID: &featureID})
+	r.NoError(err)
+	r.Len(banners, 1)
+	r.Equal(int32(7), banners[0].ID)
+
+	history, err := c.GetBannersHistoryByID(ctx, id)
+	r.NoError(err)
+	r.Len(history, 1)
+	r.Equal(int32(1), history[0].Banner.Version)
+
+	diff, err := c.Diff(ctx, id, 1, nil, true)
+	r.NoError(err)
+	r.Equal(int32(2), diff.To)
+	r.True(diff.IsActive.From)
+	r.Nil(diff.FeatureID)
+}
+
+func (s *APITestSuite) TestClient_TypedErrors() {
+	mockService := &MockBannerService{
+		GetFunc: func(ctx context.Context, id int32) (*entity.FilteredBanner, error) {
+			return nil, repository.ErrBannerNotFound
+		},
+		DeleteFunc: func(ctx context.Context, id int32, version *int32) error {
+			s.Equal(int32(2), *version)
+			return repository.ErrVersionMismatch
+		},
+		SaveFunc: func(ctx context.Context, banner *entity.Banner) (int32, error) {
+			return 0, errors.New("internal server error")
+		},
+	}
+	c, _, stop := s.newClientServer(mockService, 0)
+	defer stop()
+	ctx := context.Background()
+
+	_, err := c.Get(ctx, 1)
+	s.ErrorIs(err, client.ErrNotFound)
+
+	version := int32(2)
+	err = c.Delete(ctx, 1, &version)
+	s.ErrorIs(err, client.ErrPreconditionFailed)
+	var apiErr *client.APIError
+	s.Require().ErrorAs(err, &apiErr)
+	s.Equal(http.StatusPreconditionFailed, apiErr.StatusCode)
+
+	_, err = c.Save(ctx, &client.NewBanner{TagIDs: []int32{1}, FeatureID: 1, Content: map[string]interface{}{}})
+	s.ErrorIs(err, client.ErrServer)
+	s.NotErrorIs(err, client.ErrNotFound)
+
+	_, err = c.Save(ctx, &client.NewBanner{})
+	s.ErrorIs(err, client.ErrBadRequest)
+	s.Require().ErrorAs(err, &apiErr)
+	s.NotEmpty(apiErr.Fields)
+}
+
+func (s *APITestSuite) TestClient_Import() {
+	mockService := &MockBannerService{
+		ImportFunc: func(ctx context.Context, r io.Reader, options *entity.ImportOptions) (*entity.ImportReport, error) {
+			body, err := io.ReadAll(r)
+			s.Require().NoError(err)
+			if options.Format != entity.FormatCSV {
+				return nil, fmt.Errorf("%w: line 1: unexpected end of JSON input", service.ErrInvalidImport)
+			}
+			s.Equal("id,tag_ids,feature_id,content,is_active\n", string(body))
+			s.Equal(entity.ConflictOverwrite, options.Conflict)
+			s.True(options.DryRun)
+			return &entity.ImportReport{DryRun: true, Total: 1, Updated: 1}, nil
+		},
+	}
+	c, _, stop := s.newClientServer(mockService, 0)
+	defer stop()
+	ctx := context.Background()
+
+	report, err := c.Import(ctx, strings.NewReader("id,tag_ids,feature_id,content,is_active\n"),
+		&client.ImportOptions{Format: "csv", Conflict: "overwrite", DryRun: true})
+	s.Require().NoError(err)
+	s.Equal(&client.ImportReport{DryRun: true, Total: 1, Updated: 1}, report)
+
+	_, err = c.Import(ctx, strings.NewReader("{"), nil)
+	s.ErrorIs(err, client.ErrBadRequest)
+	var apiErr *client.APIError
+	s.Require().ErrorAs(err, &apiErr)
+	s.Contains(apiErr.Details, "line 1")
+}
+
+func (s *APITestSuite) TestClient_BulkRolledBack() {
+	mockService := &MockBannerService{
+		BulkFunc: func(ctx context.Context, request *entity.BulkRequest) ([]*entity.BulkResult, error) {
+			id := int32(7)
+			results := []*entity.BulkResult{
+				{Index: 0, Action: entity.BulkCreate, ID: &id, Status: entity.BulkOK},
+				{Index: 1, Action: entity.BulkDeactivate, Status: entity.BulkFailed, Err: repository.ErrBannerNotFound},
+			}
+			if request.Mode == entity.BulkAtomic {
+				results[0].Status = entity.BulkRolledBack
+			}
+			return results, nil
+		},
+	}
+	c, _, stop := s.newClientServer(mockService, 0)
+	defer stop()
+	id := int32(99)
+	operations := []client.BulkOperation{
+		{Action: "create", Banner: &client.NewBanner{TagIDs: []int32{1}, FeatureID: 1, Content: map[string]interface{}{}}},
+		{Action: "deactivate", ID: &id},
+	}
+
+	// отмененный атомарный пакет - ошибка, но результаты операций доступны
+	response, err := c.Bulk(context.Background(), &client.BulkRequest{Operations: operations})
+	s.ErrorIs(err, client.ErrConflict)
+	s.Require().NotNil(response)
+	s.Equal(1, response.Failed)
+	s.Equal("rolled_back", response.Results[0].Status)
+	s.NotEmpty(response.Results[1].Error)
+
+	response, err = c.Bulk(context.Background(), &client.BulkRequest{Mode: "best_effort", Operations: operations})
+	s.Require().NoError(err)
+	s.Equal("ok", response.Results[0].Status)
+}
+
+func (s *APITestSuite) TestClient_Retry() {
+	mockService := &MockBannerService{
+		GetFunc: func(ctx context.Context, id int32) (*entity.FilteredBanner, error) {
+			return &entity.FilteredBanner{ID: id}, nil
+		},
+		RollbackFunc: func(ctx context.Context, id, version int32, expectedVersion *int32) error {
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, id int32, version *int32) error {
+			return nil
+		},
+	}
+	c, requests, stop := s.newClientServer(mockService, 2)
+	defer stop()
+	ctx := context.Background()
+
+	banner, err := c.Get(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal(int32(1), banner.ID)
+	s.Equal(int32(3), requests.Load())
+
+	// POST без Idempotency-Key не повторяется
+	requests.Store(0)
+	err = c.Rollback(ctx, 1, 1, nil)
+	s.ErrorIs(err, client.ErrServer)
+	s.Equal(int32(1), requests.Load())
+
+	// DELETE одного баннера повторяется, массовое удаление ставит задачу и не повторяется
+	requests.Store(0)
+	s.Require().NoError(c.Delete(ctx, 1, nil))
+	s.Equal(int32(3), requests.Load())
+	requests.Store(0)
+	featureID := int32(1)
+	_, err = c.DeleteBanners(ctx, &featureID, nil)
+	s.ErrorIs(err, client.ErrServer)
+	s.Equal(int32(1), requests.Load())
+
+	// попытки кончились раньше, чем сервис восстановился
+	requests.Store(-10)
+	_, err = c.Get(ctx, 1)
+	s.ErrorIs(err, client.ErrServer)
+	s.Equal(int32(-7), requests.Load())
+}
+
+func (s *APITestSuite) TestClient_Auth() {
+	mockService := &MockBannerService{
+		GetFunc: func(ctx context.Context, id int32) (*entity.FilteredBanner, error) {
+			return &entity.FilteredBanner{ID: id}, nil
+		},
+	}
+	var calls int
+	auth := client.AuthenticatorFunc(func(req *http.Request) error {
+		calls++
+		req.Header.Set("token", "admin_token")
+		return nil
+	})
+	c, _, stop := s.newClientServer(mockService, 1, client.WithAuth(auth))
+	defer stop()
+
+	_, err := c.Get(context.Background(), 1)
+	s.Require().NoError(err)
+	s.Equal(2, calls)
+
+	failing, _, stopFailing := s.newClientServer(mockService, 0, client.WithAuth(client.AuthenticatorFunc(func(req *http.Request) error {
+		return errors.New("token expired")
+	})))
+	defer stopFailing()
+	_, err = failing.Get(context.Background(), 1)
+	s.ErrorContains(err, "token expired")
+
+	anonymous, _, stopAnonymous := s.newClientServer(mockService, 0, client.WithToken("garbage"))
+	defer stopAnonymous()
+	_, err = anonymous.Get(context.Background(), 1)
+	s.ErrorIs(err, client.ErrUnauthorized)
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/pkg/client"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (s *APITestSuite) TestClientCache_RefreshAndServeStale() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var (
+		title      atomic.Value
+		down       atomic.Bool
+		notChanged atomic.Int32
+	)
+	title.Store("first")
+	mockService := &MockBannerService{
+		GetForUserFunc: func(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (*entity.UserBanner, error) {
+			if down.Load() {
+				return nil, errors.New("internal server error")
+			}
+			if featureID == 2 {
+				return nil, repository.ErrBannerNotFound
+			}
+			current := title.Load().(string)
+			return &entity.UserBanner{Content: map[string]interface{}{"title": current}, ETag: `"` + current + `"`}, nil
+		},
+	}
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+		router.ServeHTTP(recorder, req)
+		if recorder.status == http.StatusNotModified {
+			notChanged.Add(1)
+		}
+	}))
+	defer server.Close()
+	c, err := client.New(server.URL, client.WithToken("user_token"), client.WithRetry(client.RetryPolicy{MaxAttempts: 1}))
+	s.Require().NoError(err)
+
+	var (
+		mu     sync.Mutex
+		stales []time.Duration
+	)
+	cache := client.NewBannerCache(c, client.CacheOptions{
+		Keys:            []client.BannerKey{{TagID: 1, FeatureID: 1}, {TagID: 1, FeatureID: 2}},
+		RefreshInterval: 10 * time.Millisecond,
+		OnStale: func(key client.BannerKey, age time.Duration, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if key.FeatureID == 1 {
+				stales = append(stales, age)
+			}
+		},
+	})
+	ctx := context.Background()
+	cache.Start(ctx)
+	defer cache.Stop()
+	r := s.Require()
+
+	content, err := cache.Get(ctx, 1, 1)
+	r.NoError(err)
+	r.Equal("first", content["title"])
+	_, err = cache.Get(ctx, 1, 2)
+	r.ErrorIs(err, client.ErrNotFound)
+
+	// без изменений баннер перепроверяется по ETag
+	r.Eventually(func() bool { return notChanged.Load() >= 2 }, time.Second, 5*time.Millisecond)
+
+	title.Store("second")
+	r.Eventually(func() bool {
+		content, err := cache.Get(ctx, 1, 1)
+		return err == nil && content["title"] == "second"
+	}, time.Second, 5*time.Millisecond)
+
+	// сервис недоступен: отдается последняя версия, устаревание сообщается через OnStale
+	down.Store(true)
+	r.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(stales) >= 2 && stales[len(stales)-1] > stales[0]
+	}, time.Second, 5*time.Millisecond)
+	content, err = cache.Get(ctx, 1, 1)
+	r.NoError(err)
+	r.Equal("second", content["title"])
+
+	// баннер не из Keys запрашивается у сервиса
+	_, err = cache.Get(ctx, 5, 5)
+	r.ErrorIs(err, client.ErrServer)
+}
+
+func (s *APITestSuite) TestClientCache_DefaultRefreshInterval() {
+	mockService := &MockBannerService{
+		GetForUserFunc: func(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (*entity.UserBanner, error) {
+			return &entity.UserBanner{Content: map[string]interface{}{"title": "cached"}}, nil
+		},
+	}
+	c, _, stop := s.newClientServer(mockService, 0, client.WithToken("user_token"))
+	defer stop()
+
+	// без RefreshInterval кэш обновляется с периодом по умолчанию, а не падает при запуске
+	cache := client.NewBannerCache(c, client.CacheOptions{Keys: []client.BannerKey{{TagID: 1, FeatureID: 1}}})
+	ctx := context.Background()
+	cache.Start(ctx)
+	defer cache.Stop()
+	content, err := cache.Get(ctx, 1, 1)
+	s.Require().NoError(err)
+	s.Equal("cached", content["title"])
+}
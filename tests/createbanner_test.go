@@ -4,6 +4,7 @@ import (
 	v1 "banner/internal/controller/http/v1"
 	"banner/internal/entity"
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"io"
@@ -93,7 +94,7 @@ func (s *APITestSuite) TestCreateBanner_InternalServerError() {
 			return -1, errors.New("internal server error")
 		},
 	}
-	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger))
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
 	r := s.Require()
 	requestBody := `{
 		"tag_ids": [4, 5, 6],
@@ -115,3 +116,29 @@ func (s *APITestSuite) TestCreateBanner_InternalServerError() {
 	s.NoError(err)
 	r.Equal("{\"error\":\"Внутренняя ошибка сервера\"}", string(responseBody))
 }
+func (s *APITestSuite) TestCreateBanner_ValidationError() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	requestBody := `{
+		"tag_ids": [4, 4],
+		"feature_id": 0,
+		"is_active": true
+	}`
+	req, _ := http.NewRequest("POST", "/banner", strings.NewReader(requestBody))
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Result().StatusCode)
+	var body struct {
+		Error  string            `json:"error"`
+		Fields map[string]string `json:"fields"`
+	}
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &body))
+	r.Equal("Некорректные данные", body.Error)
+	r.Contains(body.Fields, "tag_ids")
+	r.Contains(body.Fields, "feature_id")
+	r.Contains(body.Fields, "content")
+}
@@ -88,11 +88,11 @@ func (s *APITestSuite) TestDeleteBanner_InternalServerError() {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	mockService := &MockBannerService{
-		DeleteFunc: func(ctx context.Context, id int32) error {
+		DeleteFunc: func(ctx context.Context, id int32, version *int32) error {
 			return errors.New("internal server error")
 		},
 	}
-	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger))
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
 	r := s.Require()
 
 	req, _ := http.NewRequest("DELETE", "/banner/1", nil)
@@ -0,0 +1,243 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"banner/pkg/token"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"time"
+)
+
+func (s *APITestSuite) TestDrafts_ReviewAndPublish() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	secret := "draft_secret"
+	v1.RegisterRoutes(router, v1.NewBannerController(s.service, s.logger, s.messages).WithTokenSecret(secret), s.drafts)
+	r := s.Require()
+	ctx := context.Background()
+
+	alice, err := token.Issue([]byte(secret), "alice", token.RoleAdmin, time.Hour)
+	r.NoError(err)
+	bob, err := token.Issue([]byte(secret), "bob", token.RoleAdmin, time.Hour)
+	r.NoError(err)
+	id, err := s.service.Save(ctx, &entity.Banner{TagIDs: []int32{1}, FeatureID: 901, Content: map[string]interface{}{"title": "published"}, IsActive: true})
+	r.NoError(err)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banner_drafts WHERE banner_id = $1", id)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", id)
+	userTitle := func() string {
+		resp := s.request(router, "GET", "/user_banner?tag_id=1&feature_id=901&use_last_revision=true", "user_token", "")
+		r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+		var content map[string]interface{}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &content))
+		return content["title"].(string)
+	}
+
+	resp := s.request(router, "POST", fmt.Sprintf("/banner/%d/drafts", id), alice, `{"content": {"title": "draft"}}`)
+	r.Equal(http.StatusCreated, resp.Code, resp.Body.String())
+	var draft entity.BannerDraft
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &draft))
+	r.Equal(entity.DraftOpen, draft.Status)
+	r.Equal("alice", draft.Author)
+	r.Equal(int32(1), draft.BaseVersion)
+	r.Equal([]int32{1}, draft.TagIDs)
+	r.Equal("published", userTitle())
+
+	draftPath := fmt.Sprintf("/drafts/%d", draft.ID)
+	resp = s.request(router, "POST", fmt.Sprintf("/banner/%d/drafts", id), bob, `{"is_active": false}`)
+	r.Equal(http.StatusConflict, resp.Code)
+	resp = s.request(router, "PATCH", draftPath, bob, `{"content": {"title": "bob"}}`)
+	r.Equal(http.StatusForbidden, resp.Code)
+	resp = s.request(router, "POST", draftPath+"/approve", bob, "")
+	r.Equal(http.StatusConflict, resp.Code)
+
+	resp = s.request(router, "POST", draftPath+"/submit", alice, "")
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	resp = s.request(router, "PATCH", draftPath, alice, `{"content": {"title": "late"}}`)
+	r.Equal(http.StatusConflict, resp.Code)
+	resp = s.request(router, "POST", draftPath+"/approve", alice, "")
+	r.Equal(http.StatusForbidden, resp.Code)
+	resp = s.request(router, "POST", draftPath+"/reject", bob, `{"comment": "fix the title"}`)
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	draft = entity.BannerDraft{}
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &draft))
+	r.Equal(entity.DraftRejected, draft.Status)
+	r.Equal("bob", draft.Reviewer)
+	r.Equal("fix the title", draft.Comment)
+
+	resp = s.request(router, "PATCH", draftPath, alice, `{"content": {"title": "reviewed"}}`)
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	r.Equal(http.StatusOK, s.request(router, "POST", draftPath+"/submit", alice, "").Code)
+	resp = s.request(router, "GET", "/drafts?status=pending&banner_id="+fmt.Sprint(id), bob, "")
+	r.Equal(http.StatusOK, resp.Code)
+	var queue []*entity.BannerDraft
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &queue))
+	r.Len(queue, 1)
+	resp = s.request(router, "GET", draftPath+"/diff", bob, "")
+	r.Equal(http.StatusOK, resp.Code)
+	r.Contains(resp.Body.String(), `{"op":"replace","path":"/title","value":"reviewed"}`)
+	r.Equal("published", userTitle())
+
+	resp = s.request(router, "POST", draftPath+"/approve", bob, `{"comment": "ok"}`)
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	draft = entity.BannerDraft{}
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &draft))
+	r.Equal(entity.DraftApproved, draft.Status)
+	r.Equal(int32(2), *draft.PublishedVersion)
+	r.Equal("reviewed", userTitle())
+	banner, err := s.service.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(int32(2), banner.Version)
+
+	// черновик от устаревшей версии не публикуется
+	resp = s.request(router, "POST", fmt.Sprintf("/banner/%d/drafts", id), alice, `{"content": {"title": "stale"}}`)
+	r.Equal(http.StatusCreated, resp.Code, resp.Body.String())
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &draft))
+	draftPath = fmt.Sprintf("/drafts/%d", draft.ID)
+	r.Equal(http.StatusOK, s.request(router, "POST", draftPath+"/submit", alice, "").Code)
+	isActive := false
+	r.NoError(s.service.Update(ctx, &entity.BannerUpdate{ID: &id, IsActive: &isActive}))
+	r.Equal(http.StatusConflict, s.request(router, "POST", draftPath+"/approve", bob, "").Code)
+	r.Equal(http.StatusForbidden, s.request(router, "DELETE", draftPath, bob, "").Code)
+	r.Equal(http.StatusNoContent, s.request(router, "DELETE", draftPath, alice, "").Code)
+	r.Equal(http.StatusNotFound, s.request(router, "GET", "/drafts/999999", bob, "").Code)
+}
+
+func (s *APITestSuite) TestDrafts_InvalidRequest() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	secret := "draft_secret"
+	v1.RegisterRoutes(router, v1.NewBannerController(&MockBannerService{}, s.logger, s.messages).WithTokenSecret(secret), v1.NewDraftController(nil, s.logger, s.messages))
+	r := s.Require()
+	admin, err := token.Issue([]byte(secret), "alice", token.RoleAdmin, time.Hour)
+	r.NoError(err)
+	anonymous, err := token.Issue([]byte(secret), "", token.RoleAdmin, time.Hour)
+	r.NoError(err)
+
+	for request, field := range map[[3]string]string{
+		{"GET", "/drafts?status=merged", ""}:                   "status",
+		{"GET", "/drafts?banner_id=0", ""}:                     "banner_id",
+		{"GET", "/drafts?limit=-1", ""}:                        "limit",
+		{"GET", "/drafts/1/diff?unified=sure", ""}:             "unified",
+		{"POST", "/banner/1/drafts", `{"tag_ids": "one"}`}:     "tag_ids",
+		{"POST", "/drafts/1/reject", ""}:                       "comment",
+		{"POST", "/drafts/1/reject", `{"comment": ""}`}:        "comment",
+		{"POST", "/drafts/1/approve", `{"comment": ["long"]}`}: "comment",
+	} {
+		resp := s.request(router, request[0], request[1], admin, request[2])
+		r.Equal(http.StatusBadRequest, resp.Code, request)
+		var result struct {
+			Fields map[string]string `json:"fields"`
+		}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &result))
+		r.Contains(result.Fields, field, request)
+	}
+	r.Equal(http.StatusBadRequest, s.request(router, "GET", "/drafts/abc", admin, "").Code)
+	r.Equal(http.StatusBadRequest, s.request(router, "POST", "/banner/0/drafts", admin, "{}").Code)
+
+	// без субъекта нельзя отличить автора от ревьюера
+	for _, actor := range []string{"admin_token", anonymous} {
+		resp := s.request(router, "POST", "/banner/1/drafts", actor, `{"is_active": false}`)
+		r.Equal(http.StatusForbidden, resp.Code)
+		var result struct {
+			Error string `json:"error"`
+		}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &result))
+		r.Equal("Для работы с черновиками нужен токен с известным субъектом", result.Error)
+		r.Equal(http.StatusForbidden, s.request(router, "POST", "/drafts/1/approve", actor, "").Code)
+	}
+	r.Equal(http.StatusForbidden, s.request(router, "GET", "/drafts", "user_token", "").Code)
+	r.Equal(http.StatusForbidden, s.request(router, "POST", "/drafts/1/approve", "user_token", "").Code)
+}
+
+func (s *APITestSuite) TestDrafts_RequireReview() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	r := s.Require()
+	service := &MockBannerService{
+		UpdateFunc: func(ctx context.Context, banner *entity.BannerUpdate) error {
+			r.Fail("update must go through review")
+			return nil
+		},
+		RollbackFunc: func(ctx context.Context, id, version int32, expectedVersion *int32) error {
+			r.Fail("rollback must go through review")
+			return nil
+		},
+		RestoreFunc: func(ctx context.Context, id int32, expectedVersion *int32) error {
+			r.Fail("restore must go through review")
+			return nil
+		},
+		ImportFunc: func(ctx context.Context, reader io.Reader, options *entity.ImportOptions) (*entity.ImportReport, error) {
+			r.Fail("import with overwrite must go through review")
+			return nil, nil
+		},
+		BulkFunc: func(ctx context.Context, request *entity.BulkRequest) ([]*entity.BulkResult, error) {
+			r.Equal(entity.BulkCreate, request.Operations[0].Action)
+			id := int32(7)
+			return []*entity.BulkResult{{Index: 0, Action: entity.BulkCreate, ID: &id, Status: entity.BulkOK}}, nil
+		},
+	}
+	v1.RegisterRoutes(router, v1.NewBannerController(service, s.logger, s.messages).WithRequireReview(true), v1.NewDraftController(nil, s.logger, s.messages))
+
+	resp := s.request(router, "PATCH", "/banner/3", "admin_token", `{"is_active": false}`)
+	r.Equal(http.StatusConflict, resp.Code)
+	var result struct {
+		Error  string `json:"error"`
+		Drafts string `json:"drafts"`
+	}
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &result))
+	r.Equal("/banner/3/drafts", result.Drafts)
+	r.NotEmpty(result.Error)
+
+	r.Equal(http.StatusConflict, s.request(router, "POST", "/banner/3/rollback", "admin_token", `{"version": 1}`).Code)
+	r.Equal(http.StatusConflict, s.request(router, "POST", "/banner/3/restore", "admin_token", "").Code)
+	r.Equal(http.StatusConflict, s.request(router, "POST", "/banner/bulk", "admin_token", `{"operations": [{"action": "create", "banner": {"tag_ids": [1], "feature_id": 1, "content": {}}}, {"action": "deactivate", "id": 3}]}`).Code)
+	resp = s.importBanners(router, "?conflict=overwrite", "text/csv", "id,tag_ids,feature_id,content,is_active\n")
+	r.Equal(http.StatusConflict, resp.Code)
+
+	resp = s.request(router, "POST", "/banner/bulk", "admin_token", `{"operations": [{"action": "create", "banner": {"tag_ids": [1], "feature_id": 1, "content": {}}}]}`)
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+}
+
+func (s *APITestSuite) TestDrafts_RequireReviewBlocksDirectPublish() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	secret := "draft_secret"
+	v1.RegisterRoutes(router, v1.NewBannerController(s.service, s.logger, s.messages).WithTokenSecret(secret).WithRequireReview(true), s.drafts)
+	r := s.Require()
+	ctx := context.Background()
+
+	alice, err := token.Issue([]byte(secret), "alice", token.RoleAdmin, time.Hour)
+	r.NoError(err)
+	bob, err := token.Issue([]byte(secret), "bob", token.RoleAdmin, time.Hour)
+	r.NoError(err)
+	id, err := s.service.Save(ctx, &entity.Banner{TagIDs: []int32{1}, FeatureID: 902, Content: map[string]interface{}{"title": "published"}, IsActive: true})
+	r.NoError(err)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banner_drafts WHERE banner_id = $1", id)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", id)
+	userTitle := func() string {
+		resp := s.request(router, "GET", "/user_banner?tag_id=1&feature_id=902&use_last_revision=true", "user_token", "")
+		r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+		var content map[string]interface{}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &content))
+		return content["title"].(string)
+	}
+
+	resp := s.request(router, "PATCH", fmt.Sprintf("/banner/%d", id), alice, `{"content": {"title": "unreviewed"}}`)
+	r.Equal(http.StatusConflict, resp.Code, resp.Body.String())
+	r.Equal("published", userTitle())
+
+	resp = s.request(router, "POST", fmt.Sprintf("/banner/%d/drafts", id), alice, `{"content": {"title": "reviewed"}}`)
+	r.Equal(http.StatusCreated, resp.Code, resp.Body.String())
+	var draft entity.BannerDraft
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &draft))
+	draftPath := fmt.Sprintf("/drafts/%d", draft.ID)
+	r.Equal(http.StatusOK, s.request(router, "POST", draftPath+"/submit", alice, "").Code)
+	resp = s.request(router, "POST", draftPath+"/approve", bob, "")
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	r.Equal("reviewed", userTitle())
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"banner/internal/service"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const testFeatureSchema = `{
+	"type": "object",
+	"required": ["title", "text", "url"],
+	"properties": {
+		"title": {"type": "string"},
+		"text": {"type": "string"},
+		"url": {"type": "string", "pattern": "^https://"}
+	}
+}`
+
+func (s *APITestSuite) putTestSchema(router *gin.Engine) {
+	req, _ := http.NewRequest("PUT", "/feature/123/schema", strings.NewReader(testFeatureSchema))
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	s.Require().Equal(http.StatusNoContent, resp.Result().StatusCode)
+}
+func (s *APITestSuite) deleteTestSchema() {
+	_, err := s.db.Pool.Exec(context.Background(), "DELETE FROM feature_schemas WHERE feature_id = $1", 123)
+	s.NoError(err)
+}
+func (s *APITestSuite) TestFeatureSchema_RejectsInvalidContent() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.schemas)
+	r := s.Require()
+	s.putTestSchema(router)
+	defer s.deleteTestSchema()
+	requestBody := `{
+		"tag_ids": [4, 5, 6],
+		"feature_id": 123,
+		"content": {
+			"title": "some_title",
+			"url": "http://example.com"
+		},
+		"is_active": true
+	}`
+	req, _ := http.NewRequest("POST", "/banner", strings.NewReader(requestBody))
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Result().StatusCode)
+	var body struct {
+		Violations []entity.ContentViolation `json:"violations"`
+	}
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &body))
+	r.Len(body.Violations, 2)
+	r.Equal("/", body.Violations[0].Pointer)
+	r.Equal("/url", body.Violations[1].Pointer)
+}
+func (s *APITestSuite) TestFeatureSchema_RejectsInvalidUpdate() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.schemas)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	s.putTestSchema(router)
+	defer s.deleteTestSchema()
+	requestBody := `{
+		"content": {"title": "some_title", "text": "some_text", "url": "ftp://example.com"}
+	}`
+	req, _ := http.NewRequest("PATCH", "/banner/1", strings.NewReader(requestBody))
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Result().StatusCode)
+}
+func (s *APITestSuite) TestFeatureSchema_Report() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.schemas)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	s.putTestSchema(router)
+	defer s.deleteTestSchema()
+	req, _ := http.NewRequest("GET", "/feature/123/schema/report", nil)
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Result().StatusCode)
+	var report []entity.SchemaReportItem
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &report))
+	r.Len(report, 1)
+	r.False(report[0].Valid)
+	r.Equal("/url", report[0].Violations[0].Pointer)
+}
+func (s *APITestSuite) TestFeatureSchema_InvalidSchema() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.schemas)
+	r := s.Require()
+	req, _ := http.NewRequest("PUT", "/feature/123/schema", strings.NewReader(`{"type": "no_such_type"}`))
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Result().StatusCode)
+}
+func (s *APITestSuite) TestFeatureSchema_ExternalReference() {
+	r := s.Require()
+	schemas := service.NewSchemaService(nil, nil)
+	path := filepath.Join(s.T().TempDir(), "schema.json")
+	r.NoError(os.WriteFile(path, []byte(`{"type": "string"}`), 0o600))
+
+	// схема проверяется до сохранения, поэтому репозиторий не нужен
+	for _, ref := range []string{"file://" + path, "http://127.0.0.1:1/schema.json", "mem://feature/124/schema.json"} {
+		err := schemas.Save(context.Background(), &entity.FeatureSchema{
+			FeatureID: 123,
+			Schema:    map[string]interface{}{"properties": map[string]interface{}{"title": map[string]interface{}{"$ref": ref}}},
+		})
+		r.ErrorIs(err, service.ErrInvalidSchema, ref)
+	}
+}
+func (s *APITestSuite) TestFeatureSchema_ReplacedSchemaApplies() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.schemas)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	s.putTestSchema(router)
+	defer s.deleteTestSchema()
+	patch := func() int {
+		req, _ := http.NewRequest("PATCH", "/banner/1", strings.NewReader(`{"content": {"title": "some_title"}}`))
+		req.Header.Set("Content-type", "application/json")
+		req.Header.Set("token", "admin_token")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp.Result().StatusCode
+	}
+	r.Equal(http.StatusBadRequest, patch())
+
+	// скомпилированная схема кэшируется, замена схемы должна применяться сразу
+	req, _ := http.NewRequest("PUT", "/feature/123/schema", strings.NewReader(`{"type": "object", "required": ["title"]}`))
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNoContent, resp.Result().StatusCode)
+	r.Equal(http.StatusNoContent, patch())
+}
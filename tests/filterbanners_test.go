@@ -0,0 +1,83 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+)
+
+func (s *APITestSuite) createFilterBanners() {
+	banners := []struct {
+		tagIDs    []int32
+		featureID int32
+		url       string
+		isActive  bool
+	}{
+		{[]int32{70, 71}, 701, "https://example.com/a", true},
+		{[]int32{71}, 702, "https://other.org/b", false},
+		{[]int32{70, 72}, 703, "https://example.com/c", false},
+	}
+	for _, banner := range banners {
+		_, err := s.db.Pool.Exec(context.Background(),
+			"INSERT INTO banners (tag_ids, feature_id, content, is_active) VALUES ($1, $2, $3, $4)",
+			banner.tagIDs, banner.featureID, map[string]interface{}{"title": "spring sale", "url": banner.url}, banner.isActive)
+		s.NoError(err)
+	}
+}
+
+func (s *APITestSuite) filterFeatures(router *gin.Engine, query url.Values) []int32 {
+	req, _ := http.NewRequest("GET", "/banner?"+query.Encode(), nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	s.Require().Equal(http.StatusOK, resp.Code, resp.Body.String())
+	var banners []entity.FilteredBanner
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &banners))
+	features := []int32{}
+	for _, banner := range banners {
+		features = append(features, banner.FeatureID)
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i] < features[j] })
+	return features
+}
+
+func (s *APITestSuite) TestGetBanners_RichFilters() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createFilterBanners()
+	defer func() {
+		_, err := s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE feature_id BETWEEN 701 AND 703")
+		s.NoError(err)
+	}()
+
+	r.Equal([]int32{701, 702, 703}, s.filterFeatures(router, url.Values{"tag_ids": {"70,71"}}))
+	r.Equal([]int32{701}, s.filterFeatures(router, url.Values{"tag_ids": {"70", "71"}, "tag_match": {"all"}}))
+	r.Equal([]int32{702, 703}, s.filterFeatures(router, url.Values{"feature_ids": {"701,702,703"}, "is_active": {"false"}}))
+	r.Equal([]int32{701, 703}, s.filterFeatures(router, url.Values{
+		"feature_ids":  {"701,702,703"},
+		"content_path": {`$.url ? (@ like_regex "example\\.com")`},
+	}))
+	r.Equal([]int32{701, 702, 703}, s.filterFeatures(router, url.Values{"tag_ids": {"70,71,72"}, "q": {"spring sale"}}))
+	r.Equal([]int32{}, s.filterFeatures(router, url.Values{"tag_ids": {"70,71,72"}, "created_to": {"2000-01-01"}}))
+}
+
+func (s *APITestSuite) TestGetBanners_InvalidContentPath() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+
+	req, _ := http.NewRequest("GET", "/banner?content_path="+url.QueryEscape("$.url ? ("), nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Code)
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
 	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
@@ -69,11 +70,11 @@ func (s *APITestSuite) TestBannerGet_InternalServerError() {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	mockService := &MockBannerService{
-		GetForUserFunc: func(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (map[string]interface{}, error) {
+		GetForUserFunc: func(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (*entity.UserBanner, error) {
 			return nil, errors.New("internal server error")
 		},
 	}
-	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger))
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
 	r := s.Require()
 	s.createTestBanner()
 	defer s.deleteTestBanner()
@@ -87,3 +88,70 @@ func (s *APITestSuite) TestBannerGet_InternalServerError() {
 	s.NoError(err)
 	r.Equal("{\"error\":\"Внутренняя ошибка сервера\"}", string(responseBody))
 }
+func (s *APITestSuite) TestBannerGet_BadRequestLocalized() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	req, _ := http.NewRequest("GET", "/user_banner?tag_id=stashge", nil)
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9,ru;q=0.8")
+	req.Header.Set("token", "user_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Result().StatusCode)
+	r.Equal("en", resp.Header().Get("Content-Language"))
+	responseBody, err := io.ReadAll(resp.Body)
+	s.NoError(err)
+	r.Equal("{\"message\":\"Invalid tagId\"}", string(responseBody))
+}
+func (s *APITestSuite) TestBannerGet_BadRequestUnsupportedLanguage() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	req, _ := http.NewRequest("GET", "/user_banner?tag_id=stashge", nil)
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("Accept-Language", "de-DE")
+	req.Header.Set("token", "user_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Result().StatusCode)
+	responseBody, err := io.ReadAll(resp.Body)
+	s.NoError(err)
+	r.Equal("{\"message\":\"Некорректные данные tagId\"}", string(responseBody))
+}
+
+func (s *APITestSuite) TestBannerGet_NotModified() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	req, _ := http.NewRequest("GET", "/user_banner?tag_id=4&feature_id=123", nil)
+	req.Header.Set("token", "user_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Result().StatusCode)
+	etag := resp.Header().Get("ETag")
+	r.NotEmpty(etag)
+	r.Contains(resp.Header().Get("Cache-Control"), "max-age=")
+
+	req, _ = http.NewRequest("GET", "/user_banner?tag_id=4&feature_id=123", nil)
+	req.Header.Set("token", "user_token")
+	req.Header.Set("If-None-Match", etag)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNotModified, resp.Result().StatusCode)
+	r.Empty(resp.Body.Bytes())
+
+	req, _ = http.NewRequest("GET", "/user_banner?tag_id=4&feature_id=123&use_last_revision=true", nil)
+	req.Header.Set("token", "user_token")
+	req.Header.Set("If-None-Match", etag)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNotModified, resp.Result().StatusCode)
+	r.Equal(etag, resp.Header().Get("ETag"))
+	r.Equal("private, no-cache", resp.Header().Get("Cache-Control"))
+}
@@ -64,11 +64,11 @@ func (s *APITestSuite) TestGetBanners_InternalServerError() {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	mockService := &MockBannerService{
-		GetBannersFunc: func(ctx context.Context, featureID, tagID, limit, offset *int32) ([]*entity.FilteredBanner, error) {
+		GetBannersFunc: func(ctx context.Context, query *entity.BannersQuery) (*entity.BannersPage, error) {
 			return nil, errors.New("internal server error")
 		},
 	}
-	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger))
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
 	r := s.Require()
 
 	req, _ := http.NewRequest("GET", "/banner", nil)
@@ -79,3 +79,76 @@ func (s *APITestSuite) TestGetBanners_InternalServerError() {
 
 	r.Equal(http.StatusInternalServerError, resp.Result().StatusCode)
 }
+
+func (s *APITestSuite) TestGetBanners_BadRequest() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+
+	req, _ := http.NewRequest("GET", "/banner?feature_id=abc&tag_id=-1", nil)
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	r.Equal(http.StatusBadRequest, resp.Result().StatusCode)
+	var body struct {
+		Fields map[string]string `json:"fields"`
+	}
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &body))
+	r.Equal("Значение должно быть целым числом", body.Fields["feature_id"])
+	r.Equal("Значение должно быть больше 0", body.Fields["tag_id"])
+}
+
+func (s *APITestSuite) TestGetBanners_CursorPagination() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	for _, featureID := range []int32{601, 602, 603} {
+		_, err := s.db.Pool.Exec(context.Background(),
+			"INSERT INTO banners (tag_ids, feature_id, content, is_active) VALUES ($1, $2, $3, true)",
+			[]int32{60}, featureID, map[string]interface{}{"title": "page"})
+		s.NoError(err)
+	}
+	defer func() {
+		_, err := s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE tag_ids @> ARRAY[60]")
+		s.NoError(err)
+	}()
+
+	var features []int32
+	url := "/banner?tag_id=60&sort_by=feature_id&order=desc&limit=2&cursor="
+	for page := 0; page < 3 && url != ""; page++ {
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("token", "admin_token")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		r.Equal(http.StatusOK, resp.Code)
+
+		var result entity.BannersPage
+		s.NoError(json.Unmarshal(resp.Body.Bytes(), &result))
+		r.Equal(int64(3), result.Total)
+		for _, banner := range result.Banners {
+			features = append(features, banner.FeatureID)
+		}
+		url = ""
+		if result.NextCursor != "" {
+			url = "/banner?tag_id=60&limit=2&cursor=" + result.NextCursor
+		}
+	}
+	r.Equal([]int32{603, 602, 601}, features)
+}
+
+func (s *APITestSuite) TestGetBanners_InvalidCursor() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+
+	req, _ := http.NewRequest("GET", "/banner?cursor=garbage", nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Code)
+}
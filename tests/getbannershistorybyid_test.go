@@ -96,7 +96,7 @@ func (s *APITestSuite) TestGetBannersHistoryByID_InternalServerError() {
 			return nil, errors.New("internal server error")
 		},
 	}
-	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger))
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
 	r := s.Require()
 	req, _ := http.NewRequest("GET", "/banner/history/1", nil)
 	req.Header.Set("Content-type", "application/json")
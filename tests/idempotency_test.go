@@ -0,0 +1,130 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/internal/service"
+	"banner/pkg/token"
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func (s *APITestSuite) postBannerWithKey(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := s.newRequest("POST", "/banner", "admin_token", body)
+	req.Header.Set("Idempotency-Key", key)
+	return s.serve(router, req)
+}
+
+func (s *APITestSuite) TestCreateBanner_IdempotentRetry() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	defer func() {
+		_, err := s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE feature_id = $1", 321)
+		s.NoError(err)
+	}()
+
+	first := s.postBannerWithKey(router, "create-321", `{"tag_ids": [1, 2], "feature_id": 321, "content": {"title": "t"}, "is_active": true}`)
+	r.Equal(http.StatusCreated, first.Code)
+
+	// тот же запрос с другим форматированием - ответ повторяется, второй баннер не создается
+	retry := s.postBannerWithKey(router, "create-321", `{"feature_id":321,"tag_ids":[1,2],"content":{"title":"t"},"is_active":true}`)
+	r.Equal(http.StatusCreated, retry.Code)
+	r.Equal(first.Body.String(), retry.Body.String())
+	r.Equal("true", retry.Header().Get("Idempotent-Replayed"))
+
+	var count int
+	err := s.db.Pool.QueryRow(context.Background(), "SELECT count(*) FROM banners WHERE feature_id = $1", 321).Scan(&count)
+	s.NoError(err)
+	r.Equal(1, count)
+}
+
+func (s *APITestSuite) TestCreateBanner_IdempotencyKeyReused() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	defer func() {
+		_, err := s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE feature_id = $1", 322)
+		s.NoError(err)
+	}()
+
+	first := s.postBannerWithKey(router, "create-322", `{"tag_ids": [1], "feature_id": 322, "content": {"title": "t"}, "is_active": true}`)
+	r.Equal(http.StatusCreated, first.Code)
+
+	other := s.postBannerWithKey(router, "create-322", `{"tag_ids": [2], "feature_id": 322, "content": {"title": "t"}, "is_active": true}`)
+	r.Equal(http.StatusUnprocessableEntity, other.Code)
+}
+
+func (s *APITestSuite) TestCreateBanner_IdempotencyKeyScopedByActor() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	secret := "idempotency_secret"
+	idempotency := service.NewIdempotencyService(repository.NewIdempotencyRepository(s.db), time.Hour)
+	v1.RegisterRoutes(router, v1.NewBannerController(s.service, s.logger, s.messages).WithIdempotency(idempotency).WithTokenSecret(secret))
+	r := s.Require()
+	defer func() {
+		_, err := s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE feature_id = $1", 323)
+		s.NoError(err)
+	}()
+
+	// один и тот же ключ от разных клиентов - разные запросы
+	for i, subject := range []string{"service-a", "service-b"} {
+		issued, err := token.Issue([]byte(secret), subject, token.RoleAdmin, time.Hour)
+		r.NoError(err)
+		req, _ := http.NewRequest("POST", "/banner", strings.NewReader(fmt.Sprintf(`{"tag_ids": [%d], "feature_id": 323, "content": {"title": "t"}, "is_active": true}`, i+1)))
+		req.Header.Set("Content-type", "application/json")
+		req.Header.Set("token", issued)
+		req.Header.Set("Idempotency-Key", "create-323")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		r.Equal(http.StatusCreated, resp.Code, subject)
+		r.Empty(resp.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func (s *APITestSuite) TestCreateBanner_IdempotencyKeyReleasedOnPanic() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var completed, aborted []string
+	store := &MockIdempotencyStore{
+		BeginFunc: func(ctx context.Context, key, fingerprint string) (*entity.IdempotentResponse, error) {
+			return nil, nil
+		},
+		CompleteFunc: func(ctx context.Context, key string, response *entity.IdempotentResponse) error {
+			completed = append(completed, key)
+			return nil
+		},
+		AbortFunc: func(ctx context.Context, key string) error {
+			aborted = append(aborted, key)
+			return nil
+		},
+	}
+	mockService := &MockBannerService{
+		SaveFunc: func(ctx context.Context, banner *entity.Banner) (int32, error) {
+			if banner.FeatureID == 1 {
+				panic("unexpected state")
+			}
+			return 1, nil
+		},
+	}
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages).WithIdempotency(store))
+	r := s.Require()
+
+	resp := s.postBannerWithKey(router, "panics", `{"tag_ids": [1], "feature_id": 1, "content": {"title": "t"}, "is_active": true}`)
+	r.Equal(http.StatusInternalServerError, resp.Code)
+	r.Equal([]string{"panics"}, aborted)
+	r.Empty(completed)
+
+	resp = s.postBannerWithKey(router, "creates", `{"tag_ids": [1], "feature_id": 2, "content": {"title": "t"}, "is_active": true}`)
+	r.Equal(http.StatusCreated, resp.Code)
+	r.Equal([]string{"creates"}, completed)
+	r.Equal([]string{"panics"}, aborted)
+}
@@ -0,0 +1,225 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"banner/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+const (
+	jobFlaky  entity.JobKind = "test_flaky"
+	jobBroken entity.JobKind = "test_broken"
+)
+
+// registerTestJobs добавляет в очередь задачи, которые падают предсказуемым образом
+func (s *APITestSuite) registerTestJobs() {
+	s.jobQueue.Register(jobFlaky, func(ctx context.Context, job *entity.Job, progress func(int64) error) error {
+		if job.Attempts < 2 {
+			return errors.New("temporary failure")
+		}
+		return progress(1)
+	})
+	s.jobQueue.Register(jobBroken, func(ctx context.Context, job *entity.Job, progress func(int64) error) error {
+		return fmt.Errorf("%w: broken params", service.ErrJobPermanent)
+	})
+}
+
+// waitJob ждет, пока задача не завершится, и возвращает ее итоговое состояние
+func (s *APITestSuite) waitJob(id int64) *entity.Job {
+	var job *entity.Job
+	s.Require().Eventually(func() bool {
+		var err error
+		job, err = s.jobQueue.Get(context.Background(), id)
+		s.NoError(err)
+		return job.Status == entity.JobDone || job.Status == entity.JobFailed
+	}, 5*time.Second, 20*time.Millisecond)
+	return job
+}
+
+func (s *APITestSuite) TestJobQueue_RetryWithBackoff() {
+	r := s.Require()
+	job, err := s.jobQueue.Enqueue(context.Background(), jobFlaky, nil, 1)
+	s.NoError(err)
+	r.Equal(entity.JobQueued, job.Status)
+
+	job = s.waitJob(job.ID)
+	r.Equal(entity.JobDone, job.Status)
+	r.Equal(int32(2), job.Attempts)
+	r.Equal(int64(1), job.Processed)
+	r.Empty(job.Error)
+}
+
+func (s *APITestSuite) TestJobQueue_PermanentFailure() {
+	r := s.Require()
+	job, err := s.jobQueue.Enqueue(context.Background(), jobBroken, nil, 0)
+	s.NoError(err)
+
+	job = s.waitJob(job.ID)
+	r.Equal(entity.JobFailed, job.Status)
+	r.Equal(int32(1), job.Attempts)
+	r.Contains(job.Error, "broken params")
+	r.NotNil(job.FinishedAt)
+}
+
+func (s *APITestSuite) TestJobQueue_UnknownKind() {
+	_, err := s.jobQueue.Enqueue(context.Background(), "no_such_job", nil, 0)
+	s.ErrorIs(err, service.ErrUnknownJobKind)
+}
+
+func (s *APITestSuite) TestGetJobs_FilterByStatus() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.jobs)
+	r := s.Require()
+	job, err := s.jobQueue.Enqueue(context.Background(), jobBroken, nil, 0)
+	s.NoError(err)
+	s.waitJob(job.ID)
+
+	req, _ := http.NewRequest("GET", "/jobs?status=unknown", nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/jobs?status=failed&kind=test_broken&limit=1", nil)
+	req.Header.Set("token", "admin_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Code)
+	var jobs []entity.Job
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &jobs))
+	r.Len(jobs, 1)
+	r.Equal(job.ID, jobs[0].ID)
+}
+
+func (s *APITestSuite) TestDeleteBanners_ByFeature() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.jobs)
+	r := s.Require()
+	for _, tagID := range []int32{71, 72, 73} {
+		_, err := s.db.Pool.Exec(context.Background(),
+			"INSERT INTO banners (tag_ids, feature_id, content, is_active) VALUES ($1, 701, $2, true)",
+			[]int32{tagID}, map[string]interface{}{"title": "mass"})
+		s.NoError(err)
+	}
+	defer func() {
+		_, err := s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE feature_id = 701")
+		s.NoError(err)
+	}()
+
+	// баннер попадает в кэш до удаления
+	req, _ := http.NewRequest("GET", "/user_banner?tag_id=71&feature_id=701", nil)
+	req.Header.Set("token", "user_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Code)
+
+	req, _ = http.NewRequest("DELETE", "/banner?feature_id=701", nil)
+	req.Header.Set("token", "admin_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusAccepted, resp.Code)
+	var job entity.Job
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &job))
+	r.Equal(int64(3), job.Total)
+	r.Equal(fmt.Sprintf("/jobs/%d", job.ID), resp.Header().Get("Location"))
+
+	s.waitJob(job.ID)
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/jobs/%d", job.ID), nil)
+	req.Header.Set("token", "admin_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Code)
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &job))
+	r.Equal(entity.JobDone, job.Status)
+	r.Equal(int64(3), job.Processed)
+	r.NotNil(job.FinishedAt)
+
+	r.Equal(0, s.countBanners(701))
+	req, _ = http.NewRequest("GET", "/user_banner?tag_id=71&feature_id=701", nil)
+	req.Header.Set("token", "user_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNotFound, resp.Code)
+}
+
+func (s *APITestSuite) TestDeleteBanners_WaitsForLockedRows() {
+	r := s.Require()
+	ctx := context.Background()
+	for _, tagID := range []int32{74, 75} {
+		_, err := s.db.Pool.Exec(ctx,
+			"INSERT INTO banners (tag_ids, feature_id, content, is_active) VALUES ($1, 702, $2, true)",
+			[]int32{tagID}, map[string]interface{}{"title": "locked"})
+		r.NoError(err)
+	}
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE feature_id = 702")
+
+	// чужая транзакция держит один из баннеров, пачка удаления его пропускает
+	tx, err := s.db.Pool.Begin(ctx)
+	r.NoError(err)
+	defer tx.Rollback(context.Background())
+	_, err = tx.Exec(ctx, "SELECT id FROM banners WHERE feature_id = 702 AND tag_ids = ARRAY[74] FOR UPDATE")
+	r.NoError(err)
+
+	featureID := int32(702)
+	job, err := s.jobQueue.EnqueueDeleteBanners(ctx, &entity.DeleteBannersParams{FeatureID: &featureID})
+	r.NoError(err)
+	r.Eventually(func() bool { return s.countBanners(702) == 1 }, 5*time.Second, 20*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	current, err := s.jobQueue.Get(ctx, job.ID)
+	r.NoError(err)
+	r.Equal(entity.JobRunning, current.Status)
+
+	r.NoError(tx.Rollback(ctx))
+	job = s.waitJob(job.ID)
+	r.Equal(entity.JobDone, job.Status)
+	r.Equal(int64(2), job.Processed)
+	r.Equal(0, s.countBanners(702))
+}
+
+func (s *APITestSuite) TestDeleteBanners_BadRequest() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.jobs)
+	r := s.Require()
+
+	req, _ := http.NewRequest("DELETE", "/banner", nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Code)
+
+	req, _ = http.NewRequest("DELETE", "/banner?tag_id=0", nil)
+	req.Header.Set("token", "admin_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusBadRequest, resp.Code)
+
+	req, _ = http.NewRequest("DELETE", "/banner?tag_id=1", nil)
+	req.Header.Set("token", "user_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusForbidden, resp.Code)
+}
+
+func (s *APITestSuite) TestGetJob_NotFound() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.jobs)
+	r := s.Require()
+
+	req, _ := http.NewRequest("GET", "/jobs/999999", nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNotFound, resp.Code)
+}
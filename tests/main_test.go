@@ -6,10 +6,15 @@ import (
 	"banner/internal/service"
 	"banner/pkg/cache"
 	"banner/pkg/db/postgres"
+	"banner/pkg/i18n"
 	"banner/pkg/logger"
 	"context"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -24,11 +29,37 @@ func init() {
 type APITestSuite struct {
 	suite.Suite
 
-	db      *postgres.DB
-	handler *v1.BannerController
-	service *service.BannerService
-	repo    *repository.BannerRepository
-	logger  logger.Logger
+	db       *postgres.DB
+	handler  *v1.BannerController
+	schemas  *v1.SchemaController
+	jobs     *v1.JobController
+	jobQueue *service.JobService
+	webhooks *v1.WebhookController
+	hooks    *service.WebhookService
+	audit    *v1.AuditController
+	drafts   *v1.DraftController
+	service  *service.BannerService
+	repo     *repository.BannerRepository
+	logger   logger.Logger
+	messages *i18n.Catalog
+}
+
+// migrations - миграции, которые тесты применяют поверх таблиц из createTable, в порядке применения
+var migrations = []string{
+	"20240416120000_create_json_patch_functions",
+	"20240417120000_add_banner_version",
+	"20240418120000_create_idempotency_keys",
+	"20240419120000_add_banner_sort_indexes",
+	"20240420120000_add_banner_search_indexes",
+	"20240421120000_add_banner_soft_delete",
+	"20240422120000_create_jobs",
+	"20240423120000_add_job_queue",
+	"20240424120000_create_banner_events",
+	"20240425120000_create_webhooks",
+	"20240426120000_add_banner_event_snapshots",
+	"20240427120000_create_audit_log",
+	"20240428120000_create_banner_drafts",
+	"20240429120000_scope_idempotency_keys",
 }
 
 func TestAPISuite(t *testing.T) {
@@ -49,11 +80,23 @@ func (s *APITestSuite) SetupSuite() {
 	if err := s.createTable(); err != nil {
 		s.FailNow("Failed to create table", err)
 	}
+	s.jobQueue.Start()
+	s.hooks.Start()
 }
 func (s *APITestSuite) TearDownSuite() {
+	s.jobQueue.Stop()
+	s.hooks.Stop()
+	// миграции откатываются в обратном порядке: функции и триггеры зависят от таблиц, а таблицы - друг от друга
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if err := s.execMigration(migrations[i] + ".down.sql"); err != nil {
+			s.FailNow("Failed to roll back migration "+migrations[i], err)
+		}
+	}
 	_, err := s.db.Pool.Exec(context.Background(), `
 	      DROP TABLE banners;
-DROP TABLE banners_history;`)
+DROP TABLE banners_history;
+DROP TABLE feature_schemas;
+DROP FUNCTION save_banner_history();`)
 	if err != nil {
 		s.FailNow("Failed to drop table", err)
 	}
@@ -62,11 +105,43 @@ DROP TABLE banners_history;`)
 func (s *APITestSuite) initialize() {
 	repo := repository.NewBannerRepository(s.db)
 	memCache := cache.NewMemoryCache(1000, 20)
-	serv := service.NewBannerService(repo, memCache, 5*time.Minute)
-	contr := v1.NewBannerController(serv, s.logger)
+	schemaService := service.NewSchemaService(repository.NewSchemaRepository(s.db), repo)
+	serv := service.NewBannerService(repo, schemaService, memCache, 5*time.Minute)
+	messages, err := v1.NewCatalog("ru")
+	if err != nil {
+		s.FailNow("Failed to create message catalog", err)
+	}
+	s.messages = messages
+	idempotency := service.NewIdempotencyService(repository.NewIdempotencyRepository(s.db), time.Hour)
+	contr := v1.NewBannerController(serv, s.logger, messages).WithIdempotency(idempotency)
 	s.repo = repo
 	s.service = serv
 	s.handler = contr
+	s.schemas = v1.NewSchemaController(schemaService, s.logger, messages)
+	s.jobQueue = service.NewJobService(repository.NewJobRepository(s.db), serv, s.logger, service.JobOptions{
+		Workers:      2,
+		PollInterval: 20 * time.Millisecond,
+		MaxAttempts:  3,
+		RetryBackoff: 50 * time.Millisecond,
+		MaxBackoff:   time.Second,
+		LockTimeout:  time.Minute,
+		DrainTimeout: 5 * time.Second,
+	})
+	s.registerTestJobs()
+	s.jobs = v1.NewJobController(s.jobQueue, s.logger, messages)
+	s.hooks = service.NewWebhookService(repository.NewWebhookRepository(s.db), s.logger, service.WebhookOptions{
+		Workers:      2,
+		PollInterval: 20 * time.Millisecond,
+		MaxAttempts:  2,
+		RetryBackoff: 50 * time.Millisecond,
+		MaxBackoff:   time.Second,
+		Timeout:      time.Second,
+		LockTimeout:  time.Minute,
+		DrainTimeout: 5 * time.Second,
+	})
+	s.webhooks = v1.NewWebhookController(s.hooks, s.logger, messages)
+	s.audit = v1.NewAuditController(service.NewAuditService(repository.NewAuditRepository(s.db)), s.logger, messages)
+	s.drafts = v1.NewDraftController(serv, s.logger, messages)
 }
 func TestMain(m *testing.M) {
 	rc := m.Run()
@@ -93,7 +168,9 @@ func (s *APITestSuite) deleteTestBanner() {
 	s.NoError(err)
 	_, err = s.db.Pool.Exec(context.Background(), sqlQuery, args...)
 	s.NoError(err)
-
+	// история остается после удаления строки, следующий тест снова создает баннер 1 с версии 1
+	_, err = s.db.Pool.Exec(context.Background(), "DELETE FROM banners_history WHERE id = $1", 1)
+	s.NoError(err)
 }
 func (s *APITestSuite) createTable() error {
 	_, err := s.db.Pool.Exec(context.Background(), `
@@ -141,9 +218,54 @@ $$ LANGUAGE plpgsql;
 CREATE TRIGGER banners_history_trigger
     AFTER UPDATE ON banners
     FOR EACH ROW EXECUTE FUNCTION save_banner_history();
+
+CREATE TABLE IF NOT EXISTS feature_schemas (
+                         feature_id integer PRIMARY KEY,
+                         schema jsonb NOT NULL,
+                         created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                         updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
     `)
 	if err != nil {
 		return err
 	}
+	for _, name := range migrations {
+		if err := s.execMigration(name + ".up.sql"); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// execMigration выполняет файл миграции целиком, для объектов бд, которые неудобно дублировать в тестах
+func (s *APITestSuite) execMigration(name string) error {
+	sqlQuery, err := os.ReadFile("../migrations/" + name)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Pool.Exec(context.Background(), string(sqlQuery))
+	return err
+}
+
+// request выполняет запрос к router с токеном, непустое тело отправляется как JSON
+func (s *APITestSuite) request(router *gin.Engine, method, path, token, body string) *httptest.ResponseRecorder {
+	return s.serve(router, s.newRequest(method, path, token, body))
+}
+
+// newRequest собирает запрос так же, как request, чтобы перед serve можно было добавить заголовки
+func (s *APITestSuite) newRequest(method, path, token, body string) *http.Request {
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	s.Require().NoError(err)
+	req.Header.Set("token", token)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
+
+// serve выполняет собранный запрос
+func (s *APITestSuite) serve(router *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
@@ -3,37 +3,101 @@ package tests
 import (
 	"banner/internal/entity"
 	"context"
+	"io"
 )
 
 type MockBannerService struct {
 	SaveFunc                  func(ctx context.Context, banner *entity.Banner) (int32, error)
-	GetForUserFunc            func(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (map[string]interface{}, error)
-	GetBannersFunc            func(ctx context.Context, featureID, tagID, limit, offset *int32) ([]*entity.FilteredBanner, error)
-	DeleteFunc                func(ctx context.Context, id int32) error
+	GetForUserFunc            func(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (*entity.UserBanner, error)
+	GetBannersFunc            func(ctx context.Context, query *entity.BannersQuery) (*entity.BannersPage, error)
+	GetFunc                   func(ctx context.Context, id int32) (*entity.FilteredBanner, error)
+	DeleteFunc                func(ctx context.Context, id int32, version *int32) error
 	UpdateFunc                func(ctx context.Context, banner *entity.BannerUpdate) error
+	PatchFunc                 func(ctx context.Context, patch *entity.BannerPatch) error
 	GetBannersHistoryByIDFunc func(ctx context.Context, id int32) ([]*entity.BannerHistoryItem, error)
+	RollbackFunc              func(ctx context.Context, id, version int32, expectedVersion *int32) error
+	DiffFunc                  func(ctx context.Context, id, from int32, to *int32, unified bool) (*entity.BannerDiff, error)
+	TrashFunc                 func(ctx context.Context, limit *int32, offset int32) ([]*entity.DeletedBanner, error)
+	RestoreFunc               func(ctx context.Context, id int32, expectedVersion *int32) error
+	BulkFunc                  func(ctx context.Context, request *entity.BulkRequest) ([]*entity.BulkResult, error)
+	ExportFunc                func(ctx context.Context, w io.Writer, format entity.TransferFormat, filter *entity.BannerFilter) error
+	ImportFunc                func(ctx context.Context, r io.Reader, options *entity.ImportOptions) (*entity.ImportReport, error)
 }
 
 func (m *MockBannerService) Save(ctx context.Context, banner *entity.Banner) (int32, error) {
 	return m.SaveFunc(ctx, banner)
 }
 
-func (m *MockBannerService) GetForUser(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (map[string]interface{}, error) {
+func (m *MockBannerService) GetForUser(ctx context.Context, tagID, featureID int32, isActiveParam, lastRevision bool) (*entity.UserBanner, error) {
 	return m.GetForUserFunc(ctx, tagID, featureID, isActiveParam, lastRevision)
 }
 
-func (m *MockBannerService) GetBanners(ctx context.Context, featureID, tagID, limit, offset *int32) ([]*entity.FilteredBanner, error) {
-	return m.GetBannersFunc(ctx, featureID, tagID, limit, offset)
+func (m *MockBannerService) GetBanners(ctx context.Context, query *entity.BannersQuery) (*entity.BannersPage, error) {
+	return m.GetBannersFunc(ctx, query)
 }
 
-func (m *MockBannerService) Delete(ctx context.Context, id int32) error {
-	return m.DeleteFunc(ctx, id)
+func (m *MockBannerService) Get(ctx context.Context, id int32) (*entity.FilteredBanner, error) {
+	return m.GetFunc(ctx, id)
+}
+
+func (m *MockBannerService) Delete(ctx context.Context, id int32, version *int32) error {
+	return m.DeleteFunc(ctx, id, version)
 }
 
 func (m *MockBannerService) Update(ctx context.Context, banner *entity.BannerUpdate) error {
 	return m.UpdateFunc(ctx, banner)
 }
 
+func (m *MockBannerService) Patch(ctx context.Context, patch *entity.BannerPatch) error {
+	return m.PatchFunc(ctx, patch)
+}
+
 func (m *MockBannerService) GetBannersHistoryByID(ctx context.Context, id int32) ([]*entity.BannerHistoryItem, error) {
 	return m.GetBannersHistoryByIDFunc(ctx, id)
 }
+
+func (m *MockBannerService) Rollback(ctx context.Context, id, version int32, expectedVersion *int32) error {
+	return m.RollbackFunc(ctx, id, version, expectedVersion)
+}
+
+func (m *MockBannerService) Diff(ctx context.Context, id, from int32, to *int32, unified bool) (*entity.BannerDiff, error) {
+	return m.DiffFunc(ctx, id, from, to, unified)
+}
+
+func (m *MockBannerService) Trash(ctx context.Context, limit *int32, offset int32) ([]*entity.DeletedBanner, error) {
+	return m.TrashFunc(ctx, limit, offset)
+}
+
+func (m *MockBannerService) Restore(ctx context.Context, id int32, expectedVersion *int32) error {
+	return m.RestoreFunc(ctx, id, expectedVersion)
+}
+
+func (m *MockBannerService) Bulk(ctx context.Context, request *entity.BulkRequest) ([]*entity.BulkResult, error) {
+	return m.BulkFunc(ctx, request)
+}
+
+func (m *MockBannerService) Export(ctx context.Context, w io.Writer, format entity.TransferFormat, filter *entity.BannerFilter) error {
+	return m.ExportFunc(ctx, w, format, filter)
+}
+
+func (m *MockBannerService) Import(ctx context.Context, r io.Reader, options *entity.ImportOptions) (*entity.ImportReport, error) {
+	return m.ImportFunc(ctx, r, options)
+}
+
+type MockIdempotencyStore struct {
+	BeginFunc    func(ctx context.Context, key, fingerprint string) (*entity.IdempotentResponse, error)
+	CompleteFunc func(ctx context.Context, key string, response *entity.IdempotentResponse) error
+	AbortFunc    func(ctx context.Context, key string) error
+}
+
+func (m *MockIdempotencyStore) Begin(ctx context.Context, key, fingerprint string) (*entity.IdempotentResponse, error) {
+	return m.BeginFunc(ctx, key, fingerprint)
+}
+
+func (m *MockIdempotencyStore) Complete(ctx context.Context, key string, response *entity.IdempotentResponse) error {
+	return m.CompleteFunc(ctx, key, response)
+}
+
+func (m *MockIdempotencyStore) Abort(ctx context.Context, key string) error {
+	return m.AbortFunc(ctx, key)
+}
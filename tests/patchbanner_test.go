@@ -0,0 +1,114 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func (s *APITestSuite) TestPatchBanner_MergePatch() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	requestBody := `{
+		"content": {"title": "new_title", "url": null},
+		"is_active": false
+	}`
+	req, _ := http.NewRequest("PATCH", "/banner/1", strings.NewReader(requestBody))
+	req.Header.Set("Content-type", "application/merge-patch+json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNoContent, resp.Result().StatusCode)
+
+	banner, err := s.repo.GetByID(context.Background(), 1)
+	s.NoError(err)
+	r.Equal(map[string]interface{}{"title": "new_title", "text": "some_text3"}, banner.Content)
+	r.False(banner.IsActive)
+	r.Equal([]int32{4, 5, 6}, banner.TagIDs)
+}
+func (s *APITestSuite) TestPatchBanner_JSONPatch() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	requestBody := `[
+		{"op": "test", "path": "/content/title", "value": "some_title"},
+		{"op": "replace", "path": "/content/title", "value": "new_title"},
+		{"op": "remove", "path": "/content/url"},
+		{"op": "add", "path": "/tag_ids/-", "value": 7}
+	]`
+	req, _ := http.NewRequest("PATCH", "/banner/1", strings.NewReader(requestBody))
+	req.Header.Set("Content-type", "application/json-patch+json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNoContent, resp.Result().StatusCode)
+
+	banner, err := s.repo.GetByID(context.Background(), 1)
+	s.NoError(err)
+	r.Equal(map[string]interface{}{"title": "new_title", "text": "some_text3"}, banner.Content)
+	r.Equal([]int32{4, 5, 6, 7}, banner.TagIDs)
+}
+func (s *APITestSuite) TestPatchBanner_TestFailed() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	requestBody := `[
+		{"op": "test", "path": "/content/title", "value": "other_title"},
+		{"op": "replace", "path": "/content/title", "value": "new_title"}
+	]`
+	req, _ := http.NewRequest("PATCH", "/banner/1", strings.NewReader(requestBody))
+	req.Header.Set("Content-type", "application/json-patch+json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusUnprocessableEntity, resp.Result().StatusCode)
+
+	banner, err := s.repo.GetByID(context.Background(), 1)
+	s.NoError(err)
+	r.Equal("some_title", banner.Content["title"])
+}
+func (s *APITestSuite) TestPatchBanner_InvalidResult() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	req, _ := http.NewRequest("PATCH", "/banner/1", strings.NewReader(`{"tag_ids": [4, 4]}`))
+	req.Header.Set("Content-type", "application/merge-patch+json")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusUnprocessableEntity, resp.Result().StatusCode)
+}
+func (s *APITestSuite) TestPatchBanner_UnsupportedMediaType() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockService := &MockBannerService{
+		PatchFunc: func(ctx context.Context, patch *entity.BannerPatch) error {
+			return nil
+		},
+	}
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
+	r := s.Require()
+	req, _ := http.NewRequest("PATCH", "/banner/1", strings.NewReader(`<banner/>`))
+	req.Header.Set("Content-type", "application/xml")
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusUnsupportedMediaType, resp.Result().StatusCode)
+}
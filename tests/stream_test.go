@@ -0,0 +1,220 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"banner/internal/repository"
+	"banner/internal/service"
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readEvents читает из потока count событий, комментарии пропускаются
+func readEvents(reader *bufio.Reader, count int) ([]sseEvent, error) {
+	var (
+		events  []sseEvent
+		current sseEvent
+	)
+	for len(events) < count {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return events, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if current.event != "" {
+				events = append(events, current)
+			}
+			current = sseEvent{}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			current.id = value
+		case "event":
+			current.event = value
+		case "data":
+			current.data = value
+		}
+	}
+	return events, nil
+}
+
+func (s *APITestSuite) newStreamServer() (*httptest.Server, *v1.StreamController) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	stream := v1.NewStreamController(service.NewEventService(repository.NewEventRepository(s.db)), s.logger, s.messages, v1.StreamOptions{
+		PollInterval: 10 * time.Millisecond,
+		Heartbeat:    time.Second,
+	})
+	v1.RegisterRoutes(router, s.handler, stream)
+	return httptest.NewServer(router), stream
+}
+
+func (s *APITestSuite) openStream(ctx context.Context, url, lastEventID string) *bufio.Reader {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	s.Require().NoError(err)
+	req.Header.Set("token", "admin_token")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Equal("text/event-stream", resp.Header.Get("Content-Type"))
+	s.T().Cleanup(func() { resp.Body.Close() })
+	return bufio.NewReader(resp.Body)
+}
+
+func (s *APITestSuite) TestStream_ChangesAndResume() {
+	server, stream := s.newStreamServer()
+	defer server.Close()
+	defer stream.Close()
+	r := s.Require()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// поток без Last-Event-ID начинается с текущего момента
+	reader := s.openStream(ctx, server.URL+"/banner/stream?feature_id=777", "")
+	other, err := s.service.Save(ctx, &entity.Banner{TagIDs: []int32{1}, FeatureID: 778, Content: map[string]interface{}{"title": "other"}, IsActive: true})
+	r.NoError(err)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", other)
+	id, err := s.service.Save(ctx, &entity.Banner{TagIDs: []int32{1, 2}, FeatureID: 777, Content: map[string]interface{}{"title": "stream"}, IsActive: true})
+	r.NoError(err)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", id)
+	isActive := false
+	r.NoError(s.service.Update(ctx, &entity.BannerUpdate{ID: &id, IsActive: &isActive}))
+	content := map[string]interface{}{"title": "changed"}
+	r.NoError(s.service.Update(ctx, &entity.BannerUpdate{ID: &id, Content: &content}))
+	r.NoError(s.service.Delete(ctx, id, nil))
+
+	events, err := readEvents(reader, 4)
+	r.NoError(err)
+	r.Equal([]string{"created", "deactivated", "updated", "deleted"}, []string{events[0].event, events[1].event, events[2].event, events[3].event})
+	var updated entity.BannerEvent
+	r.NoError(json.Unmarshal([]byte(events[2].data), &updated))
+	r.Equal(id, updated.BannerID)
+	r.Equal(strconv.FormatInt(updated.ID, 10), events[2].id)
+	r.Equal("changed", updated.Content["title"])
+	r.False(updated.IsActive)
+	r.Equal([]entity.TagFeature{{TagID: 1, FeatureID: 777}, {TagID: 2, FeatureID: 777}}, updated.Affected)
+
+	// продолжение после первого события отдает остальные
+	resumed, err := readEvents(s.openStream(ctx, server.URL+"/banner/stream?feature_id=777", events[0].id), 3)
+	r.NoError(err)
+	r.Equal(events[1:], resumed)
+}
+
+func (s *APITestSuite) TestStream_InvalidRequest() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, v1.NewBannerController(&MockBannerService{}, s.logger, s.messages),
+		v1.NewStreamController(nil, s.logger, s.messages, v1.StreamOptions{PollInterval: time.Second, Heartbeat: time.Second}))
+	r := s.Require()
+
+	for path, status := range map[string]int{
+		"/banner/stream?feature_id=0":       http.StatusBadRequest,
+		"/banner/stream?last_event_id=abc":  http.StatusBadRequest,
+		"/banner/stream?last_event_id=-1":   http.StatusBadRequest,
+		"/banner/stream?feature_id=x&q=foo": http.StatusBadRequest,
+		"/banner/events?after=x":            http.StatusBadRequest,
+		"/banner/events?after=-1":           http.StatusBadRequest,
+		"/banner/events?limit=0":            http.StatusBadRequest,
+		"/banner/events?limit=1001":         http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("token", "admin_token")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		r.Equal(status, resp.Code, path)
+	}
+
+	req, _ := http.NewRequest("GET", "/banner/stream", nil)
+	req.Header.Set("token", "user_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusForbidden, resp.Code)
+}
+
+func (s *APITestSuite) TestEvents_ActorAndSnapshots() {
+	server, stream := s.newStreamServer()
+	defer server.Close()
+	defer stream.Close()
+	r := s.Require()
+	ctx := context.Background()
+	after, err := service.NewEventService(repository.NewEventRepository(s.db)).LastEventID(ctx)
+	r.NoError(err)
+
+	send := func(method, path, body string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		r.NoError(err)
+		req.Header.Set("token", "admin_token")
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		r.NoError(err)
+		s.T().Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	resp := send("POST", "/banner", `{"tag_ids": [1, 2], "feature_id": 779, "content": {"title": "events"}, "is_active": true}`)
+	r.Equal(http.StatusCreated, resp.StatusCode)
+	var created struct {
+		BannerID int32 `json:"banner_id"`
+	}
+	r.NoError(json.NewDecoder(resp.Body).Decode(&created))
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", created.BannerID)
+	resp = send("PATCH", "/banner/"+strconv.Itoa(int(created.BannerID)), `{"tag_ids": [2, 3], "is_active": false}`)
+	r.Equal(http.StatusNoContent, resp.StatusCode)
+	r.NoError(s.service.Delete(entity.WithActor(ctx, "cleanup"), created.BannerID, nil))
+
+	// журнал читается страницами: next первой страницы продолжает чтение
+	read := func(after int64, limit int) *entity.BannerEventsPage {
+		resp := send("GET", "/banner/events?feature_id=779&after="+strconv.FormatInt(after, 10)+"&limit="+strconv.Itoa(limit), "")
+		r.Equal(http.StatusOK, resp.StatusCode)
+		var page entity.BannerEventsPage
+		r.NoError(json.NewDecoder(resp.Body).Decode(&page))
+		return &page
+	}
+	first := read(after, 2)
+	r.Len(first.Events, 2)
+	r.Equal(first.Events[1].ID, first.Next)
+	rest := read(first.Next, 2)
+	r.Len(rest.Events, 1)
+	r.Empty(read(rest.Next, 2).Events)
+	r.Equal(rest.Next, read(rest.Next, 2).Next)
+
+	created1, updated, deleted := first.Events[0], first.Events[1], rest.Events[0]
+	r.Equal([]string{"created", "updated", "deleted"}, []string{string(created1.Type), string(updated.Type), string(deleted.Type)})
+	r.Equal("admin", created1.Actor)
+	r.Nil(created1.Before)
+	r.Equal([]int32{1, 2}, created1.After.TagIDs)
+	r.Equal("admin", updated.Actor)
+	r.Equal([]int32{1, 2}, updated.Before.TagIDs)
+	r.True(updated.Before.IsActive)
+	r.Equal([]int32{2, 3}, updated.After.TagIDs)
+	r.False(updated.After.IsActive)
+	r.Equal(updated.Before.Version+1, updated.After.Version)
+	r.Equal("cleanup", deleted.Actor)
+	r.Nil(deleted.Before.DeletedAt)
+	r.NotNil(deleted.After.DeletedAt)
+
+	// журнал только дополняется
+	_, err = s.db.Pool.Exec(ctx, "UPDATE banner_events SET actor = 'someone' WHERE id = $1", created1.ID)
+	r.ErrorContains(err, "append-only")
+	_, err = s.db.Pool.Exec(ctx, "DELETE FROM banner_events WHERE id = $1", created1.ID)
+	r.ErrorContains(err, "append-only")
+}
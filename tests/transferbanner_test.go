@@ -0,0 +1,229 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+func (s *APITestSuite) TestExportBanners_JSONL() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	req, _ := http.NewRequest("GET", "/banner/export?feature_id=123", nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Code)
+	r.Equal("application/x-ndjson", resp.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(resp.Body.String()), "\n")
+	r.Len(lines, 1)
+	var banner entity.FilteredBanner
+	s.NoError(json.Unmarshal([]byte(lines[0]), &banner))
+	r.Equal(int32(1), banner.ID)
+}
+
+func (s *APITestSuite) TestExportBanners_CSV() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	req, _ := http.NewRequest("GET", "/banner/export?format=csv&tag_id=4", nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Code)
+	lines := strings.Split(strings.TrimSpace(resp.Body.String()), "\n")
+	r.Len(lines, 2)
+	r.Equal("id,tag_ids,feature_id,content,is_active,created_at,updated_at,version", lines[0])
+	r.True(strings.HasPrefix(lines[1], `1,"[4,5,6]",123,`))
+}
+
+func (s *APITestSuite) TestExportBanners_InvalidFilter() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+
+	for _, format := range []string{"jsonl", "csv"} {
+		resp := s.request(router, "GET", "/banner/export?format="+format+"&content_path="+url.QueryEscape("$.url ? ("), "admin_token", "")
+		r.Equal(http.StatusBadRequest, resp.Code, format)
+		r.Contains(resp.Header().Get("Content-Type"), "application/json", format)
+		r.Empty(resp.Header().Get("Content-Disposition"), format)
+		var result struct {
+			Fields map[string]string `json:"fields"`
+		}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &result), resp.Body.String())
+		r.Contains(result.Fields, "content_path", format)
+	}
+}
+
+func (s *APITestSuite) TestExportBanners_HeadersOnStart() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	r := s.Require()
+	var exportErr error
+	var data string
+	mockService := &MockBannerService{
+		ExportFunc: func(ctx context.Context, w io.Writer, format entity.TransferFormat, filter *entity.BannerFilter) error {
+			if _, err := io.WriteString(w, data); err != nil {
+				return err
+			}
+			return exportErr
+		},
+	}
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
+
+	exportErr = errors.New("connection reset")
+	resp := s.request(router, "GET", "/banner/export?format=csv", "admin_token", "")
+	r.Equal(http.StatusInternalServerError, resp.Code)
+	r.Empty(resp.Header().Get("Content-Disposition"))
+
+	exportErr = nil
+	resp = s.request(router, "GET", "/banner/export?format=csv", "admin_token", "")
+	r.Equal(http.StatusOK, resp.Code)
+	r.Equal("text/csv", resp.Header().Get("Content-Type"))
+	r.Equal(`attachment; filename="banners.csv"`, resp.Header().Get("Content-Disposition"))
+	r.Empty(resp.Body.String())
+
+	data = "{}\n"
+	resp = s.request(router, "GET", "/banner/export", "admin_token", "")
+	r.Equal(http.StatusOK, resp.Code)
+	r.Equal("application/x-ndjson", resp.Header().Get("Content-Type"))
+	r.Equal("{}\n", resp.Body.String())
+}
+
+func (s *APITestSuite) importBanners(router *gin.Engine, query, contentType, body string) *httptest.ResponseRecorder {
+	req := s.newRequest("POST", "/banner/import"+query, "admin_token", body)
+	req.Header.Set("Content-Type", contentType)
+	return s.serve(router, req)
+}
+
+func (s *APITestSuite) TestImportBanners_DryRun() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+
+	body := `{"tag_ids": [1], "feature_id": 501, "content": {"title": "a"}, "is_active": true}
+{"tag_ids": [], "feature_id": 501, "content": {"title": "b"}, "is_active": true}
+not json
+`
+	resp := s.importBanners(router, "?dry_run=true", "application/x-ndjson", body)
+	r.Equal(http.StatusOK, resp.Code)
+	var report entity.ImportReport
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &report))
+	r.True(report.DryRun)
+	r.Equal(3, report.Total)
+	r.Equal(1, report.Created)
+	r.Len(report.Rejected, 2)
+	r.Equal(2, report.Rejected[0].Line)
+	r.Equal(3, report.Rejected[1].Line)
+	r.Equal(0, s.countBanners(501))
+}
+
+func (s *APITestSuite) TestImportBanners_CSVConflicts() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	body := "id,tag_ids,feature_id,content,is_active\n" +
+		`1,"[4]",123,"{""title"":""imported""}",true` + "\n"
+
+	resp := s.importBanners(router, "?conflict=fail", "text/csv", body)
+	r.Equal(http.StatusConflict, resp.Code)
+
+	resp = s.importBanners(router, "?conflict=skip", "text/csv", body)
+	r.Equal(http.StatusOK, resp.Code)
+	var report entity.ImportReport
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &report))
+	r.Equal(1, report.Skipped)
+	r.Equal(entity.RejectConflict, report.Rejected[0].Reason)
+
+	resp = s.importBanners(router, "?conflict=overwrite&preserve_ids=true", "text/csv", body)
+	r.Equal(http.StatusOK, resp.Code)
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &report))
+	r.Equal(1, report.Updated)
+	banner, err := s.repo.GetByID(context.Background(), 1)
+	s.NoError(err)
+	r.Equal("imported", banner.Content["title"])
+	r.Equal([]int32{4}, banner.TagIDs)
+}
+
+func (s *APITestSuite) TestImportBanners_DuplicateIDs() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	body := "id,tag_ids,feature_id,content,is_active\n" +
+		`1,"[4]",123,"{""title"":""first""}",true` + "\n" +
+		`1,"[4]",123,"{""title"":""second""}",false` + "\n"
+
+	resp := s.importBanners(router, "?conflict=overwrite&preserve_ids=true", "text/csv", body)
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	var report entity.ImportReport
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &report))
+	r.Equal(2, report.Total)
+	r.Equal(1, report.Updated)
+	r.Len(report.Rejected, 1)
+	r.Equal(entity.RejectDuplicate, report.Rejected[0].Reason)
+	r.Equal(3, report.Rejected[0].Line)
+	r.Equal(int32(1), report.Rejected[0].ID)
+	banner, err := s.repo.GetByID(context.Background(), 1)
+	s.NoError(err)
+	r.Equal("first", banner.Content["title"])
+
+	// без сохранения id строки перезаписывают баннер с той же фичей и тегами
+	resp = s.importBanners(router, "?conflict=overwrite", "text/csv", body)
+	r.Equal(http.StatusOK, resp.Code, resp.Body.String())
+	report = entity.ImportReport{}
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &report))
+	r.Equal(1, report.Updated)
+	r.Len(report.Rejected, 1)
+	r.Equal(entity.RejectDuplicate, report.Rejected[0].Reason)
+}
+
+func (s *APITestSuite) TestImportBanners_TooLarge() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var read int64
+	mockService := &MockBannerService{
+		ImportFunc: func(ctx context.Context, r io.Reader, options *entity.ImportOptions) (*entity.ImportReport, error) {
+			var err error
+			read, err = io.Copy(io.Discard, r)
+			return nil, err
+		},
+	}
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
+	r := s.Require()
+
+	// тело генерируется по мере чтения, чтобы не держать в тесте файл больше предела
+	req, _ := http.NewRequest("POST", "/banner/import", io.LimitReader(rand.Reader, 64<<20+1))
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusRequestEntityTooLarge, resp.Code)
+	r.Equal(int64(64<<20), read)
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func (s *APITestSuite) TestTrash_DeleteAndRestore() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	req, _ := http.NewRequest("DELETE", "/banner/1", nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNoContent, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/user_banner?tag_id=4&feature_id=123&use_last_revision=true", nil)
+	req.Header.Set("token", "user_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNotFound, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/banner?feature_id=123", nil)
+	req.Header.Set("token", "admin_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Code)
+	r.Equal("[]", resp.Body.String())
+
+	req, _ = http.NewRequest("GET", "/banner/trash", nil)
+	req.Header.Set("token", "admin_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Code)
+	var trash []entity.DeletedBanner
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &trash))
+	r.Len(trash, 1)
+	r.Equal(int32(1), trash[0].ID)
+	r.False(trash[0].DeletedAt.IsZero())
+
+	req, _ = http.NewRequest("POST", "/banner/1/restore", nil)
+	req.Header.Set("token", "admin_token")
+	req.Header.Set("If-Match", `"1"`)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusPreconditionFailed, resp.Code)
+
+	req, _ = http.NewRequest("POST", "/banner/1/restore", nil)
+	req.Header.Set("token", "admin_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNoContent, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/user_banner?tag_id=4&feature_id=123&use_last_revision=true", nil)
+	req.Header.Set("token", "user_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusOK, resp.Code)
+
+	req, _ = http.NewRequest("POST", "/banner/1/restore", nil)
+	req.Header.Set("token", "admin_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNotFound, resp.Code)
+}
+
+func (s *APITestSuite) TestTrash_RestoreConflict() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+
+	req, _ := http.NewRequest("DELETE", "/banner/1", nil)
+	req.Header.Set("token", "admin_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusNoContent, resp.Code)
+
+	_, err := s.db.Pool.Exec(context.Background(),
+		"INSERT INTO banners (id, tag_ids, feature_id, content, is_active) VALUES (2, $1, 123, $2, true)",
+		[]int32{6, 7}, map[string]interface{}{"title": "replacement"})
+	s.NoError(err)
+	defer func() {
+		_, err := s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = 2")
+		s.NoError(err)
+	}()
+
+	req, _ = http.NewRequest("POST", "/banner/1/restore", nil)
+	req.Header.Set("token", "admin_token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusConflict, resp.Code)
+}
+
+func (s *APITestSuite) TestTrash_Purge() {
+	r := s.Require()
+	s.createTestBanner()
+	defer s.deleteTestBanner()
+	s.NoError(s.service.Delete(context.Background(), 1, nil))
+
+	purged, err := s.service.PurgeDeleted(context.Background(), time.Hour)
+	s.NoError(err)
+	r.Equal(int64(0), purged)
+
+	_, err = s.db.Pool.Exec(context.Background(), "UPDATE banners SET deleted_at = now() - interval '2 hours' WHERE id = 1")
+	s.NoError(err)
+	purged, err = s.service.PurgeDeleted(context.Background(), time.Hour)
+	s.NoError(err)
+	r.Equal(int64(1), purged)
+
+	var count int
+	s.NoError(s.db.Pool.QueryRow(context.Background(), "SELECT COUNT(*) FROM banners_history WHERE id = 1").Scan(&count))
+	r.Zero(count)
+}
+
+func (s *APITestSuite) TestTrash_Forbidden() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler)
+	r := s.Require()
+
+	req, _ := http.NewRequest("GET", "/banner/trash", nil)
+	req.Header.Set("token", "user_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusForbidden, resp.Code)
+}
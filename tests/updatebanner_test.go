@@ -98,7 +98,7 @@ func (s *APITestSuite) TestUpdateBanner_InternalServerError() {
 			return errors.New("internal server error")
 		},
 	}
-	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger))
+	v1.RegisterRoutes(router, v1.NewBannerController(mockService, s.logger, s.messages))
 	r := s.Require()
 	requestBody := `{
 		"tag_ids": [7, 8, 9]
@@ -0,0 +1,218 @@
+package tests
+
+import (
+	v1 "banner/internal/controller/http/v1"
+	"banner/internal/entity"
+	"banner/pkg/webhook"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// webhookReceiver - подписчик, который запоминает доставки и отвечает 500, пока fail выставлен
+type webhookReceiver struct {
+	server *httptest.Server
+	fail   atomic.Bool
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newWebhookReceiver() *webhookReceiver {
+	receiver := &webhookReceiver{}
+	receiver.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if receiver.fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		receiver.mu.Lock()
+		receiver.requests = append(receiver.requests, r)
+		receiver.bodies = append(receiver.bodies, body)
+		receiver.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return receiver
+}
+
+func (r *webhookReceiver) received() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// deliveries - заголовки и тела полученных доставок
+func (r *webhookReceiver) deliveries() ([]http.Header, [][]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	headers := make([]http.Header, len(r.requests))
+	for i, req := range r.requests {
+		headers[i] = req.Header
+	}
+	return headers, r.bodies
+}
+
+func (s *APITestSuite) webhookRequest(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var raw []byte
+	if body != nil {
+		var err error
+		raw, err = json.Marshal(body)
+		s.Require().NoError(err)
+	}
+	return s.request(router, method, path, "admin_token", string(raw))
+}
+
+func (s *APITestSuite) createWebhook(router *gin.Engine, body gin.H) *entity.Webhook {
+	resp := s.webhookRequest(router, "POST", "/webhooks", body)
+	s.Require().Equal(http.StatusCreated, resp.Code, resp.Body.String())
+	var hook entity.Webhook
+	s.Require().NoError(json.Unmarshal(resp.Body.Bytes(), &hook))
+	s.T().Cleanup(func() { s.webhookRequest(router, "DELETE", fmt.Sprintf("/webhooks/%d", hook.ID), nil) })
+	return &hook
+}
+
+func (s *APITestSuite) webhookDeliveries(router *gin.Engine, id int32, status string) []*entity.WebhookDelivery {
+	resp := s.webhookRequest(router, "GET", fmt.Sprintf("/webhooks/%d/deliveries?status=%s", id, status), nil)
+	s.Require().Equal(http.StatusOK, resp.Code, resp.Body.String())
+	var deliveries []*entity.WebhookDelivery
+	s.Require().NoError(json.Unmarshal(resp.Body.Bytes(), &deliveries))
+	return deliveries
+}
+
+func (s *APITestSuite) TestWebhooks_SignedDelivery() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.webhooks)
+	receiver := newWebhookReceiver()
+	defer receiver.server.Close()
+	r := s.Require()
+	ctx := context.Background()
+
+	hook := s.createWebhook(router, gin.H{"url": receiver.server.URL, "event_types": []string{"created", "deactivated"}, "feature_ids": []int32{881}})
+	r.NotEmpty(hook.Secret)
+	r.True(hook.IsActive)
+	resp := s.webhookRequest(router, "GET", fmt.Sprintf("/webhooks/%d", hook.ID), nil)
+	r.Equal(http.StatusOK, resp.Code)
+	r.NotContains(resp.Body.String(), hook.Secret)
+
+	other, err := s.service.Save(ctx, &entity.Banner{TagIDs: []int32{1}, FeatureID: 882, Content: map[string]interface{}{"title": "other"}, IsActive: true})
+	r.NoError(err)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", other)
+	id, err := s.service.Save(ctx, &entity.Banner{TagIDs: []int32{1}, FeatureID: 881, Content: map[string]interface{}{"title": "hook"}, IsActive: true})
+	r.NoError(err)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", id)
+	content := map[string]interface{}{"title": "changed"}
+	r.NoError(s.service.Update(ctx, &entity.BannerUpdate{ID: &id, Content: &content}))
+	isActive := false
+	r.NoError(s.service.Update(ctx, &entity.BannerUpdate{ID: &id, IsActive: &isActive}))
+
+	r.Eventually(func() bool { return len(s.webhookDeliveries(router, hook.ID, "delivered")) == 2 }, 5*time.Second, 20*time.Millisecond)
+	headers, bodies := receiver.deliveries()
+	r.Len(bodies, 2)
+	var types []string
+	for i, header := range headers {
+		r.NoError(webhook.Verify(hook.Secret, header.Get(webhook.SignatureHeader), bodies[i], time.Minute))
+		r.ErrorIs(webhook.Verify("wrong", header.Get(webhook.SignatureHeader), bodies[i], time.Minute), webhook.ErrSignature)
+		var event entity.BannerEvent
+		r.NoError(json.Unmarshal(bodies[i], &event))
+		r.Equal(id, event.BannerID)
+		r.Equal(string(event.Type), header.Get(webhook.EventHeader))
+		types = append(types, string(event.Type))
+	}
+	r.ElementsMatch([]string{"created", "deactivated"}, types)
+	for _, delivery := range s.webhookDeliveries(router, hook.ID, "") {
+		r.Equal(entity.DeliveryDelivered, delivery.Status)
+		r.Equal(int32(1), delivery.Attempts)
+		r.Equal(int32(http.StatusNoContent), *delivery.ResponseStatus)
+	}
+}
+
+func (s *APITestSuite) TestWebhooks_DeadLetterAndRedeliver() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, s.handler, s.webhooks)
+	receiver := newWebhookReceiver()
+	defer receiver.server.Close()
+	receiver.fail.Store(true)
+	r := s.Require()
+
+	hook := s.createWebhook(router, gin.H{"url": receiver.server.URL, "feature_ids": []int32{883}})
+	id, err := s.service.Save(context.Background(), &entity.Banner{TagIDs: []int32{1}, FeatureID: 883, Content: map[string]interface{}{"title": "dead"}, IsActive: true})
+	r.NoError(err)
+	defer s.db.Pool.Exec(context.Background(), "DELETE FROM banners WHERE id = $1", id)
+
+	// после MaxAttempts неудачных попыток доставка перестает повторяться
+	var dead []*entity.WebhookDelivery
+	r.Eventually(func() bool {
+		dead = s.webhookDeliveries(router, hook.ID, "dead")
+		return len(dead) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+	r.Equal(int32(2), dead[0].Attempts)
+	r.Equal(int32(http.StatusInternalServerError), *dead[0].ResponseStatus)
+	r.Contains(dead[0].Error, "500")
+	r.Equal(entity.EventCreated, dead[0].EventType)
+
+	receiver.fail.Store(false)
+	resp := s.webhookRequest(router, "POST", fmt.Sprintf("/webhooks/%d/deliveries/%d/retry", hook.ID, dead[0].ID), nil)
+	r.Equal(http.StatusAccepted, resp.Code, resp.Body.String())
+	r.Eventually(func() bool { return len(s.webhookDeliveries(router, hook.ID, "delivered")) == 1 }, 5*time.Second, 20*time.Millisecond)
+	r.Equal(1, receiver.received())
+
+	resp = s.webhookRequest(router, "POST", fmt.Sprintf("/webhooks/%d/deliveries/%d/retry", hook.ID+1000, dead[0].ID), nil)
+	r.Equal(http.StatusNotFound, resp.Code)
+}
+
+func (s *APITestSuite) TestWebhooks_InvalidRequest() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1.RegisterRoutes(router, v1.NewBannerController(&MockBannerService{}, s.logger, s.messages), v1.NewWebhookController(nil, s.logger, s.messages))
+	r := s.Require()
+
+	for body, field := range map[string]string{
+		`{}`:                           "url",
+		`{"url": "ftp://example.com"}`: "url",
+		`{"url": "http://example.com", "event_types": ["moved"]}`: "event_types[0]",
+		`{"url": "http://example.com", "feature_ids": [0]}`:       "feature_ids[0]",
+		`{"url": "http://example.com", "feature_ids": [1, 1]}`:    "feature_ids",
+		`{"url": "http://example.com", "event_types": "created"}`: "event_types",
+	} {
+		req, _ := http.NewRequest("POST", "/webhooks", strings.NewReader(body))
+		req.Header.Set("token", "admin_token")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		r.Equal(http.StatusBadRequest, resp.Code, body)
+		var result struct {
+			Fields map[string]string `json:"fields"`
+		}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &result))
+		r.Contains(result.Fields, field, body)
+	}
+
+	for path, status := range map[string]int{
+		"/webhooks/abc":                      http.StatusBadRequest,
+		"/webhooks/0/deliveries":             http.StatusBadRequest,
+		"/webhooks/1/deliveries?status=lost": http.StatusBadRequest,
+		"/webhooks/1/deliveries?limit=-1":    http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("token", "admin_token")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		r.Equal(status, resp.Code, path)
+	}
+
+	req, _ := http.NewRequest("GET", "/webhooks", nil)
+	req.Header.Set("token", "user_token")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusForbidden, resp.Code)
+}